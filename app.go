@@ -8,6 +8,7 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"os"
 
 	allcountries "github.com/junkd0g/covid/controller/allcountries"
 	comparectl "github.com/junkd0g/covid/controller/compare"
@@ -15,14 +16,28 @@ import (
 	countriescon "github.com/junkd0g/covid/controller/countries"
 	countrycon "github.com/junkd0g/covid/controller/country"
 	cssectl "github.com/junkd0g/covid/controller/csse"
+	curvectl "github.com/junkd0g/covid/controller/curve"
+	forecastctl "github.com/junkd0g/covid/controller/forecast"
 	hotspot "github.com/junkd0g/covid/controller/hotspot"
+	metricsctl "github.com/junkd0g/covid/controller/metrics"
 	crnews "github.com/junkd0g/covid/controller/news"
+	snapshotctl "github.com/junkd0g/covid/controller/snapshot"
 	sortcon "github.com/junkd0g/covid/controller/sort"
+	statsctl "github.com/junkd0g/covid/controller/stats"
+	statusctl "github.com/junkd0g/covid/controller/status"
 	totalcon "github.com/junkd0g/covid/controller/totalcon"
 	worldct "github.com/junkd0g/covid/controller/world"
 
 	"github.com/gorilla/mux"
+	caching "github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
+	continent "github.com/junkd0g/covid/lib/continent"
+	csse "github.com/junkd0g/covid/lib/csse"
+	curve "github.com/junkd0g/covid/lib/curve"
+	cworld "github.com/junkd0g/covid/lib/cworld"
+	news "github.com/junkd0g/covid/lib/news"
+	scheduler "github.com/junkd0g/covid/lib/scheduler"
+	stats "github.com/junkd0g/covid/lib/stats"
 	"github.com/rs/cors"
 )
 
@@ -40,7 +55,15 @@ var (
 
 	Endpoints:
 		GET:
+			/api/status
+			/api/snapshot/dates
+			/api/snapshot/{country}/{date}
+			/api/snapshot/{country}/{date}/diff
 			/api/hotspot
+			/api/metrics/{country}
+			/api/forecast/{country}
+			/api/curve/provinces
+			/api/curve/{country}/{province}
             /api/world
             /api/continent
 			/api/total
@@ -54,25 +77,64 @@ var (
 			/api/country
 			/api/sort
 			/api/stats
+			/api/compare
 			/api/compare/all
 
 */
 
+// setCache creates the cache backend of the config and sets the Store
+// every lib package caches the data of the third party APIs in
+func setCache() {
+	store, err := caching.NewStoreFromConfig(serverConf)
+	if err != nil {
+		fmt.Println("Cannot create cache backend: " + err.Error())
+		os.Exit(2)
+	}
+
+	stats.SetCache(store)
+	curve.SetCache(store)
+	cworld.SetCache(store)
+	continent.SetCache(store)
+	csse.SetCache(store)
+	news.SetCache(store)
+}
+
 func main() {
+	setCache()
+
 	router := mux.NewRouter().StrictSlash(true)
 	port := serverConf.Server.Port
 	fmt.Println("server running at port " + port)
 
+	if serverConf.Scheduler.Enabled {
+		scheduler.Default.Start()
+	}
+
+	router.HandleFunc("/api/status", statusctl.Handle).Methods("GET")
+	router.HandleFunc("/api/snapshot/dates", snapshotctl.DatesHandle).Methods("GET")
+	router.HandleFunc("/api/snapshot/{country}/{date}", snapshotctl.Handle).Methods("GET")
+	router.HandleFunc("/api/snapshot/{country}/{date}/diff", snapshotctl.DiffHandle).Methods("GET")
+	router.HandleFunc("/api/csse", cssectl.SummaryHandle).Methods("GET")
 	router.HandleFunc("/api/csse/{country}", cssectl.Handle).Methods("GET")
+	router.HandleFunc("/api/csse/{country}/geojson", cssectl.GeoJSONHandle).Methods("GET")
 	router.HandleFunc("/api/hotspot/{days}", hotspot.Handle).Methods("GET")
+	router.HandleFunc("/api/metrics/{country}", metricsctl.Handle).Methods("GET")
+	router.HandleFunc("/api/forecast/{country}", forecastctl.Handle).Methods("GET")
+	router.HandleFunc("/api/curve/provinces", curvectl.ProvincesHandle).Methods("GET")
+	router.HandleFunc("/api/curve/{country}/{province}", curvectl.Handle).Methods("GET")
 	router.HandleFunc("/api/world", worldct.Handle).Methods("GET")
 	router.HandleFunc("/api/continent", continentctl.Handle).Methods("GET")
+	router.HandleFunc("/api/news", crnews.NewsHandle).Methods("GET")
 	router.HandleFunc("/api/news/all", crnews.NewsAllHandle).Methods("GET")
+	router.HandleFunc("/api/news/vaccine", crnews.VaccineNewsHandle).Methods("GET")
+	router.HandleFunc("/api/news/treatment", crnews.TreatmentNewsHandle).Methods("GET")
 	router.HandleFunc("/api/country", countrycon.Handle).Methods("POST")
 	router.HandleFunc("/api/countries", countriescon.Handle).Methods("GET")
 	router.HandleFunc("/api/countries/all", allcountries.Handle).Methods("GET")
 	router.HandleFunc("/api/sort", sortcon.Handle).Methods("POST")
+	router.HandleFunc("/api/stats", statsctl.Handle).Methods("POST")
 	router.HandleFunc("/api/total", totalcon.Handle).Methods("GET")
+	router.HandleFunc("/api/compare", comparectl.CountriesHandle).Methods("POST")
 	router.HandleFunc("/api/compare/all", comparectl.Handle).Methods("POST")
 
 	c := cors.New(cors.Options{
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
@@ -47,7 +49,10 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform()
+	jsonBody, status, stale := perform()
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -72,21 +77,24 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform() ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform() ([]byte, int, bool) {
 
 	totalStats, err := stats.GetAllCountriesName()
 	if err != nil {
 		applogger.Log("ERROR", "allcountries", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
+	_, totalStats.Stale = stats.Stale()
 	jsonBody, jsonBodyErr := json.Marshal(totalStats)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "allcountries", "perform", err.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
 
-	return jsonBody, 200
+	return jsonBody, 200, totalStats.Stale
 }
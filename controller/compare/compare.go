@@ -2,9 +2,8 @@ package comparectl
 
 /*
 	Controller used for the endpoints:
-		/compare
-		/compare/firstdeath
-		/compare/perday
+		/api/compare
+		/api/compare/all
 */
 
 import (
@@ -13,18 +12,25 @@ import (
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	curve "github.com/junkd0g/covid/lib/curve"
 	mcountry "github.com/junkd0g/covid/lib/model/country"
+	registry "github.com/junkd0g/covid/lib/registry"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 
 	"io/ioutil"
 	"net/http"
 )
 
-//Request used for the https request's body
+//Request used for the https request's body, Normalize and Smoothing
+//are optional
 type Request struct {
-	NameOne string `json:"countryOne"`
-	NameTwo string `json:"countryTwo"`
+	NameOne   string `json:"countryOne"`
+	NameTwo   string `json:"countryTwo"`
+	Normalize string `json:"normalize"`
+	Smoothing string `json:"smoothing"`
 }
 
 // Handle POST request to /api/compare/all endpoint
@@ -33,129 +39,90 @@ type Request struct {
 
 	{
 		"countryOne" : "Spain",
-		"countryTwo" : "Italy"
+		"countryTwo" : "Italy",
+		"normalize" : "per100k"
 	}
 
+	normalize is optional, per100k or perMillion divide the curves by the
+	countries' population. smoothing is optional too (trailing:N,
+	centered:N, exponential:A, clip or a method and clip like
+	trailing:7,clip) and smooths the per day curves
+
 	Response
 
 	{
     "countryOne": {
         "country": "Spain",
         "dataDeaths": [
-            28,
-            27888,
-            27940,
-            28628,
-            28678,
-            28752
+            { "date": "2020-06-01", "value": 27127 },
+            { "date": "2020-06-02", "value": 27127 },
+            { "date": "2020-06-03", "value": 27128 }
         ],
         "dataDeathsFromFirst": [
-            28,
-            27888,
-            27940,
-            28628,
-            28678,
-            28752
+            { "date": "2020-03-03", "value": 1 },
+            { "date": "2020-03-04", "value": 2 },
+            { "date": "2020-03-05", "value": 3 }
         ],
         "dataDeathsPerDay": [
-            110,
-            52,
-            688,
-            50,
-            74
+            { "date": "2020-06-02", "value": 0 },
+            { "date": "2020-06-03", "value": 1 }
         ],
         "dataRecoverd": [
-            150376,
-            150376,
-            150376,
-            150376,
-            150376
+            { "date": "2020-06-01", "value": 150376 },
+            { "date": "2020-06-02", "value": 150376 },
+            { "date": "2020-06-03", "value": 150376 }
         ],
         "dataCases": [
-            15,
-            32,
-            45,
-            84,
-            120,
-            165,
-            222,
-            259,
+            { "date": "2020-06-01", "value": 239429 },
+            { "date": "2020-06-02", "value": 239638 },
+            { "date": "2020-06-03", "value": 239932 }
         ],
         "dataCasesFromFirst": [
-            159,
-            294,
-            394,
-            334,
-            318,
-            332,
-            240
+            { "date": "2020-06-02", "value": 209 },
+            { "date": "2020-06-03", "value": 294 }
         ]
     },
     "countryTwo": {
-        "country": "Spain",
+        "country": "Italy",
         "dataDeaths": [
-            0,
-            0,
-            0,
-            0,
-            0,
+            { "date": "2020-06-01", "value": 33475 },
+            { "date": "2020-06-02", "value": 33530 },
+            { "date": "2020-06-03", "value": 33601 }
         ],
         "dataDeathsFromFirst": [
-            33530,
-            33601,
-            33689,
-            33774,
-            33846,
-            33899
+            { "date": "2020-02-21", "value": 1 },
+            { "date": "2020-02-22", "value": 2 },
+            { "date": "2020-02-23", "value": 3 }
         ],
         "dataDeathsPerDay": [
-            1,
-            1,
-            1,
-            4,
-            3,
-            2,
+            { "date": "2020-06-02", "value": 55 },
+            { "date": "2020-06-03", "value": 71 }
         ],
         "dataRecoverd": [
-            0,
-            1,
-            1,
-            1,
-            2,
-            3,
-            45,
-            46,
-            46,
+            { "date": "2020-06-01", "value": 158355 },
+            { "date": "2020-06-02", "value": 160092 },
+            { "date": "2020-06-03", "value": 160938 }
         ],
         "dataCases": [
-            232664,
-            232997,
-            233197,
-            233515,
-            233836,
-            234013,
-            234531,
-            234801,
-            234998
+            { "date": "2020-06-01", "value": 233197 },
+            { "date": "2020-06-02", "value": 233515 },
+            { "date": "2020-06-03", "value": 233836 }
         ],
         "dataCasesFromFirst": [
-            200,
-            318,
-            321,
-            177,
-            518,
-            270,
-            197
+            { "date": "2020-06-02", "value": 318 },
+            { "date": "2020-06-03", "value": 321 }
         ]
     }
 }
-}
 */
 func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform(r)
+	jsonBody, status, stale := perform(r)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -163,94 +130,84 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		"Endpoint /compare/percent called with response JSON body "+string(jsonBody), status, elapsed)
 }
 
-func perform(r *http.Request) ([]byte, int) {
+//Perform used in the /api/compare/all endpoint's handle to return all the
+//curves of two countries, it is built on curve.Compare as /api/compare
+//	@param r *http.Request used to get http request's body
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func perform(r *http.Request) ([]byte, int, bool) {
 	var compareRequest Request
 
 	b, errIoutilReadAll := ioutil.ReadAll(r.Body)
 	if errIoutilReadAll != nil {
 		applogger.Log("ERROR", "compare", "perform", errIoutilReadAll.Error())
 		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, errIoutilReadAll)
-		return statsErrJSONBody, 500
+		return statsErrJSONBody, 500, false
 	}
 
 	unmarshallError := json.Unmarshal(b, &compareRequest)
 	if unmarshallError != nil {
 		applogger.Log("ERROR", "compare", "perform", unmarshallError.Error())
 		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, unmarshallError)
-		return statsErrJSONBody, 400
+		return statsErrJSONBody, 400, false
 	}
 
 	applogger.Log("INFO", "compare", "Perform",
 		fmt.Sprintf("Getting this request %v", compareRequest))
 
-	compareDeathsCountries, compareDeathsCountriesErr := curve.CompareDeathsCountries(compareRequest.NameOne, compareRequest.NameTwo)
-	if compareDeathsCountriesErr != nil {
-		applogger.Log("ERROR", "compare", "perform", compareDeathsCountriesErr.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, compareDeathsCountriesErr)
-		return statsErrJSONBody, 500
+	smoothingOptions, errSmoothing := smoothing.Parse(compareRequest.Smoothing)
+	if errSmoothing != nil {
+		applogger.Log("ERROR", "compare", "perform", errSmoothing.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errSmoothing)
+		return statsErrJSONBody, 400, false
 	}
 
-	compareRecoveryCountries, compareRecoveryCountriesErr := curve.CompareRecoveryCountries(compareRequest.NameOne, compareRequest.NameTwo)
-	if compareRecoveryCountriesErr != nil {
-		applogger.Log("ERROR", "compare", "perform", compareRecoveryCountriesErr.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, compareRecoveryCountriesErr)
-		return statsErrJSONBody, 500
+	compare, compareErr := curve.Compare(curve.CompareQuery{
+		Countries: []string{compareRequest.NameOne, compareRequest.NameTwo},
+		Normalize: compareRequest.Normalize,
+		Smoothing: smoothingOptions,
+	})
+	if compareErr != nil {
+		applogger.Log("ERROR", "compare", "perform", compareErr.Error())
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(compareErr); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(compareErr)
+		if badCompareRequest(compareErr) {
+			status = 400
+		}
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, compareErr)
+		return statsErrJSONBody, status, false
 	}
 
-	compareCasesCountries, compareCasesCountriesErr := curve.CompareCasesCountries(compareRequest.NameOne, compareRequest.NameTwo)
-	if compareCasesCountriesErr != nil {
-		applogger.Log("ERROR", "compare", "perform", compareCasesCountriesErr.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, compareCasesCountriesErr)
-		return statsErrJSONBody, 500
-	}
-
-	comparePerDayCasesCountries, comparePerDayCasesCountriesErr := curve.ComparePerDayCasesCountries(compareRequest.NameOne, compareRequest.NameTwo)
-	if comparePerDayCasesCountriesErr != nil {
-		applogger.Log("ERROR", "compare", "perform", comparePerDayCasesCountriesErr.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, comparePerDayCasesCountriesErr)
-		return statsErrJSONBody, 500
-	}
-
-	comparePerDayDeathsCountries, comparePerDayDeathsCountriesErr := curve.ComparePerDayDeathsCountries(compareRequest.NameOne, compareRequest.NameTwo)
-	if comparePerDayDeathsCountriesErr != nil {
-		applogger.Log("ERROR", "compare", "perform", comparePerDayDeathsCountriesErr.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, comparePerDayDeathsCountriesErr)
-		return statsErrJSONBody, 500
-	}
-
-	compareDeathsFromFirstDeathCountries, compareDeathsFromFirstDeathCountriesErr := curve.CompareDeathsFromFirstDeathCountries(compareRequest.NameOne, compareRequest.NameTwo)
-	if compareDeathsFromFirstDeathCountriesErr != nil {
-		applogger.Log("ERROR", "compare", "perform", compareDeathsFromFirstDeathCountriesErr.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, compareDeathsFromFirstDeathCountriesErr)
-		return statsErrJSONBody, 500
-	}
-	var countryOneAllData mcountry.CompareAllData
-	var countryTwoAllData mcountry.CompareAllData
-
-	countryOneAllData.Country = compareRequest.NameOne
-	countryOneAllData.DataDeaths = compareDeathsCountries.CountryOne.Data
-	countryOneAllData.DataDeathsFromFirst = compareDeathsFromFirstDeathCountries.CountryOne.Data
-	countryOneAllData.DataDeathsPerDay = comparePerDayDeathsCountries.CountryOne.Data
-	countryOneAllData.DataRecovered = compareRecoveryCountries.CountryOne.Data
-	countryOneAllData.DataCases = compareCasesCountries.CountryOne.Data
-	countryOneAllData.DataCasesFromFist = comparePerDayCasesCountries.CountryOne.Data
-
-	countryTwoAllData.Country = compareRequest.NameTwo
-	countryTwoAllData.DataDeaths = compareDeathsCountries.CountryTwo.Data
-	countryTwoAllData.DataDeathsFromFirst = compareDeathsFromFirstDeathCountries.CountryTwo.Data
-	countryTwoAllData.DataDeathsPerDay = comparePerDayDeathsCountries.CountryTwo.Data
-	countryTwoAllData.DataRecovered = compareRecoveryCountries.CountryTwo.Data
-	countryTwoAllData.DataCases = compareCasesCountries.CountryTwo.Data
-	countryTwoAllData.DataCasesFromFist = comparePerDayCasesCountries.CountryTwo.Data
-
-	jsonBody, jsonBodyErr := json.Marshal(mcountry.CompareAll{countryOneAllData, countryTwoAllData})
+	_, stale := curve.Stale()
+	jsonBody, jsonBodyErr := json.Marshal(mcountry.CompareAll{
+		CountryOne: compareAllData(compareRequest.NameOne, compare.Data[compareRequest.NameOne]),
+		CountryTwo: compareAllData(compareRequest.NameTwo, compare.Data[compareRequest.NameTwo]),
+		Stale:      stale,
+	})
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "compare", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
 
 	applogger.Log("INFO", "compare", "perform",
 		"Returning status: 200 with JSONbody "+string(jsonBody))
-	return jsonBody, 200
+	return jsonBody, 200, stale
+}
+
+//compareAllData returns the curves of a country in the format of
+///api/compare/all, dataCasesFromFirst has always been the cases per day
+func compareAllData(name string, metrics mcountry.CompareMetrics) mcountry.CompareAllData {
+	return mcountry.CompareAllData{
+		Country:             name,
+		DataDeaths:          metrics[curve.MetricDeaths],
+		DataDeathsFromFirst: metrics[curve.MetricDeathsFromFirstDeath],
+		DataDeathsPerDay:    metrics[curve.MetricDeathsPerDay],
+		DataRecovered:       metrics[curve.MetricRecovered],
+		DataCases:           metrics[curve.MetricCases],
+		DataCasesFromFist:   metrics[curve.MetricCasesPerDay],
+	}
 }
@@ -8,24 +8,29 @@ import (
 	"testing"
 )
 
+type TimelinePointExpectedResponse struct {
+	Date  string `json:"date"`
+	Value int    `json:"value"`
+}
+
 type CompareExpectedResponse struct {
 	CountryOne struct {
-		Country             string `json:"country"`
-		DataDeaths          []int  `json:"dataDeaths"`
-		DataDeathsFromFirst []int  `json:"dataDeathsFromFirst"`
-		DataDeathsPerDay    []int  `json:"dataDeathsPerDay"`
-		DataRecoverd        []int  `json:"dataRecoverd"`
-		DataCases           []int  `json:"dataCases"`
-		DataCasesFromFirst  []int  `json:"dataCasesFromFirst"`
+		Country             string                          `json:"country"`
+		DataDeaths          []TimelinePointExpectedResponse `json:"dataDeaths"`
+		DataDeathsFromFirst []TimelinePointExpectedResponse `json:"dataDeathsFromFirst"`
+		DataDeathsPerDay    []TimelinePointExpectedResponse `json:"dataDeathsPerDay"`
+		DataRecoverd        []TimelinePointExpectedResponse `json:"dataRecoverd"`
+		DataCases           []TimelinePointExpectedResponse `json:"dataCases"`
+		DataCasesFromFirst  []TimelinePointExpectedResponse `json:"dataCasesFromFirst"`
 	} `json:"countryOne"`
 	CountryTwo struct {
-		Country             string `json:"country"`
-		DataDeaths          []int  `json:"dataDeaths"`
-		DataDeathsFromFirst []int  `json:"dataDeathsFromFirst"`
-		DataDeathsPerDay    []int  `json:"dataDeathsPerDay"`
-		DataRecoverd        []int  `json:"dataRecoverd"`
-		DataCases           []int  `json:"dataCases"`
-		DataCasesFromFirst  []int  `json:"dataCasesFromFirst"`
+		Country             string                          `json:"country"`
+		DataDeaths          []TimelinePointExpectedResponse `json:"dataDeaths"`
+		DataDeathsFromFirst []TimelinePointExpectedResponse `json:"dataDeathsFromFirst"`
+		DataDeathsPerDay    []TimelinePointExpectedResponse `json:"dataDeathsPerDay"`
+		DataRecoverd        []TimelinePointExpectedResponse `json:"dataRecoverd"`
+		DataCases           []TimelinePointExpectedResponse `json:"dataCases"`
+		DataCasesFromFirst  []TimelinePointExpectedResponse `json:"dataCasesFromFirst"`
 	} `json:"countryTwo"`
 }
 
@@ -0,0 +1,169 @@
+package comparectl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	curve "github.com/junkd0g/covid/lib/curve"
+	registry "github.com/junkd0g/covid/lib/registry"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+	merror "github.com/junkd0g/neji"
+)
+
+//CountriesRequest used for the /api/compare request's body, every metric
+//is returned when Metrics is empty. Normalize is per100k or perMillion to
+//divide the series by the countries' population and Smoothing smooths the
+//per day series (check smoothing.Parse)
+type CountriesRequest struct {
+	Countries []string `json:"countries"`
+	Metrics   []string `json:"metrics"`
+	Normalize string   `json:"normalize"`
+	Smoothing string   `json:"smoothing"`
+}
+
+// CountriesHandle POST request to /api/compare endpoint
+/*
+	The metrics are deaths, deathsPerDay, deathsFromFirstDeath,
+	deathsPerDayFromFirstDeath, cases, casesPerDay, recovered and recoveredPerDay.
+	The optional normalize is per100k or perMillion and the optional
+	smoothing (trailing:N, centered:N, exponential:A, clip or a method and
+	clip like trailing:7,clip) smooths the per day metrics
+
+	Request:
+
+	{
+		"countries" : ["Spain", "Italy", "Greece"],
+		"metrics" : ["deaths", "casesPerDay"],
+		"smoothing" : "trailing:7"
+	}
+
+	Response
+
+	{
+    "data": {
+        "Greece": {
+            "casesPerDay": [
+                { "date": "2020-06-10", "value": 15 },
+                { "date": "2020-06-11", "value": 0 }
+            ],
+            "deaths": [
+                { "date": "2020-06-10", "value": 182 },
+                { "date": "2020-06-11", "value": 183 }
+            ]
+        },
+        "Italy": {
+            "casesPerDay": [
+                { "date": "2020-06-10", "value": 202 },
+                { "date": "2020-06-11", "value": 379 }
+            ],
+            "deaths": [
+                { "date": "2020-06-10", "value": 34114 },
+                { "date": "2020-06-11", "value": 34167 }
+            ]
+        },
+        "Spain": {
+            "casesPerDay": [
+                { "date": "2020-06-10", "value": 314 },
+                { "date": "2020-06-11", "value": 427 }
+            ],
+            "deaths": [
+                { "date": "2020-06-10", "value": 27136 },
+                { "date": "2020-06-11", "value": 27136 }
+            ]
+        }
+    }
+}
+*/
+func CountriesHandle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status, stale := performCountries(r)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "compare", "CountriesHandle",
+		"Endpoint /api/compare called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//PerformCountries used in the /api/compare endpoint's handle to return the
+//metrics of every country of the request by calling curve.Compare.
+//A request with no countries or an unknown metric or normalization is a 400,
+//normalizing a country whose population is missing is a 500
+//	@param r *http.Request used to get http request's body
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func performCountries(r *http.Request) ([]byte, int, bool) {
+	var countriesRequest CountriesRequest
+
+	b, errIoutilReadAll := ioutil.ReadAll(r.Body)
+	if errIoutilReadAll != nil {
+		applogger.Log("ERROR", "compare", "performCountries", errIoutilReadAll.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, errIoutilReadAll)
+		return statsErrJSONBody, 500, false
+	}
+
+	unmarshallError := json.Unmarshal(b, &countriesRequest)
+	if unmarshallError != nil {
+		applogger.Log("ERROR", "compare", "performCountries", unmarshallError.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, unmarshallError)
+		return statsErrJSONBody, 400, false
+	}
+
+	applogger.Log("INFO", "compare", "performCountries",
+		fmt.Sprintf("Getting this request %v", countriesRequest))
+
+	smoothingOptions, errSmoothing := smoothing.Parse(countriesRequest.Smoothing)
+	if errSmoothing != nil {
+		applogger.Log("ERROR", "compare", "performCountries", errSmoothing.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errSmoothing)
+		return statsErrJSONBody, 400, false
+	}
+
+	compare, compareErr := curve.Compare(curve.CompareQuery{
+		Countries: countriesRequest.Countries,
+		Metrics:   countriesRequest.Metrics,
+		Normalize: countriesRequest.Normalize,
+		Smoothing: smoothingOptions,
+	})
+	if compareErr != nil {
+		applogger.Log("ERROR", "compare", "performCountries", compareErr.Error())
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(compareErr); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(compareErr)
+		if badCompareRequest(compareErr) {
+			status = 400
+		}
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, compareErr)
+		return statsErrJSONBody, status, false
+	}
+
+	_, compare.Stale = curve.Stale()
+	jsonBody, jsonBodyErr := json.Marshal(compare)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "compare", "performCountries", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, compare.Stale
+}
+
+//badCompareRequest reports whether curve.Compare failed because of
+//the request
+func badCompareRequest(err error) bool {
+	return errors.Is(err, curve.ErrNoCountries) || errors.Is(err, curve.ErrUnknownMetric) ||
+		errors.Is(err, curve.ErrUnknownNormalization)
+}
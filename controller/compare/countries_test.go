@@ -0,0 +1,68 @@
+package comparectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type CompareCountriesExpectedResponse struct {
+	Data map[string]map[string][]TimelinePointExpectedResponse `json:"data"`
+}
+
+func Test_APICompareCountries(t *testing.T) {
+	var jsonStr = []byte(`{"countries" : ["Spain", "Italy", "Greece"], "metrics" : ["deaths", "casesPerDay"]}`)
+
+	req, err := http.NewRequest("POST", "/api/compare", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(CountriesHandle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var ccer CompareCountriesExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &ccer)
+
+	if len(ccer.Data) != 3 {
+		t.Fatalf("Expecting 3 countries having %d", len(ccer.Data))
+	}
+
+	for country, metrics := range ccer.Data {
+		if len(metrics) != 2 || len(metrics["deaths"]) == 0 || len(metrics["casesPerDay"]) == 0 {
+			t.Errorf("Wrong metrics of %s", country)
+		}
+	}
+}
+
+func Test_APICompareCountriesBadRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"countries" : []}`,
+		`{"countries" : ["Spain"], "metrics" : ["population"]}`,
+		`{"countries" : "Spain"}`,
+		`{"countries" : ["Spain"], "normalize" : "perCapita"}`,
+		`{"countries" : ["Spain"], "smoothing" : "weekly"}`,
+	} {
+		req, err := http.NewRequest("POST", "/api/compare", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(CountriesHandle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v",
+				body, status, http.StatusBadRequest)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	continent "github.com/junkd0g/covid/lib/continent"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
@@ -86,7 +88,10 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform()
+	jsonBody, status, stale := perform()
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -97,21 +102,24 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //Perform used in the /api/continent endpoint's handle to return
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform() ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform() ([]byte, int, bool) {
 
 	continentData, err := continent.GetContinentData()
 	if err != nil {
 		applogger.Log("ERROR", "continentct", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
 	jsonBody, jsonBodyErr := json.Marshal(continentData)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "continentct", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
 
-	return jsonBody, 200
+	_, stale := continent.Stale()
+	return jsonBody, 200, stale
 }
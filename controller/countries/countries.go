@@ -2,16 +2,52 @@ package countriescon
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
 /*
-	Get request to /api/countries with no parameters
+	Get request to /api/countries with optional parameters
+		filter    comma separated conditions field:op:value, op is one of
+		          eq, ne, gt, gte, lt, lte
+		continent comma separated continents the countries are in
+		prefix    the start of the country's name
+		contains  a part of the country's name
+		sort      comma separated sort keys field:order, order is asc or
+		          desc (default)
+		limit     maximum number of countries, all of them when it is missing
+		offset    number of countries to skip
+		fields    comma separated fields of the countries in the response
+
+	The fields are the ones of a country and the ratios deathRate,
+	recoveryRate, criticalRate, positivityRate and todayCasesRate
+
+	/api/countries?filter=cases:gt:10000,deaths:lt:500&continent=Europe&sort=cases:desc&limit=2&fields=country,cases,deaths
+
+	Response:
+
+	{
+		"data": [
+			{ "country": "Belarus", "cases": 49453, "deaths": 276 },
+			{ "country": "Serbia", "cases": 11896, "deaths": 250 }
+		],
+		"total": 2,
+		"limit": 2,
+		"offset": 0
+	}
+
+	and with no parameters
 
 	Response:
 
@@ -43,14 +79,19 @@ import (
 				"tests": 48305,
             	"testsPerOneMillion": 1243
 			}
-		]
+		],
+		"total": 215,
+		"offset": 0
 	}
 */
 func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform()
+	jsonBody, status, stale := perform(r.URL.Query())
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -59,8 +100,9 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 }
 
 //Perform used in the /countries endpoint's handle to return
-//	the structs.Countries struct as a json response by calling
-//	stats.GetAllCountries() which returns grobal statistics
+//	the mcountry.CountriesPage struct as a json response by calling
+//	stats.QueryCountries() with the query of the request's parameters,
+//	an unknown field, operator or continent is a 400
 //
 //	Array of all countries' object data. Country string value of country name,
 //	cases integer in total confirm cases of the country, todayCases int contains
@@ -87,23 +129,100 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //		]
 //	}
 //
+//	@param query url.Values the filtering, sorting and pagination parameters
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform() ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform(query url.Values) ([]byte, int, bool) {
+	q, errQuery := parseQuery(query)
+	if errQuery != nil {
+		applogger.Log("ERROR", "countriescon", "perform", errQuery.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errQuery)
+		return errorJSONBody, 400, false
+	}
 
-	countries, err := stats.GetAllCountries()
+	countries, err := stats.QueryCountries(q)
 	if err != nil {
 		applogger.Log("ERROR", "countriescon", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(err)
+		if errors.Is(err, stats.ErrUnknownField) || errors.Is(err, stats.ErrUnknownOperator) ||
+			errors.Is(err, stats.ErrUnknownContinent) {
+			status = 400
+		}
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
+	_, countries.Stale = stats.Stale()
 	jsonBody, jsonBodyErr := json.Marshal(countries)
-	if err != nil {
+	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "countriescon", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
 
-	return jsonBody, 200
+	return jsonBody, 200, countries.Stale
+}
+
+//parseQuery returns the stats.Query of the request's parameters
+func parseQuery(query url.Values) (stats.Query, error) {
+	var q stats.Query
+
+	for _, v := range list(query, "filter") {
+		parts := strings.Split(v, ":")
+		if len(parts) != 3 {
+			return q, fmt.Errorf("malformed filter %q, expecting field:op:value", v)
+		}
+		value, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return q, fmt.Errorf("malformed value of filter %q, expecting a number", v)
+		}
+		q.Conditions = append(q.Conditions, stats.Condition{Field: parts[0], Op: parts[1], Value: value})
+	}
+
+	for _, v := range list(query, "sort") {
+		parts := strings.Split(v, ":")
+		key := stats.SortKey{Field: parts[0]}
+		if len(parts) > 2 || (len(parts) == 2 && parts[1] != "asc" && parts[1] != "desc") {
+			return q, fmt.Errorf("malformed sort %q, expecting field:asc or field:desc", v)
+		}
+		key.Ascending = len(parts) == 2 && parts[1] == "asc"
+		q.Sort = append(q.Sort, key)
+	}
+
+	for _, name := range []string{"limit", "offset"} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("%s must be a non negative integer", name)
+		}
+		if name == "limit" {
+			q.Limit = n
+		} else {
+			q.Offset = n
+		}
+	}
+
+	q.Continents = list(query, "continent")
+	q.Fields = list(query, "fields")
+	q.Prefix = query.Get("prefix")
+	q.Contains = query.Get("contains")
+
+	return q, nil
+}
+
+//list returns the comma separated values of all the name parameters
+func list(query url.Values, name string) []string {
+	var values []string
+	for _, v := range query[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
 }
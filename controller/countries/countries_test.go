@@ -53,3 +53,61 @@ func Test_APITotal(t *testing.T) {
 		t.Errorf("Cases field looks broken")
 	}
 }
+
+func Test_APICountriesQuery(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/countries?filter=cases:gt:10000&sort=cases:asc&limit=3&fields=country,cases", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var cer struct {
+		Data  []map[string]interface{} `json:"data"`
+		Total int                      `json:"total"`
+	}
+	json.Unmarshal([]byte(rr.Body.String()), &cer)
+
+	if len(cer.Data) != 3 || cer.Total < 3 {
+		t.Fatalf("Expecting 3 countries having %d of %d", len(cer.Data), cer.Total)
+	}
+
+	for _, v := range cer.Data {
+		if len(v) != 2 || v["cases"].(float64) <= 10000 {
+			t.Errorf("Wrong country in the response %v", v)
+		}
+	}
+}
+
+func Test_APICountriesBadQuery(t *testing.T) {
+	for _, query := range []string{
+		"filter=cases:gt",
+		"filter=cases:gt:many",
+		"filter=vaccinated:gt:10",
+		"filter=cases:bigger:10",
+		"sort=cases:up",
+		"fields=country,vaccinated",
+		"limit=-1",
+	} {
+		req, err := http.NewRequest("GET", "/api/countries?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(Handle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v",
+				query, status, http.StatusBadRequest)
+		}
+	}
+}
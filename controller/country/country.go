@@ -5,7 +5,10 @@ import (
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	registry "github.com/junkd0g/covid/lib/registry"
 	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 
 	"io/ioutil"
@@ -46,7 +49,10 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform(r)
+	jsonBody, status, stale := perform(r)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -89,14 +95,15 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform(r *http.Request) ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform(r *http.Request) ([]byte, int, bool) {
 	var countryRequest CountryRequest
 
 	b, errIoutilReadAll := ioutil.ReadAll(r.Body)
 	if errIoutilReadAll != nil {
 		applogger.Log("ERROR", "countrycon", "perform", errIoutilReadAll.Error())
 		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, errIoutilReadAll)
-		return statsErrJSONBody, 500
+		return statsErrJSONBody, 500, false
 	}
 
 	json.Unmarshal(b, &countryRequest)
@@ -104,16 +111,21 @@ func perform(r *http.Request) ([]byte, int) {
 	country, err := stats.GetCountry(countryRequest.Name)
 	if err != nil {
 		applogger.Log("ERROR", "countrycon", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
 	jsonBody, jsonBodyErr := json.Marshal(country)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "countrycon", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
 
-	return jsonBody, 200
+	_, stale := stats.Stale()
+	return jsonBody, 200, stale
 }
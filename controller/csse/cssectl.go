@@ -7,7 +7,10 @@ import (
 
 	"github.com/gorilla/mux"
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	csse "github.com/junkd0g/covid/lib/csse"
+	registry "github.com/junkd0g/covid/lib/registry"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
@@ -16,20 +19,39 @@ import (
 
 	{
     	"country": "US",
+    	"totals": {
+        	"cases": 3431574,
+        	"deaths": 136466,
+        	"recovered": 1049098
+    	},
+    	"provinces": {
+        	"South Carolina": {
+            	"cases": 62148,
+            	"deaths": 1006,
+            	"recovered": 0
+        	}
+    	},
     	"data": [
         	{
-            	"county": "<nil>",
+            	"county": "Abbeville",
+            	"province": "South Carolina",
+            	"cases": 60389,
+            	"deaths": 993,
+            	"recovered": 0,
+            	"coordinates": {
+                	"latitude": 34.22333378,
+                	"longitude": -82.46170658
+            	},
+            	"updatedAt": "2020-07-15T04:34:39Z"
+        	},
+        	{
+            	"county": null,
             	"province": "Diamond Princess",
             	"cases": 49,
             	"deaths": 0,
-            	"recovered": 0
-        	},
-        	{
-            	"county": "<nil>",
-            	"province": "Grand Princess",
-            	"cases": 103,
-            	"deaths": 3,
-            	"recovered": 0
+            	"recovered": 0,
+            	"coordinates": null,
+            	"updatedAt": "2020-07-15T04:34:39Z"
 			}
 		]
 	}
@@ -39,7 +61,10 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
-	jsonBody, status := perform(vars["country"])
+	jsonBody, status, stale := perform(vars["country"])
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -50,21 +75,166 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //Perform used in the /api/csse/{country} endpoint's handle to return
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform(country string) ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform(country string) ([]byte, int, bool) {
 
 	csseData, err := csse.GetCSSECountryData(country)
 	if err != nil {
 		applogger.Log("ERROR", "cssectl", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
+	_, csseData.Stale = csse.Stale()
 	jsonBody, jsonBodyErr := json.Marshal(csseData)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "cssectl", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, csseData.Stale
+}
+
+/*
+	Get request to /api/csse, the totals of every country and of all of them
+
+	{
+		"totals": {
+			"cases": 13323011,
+			"deaths": 578628,
+			"recovered": 7371364
+		},
+		"countries": [
+			{
+				"country": "Afghanistan",
+				"provinces": 0,
+				"totals": {
+					"cases": 34740,
+					"deaths": 1045,
+					"recovered": 21454
+				}
+			}
+		]
+	}
+*/
+func SummaryHandle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status, stale := performSummary()
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "cssectl", "SummaryHandle",
+		"Endpoint /api/csse called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//performSummary used in the /api/csse endpoint's handle to return the
+//totals of every country
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func performSummary() ([]byte, int, bool) {
+	summary, err := csse.GetCSSESummary()
+	if err != nil {
+		applogger.Log("ERROR", "cssectl", "performSummary", err.Error())
+		status := upstream.HTTPStatus(err)
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return errorJSONBody, status, false
+	}
+
+	_, summary.Stale = csse.Stale()
+	jsonBody, jsonBodyErr := json.Marshal(summary)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "cssectl", "performSummary", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, summary.Stale
+}
+
+/*
+	Get request to /api/csse/{country}/geojson, the counties and the
+	provinces of a country that have coordinates as a GeoJSON
+	FeatureCollection of points
+
+	{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"geometry": {
+					"type": "Point",
+					"coordinates": [-82.46170658, 34.22333378]
+				},
+				"properties": {
+					"country": "US",
+					"province": "South Carolina",
+					"county": "Abbeville",
+					"cases": 60389,
+					"deaths": 993,
+					"recovered": 0,
+					"updatedAt": "2020-07-15T04:34:39Z"
+				}
+			}
+		]
+	}
+*/
+func GeoJSONHandle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	vars := mux.Vars(r)
+	jsonBody, status, stale := performGeoJSON(vars["country"])
+	if status == 200 {
+		w.Header().Set("Content-Type", "application/geo+json")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "cssectl", "GeoJSONHandle",
+		"Endpoint /api/csse/{country}/geojson called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//performGeoJSON used in the /api/csse/{country}/geojson endpoint's handle
+//to return the FeatureCollection of a country, 404 when there are no csse
+//data for the country
+//	@param country string name of the country
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func performGeoJSON(country string) ([]byte, int, bool) {
+	csseData, err := csse.GetCSSECountryData(country)
+	if err != nil {
+		applogger.Log("ERROR", "cssectl", "performGeoJSON", err.Error())
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(err)
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return errorJSONBody, status, false
+	}
+
+	jsonBody, jsonBodyErr := json.Marshal(csse.GeoJSON(csseData))
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "cssectl", "performGeoJSON", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
 	}
 
-	return jsonBody, 200
+	_, stale := csse.Stale()
+	return jsonBody, 200, stale
 }
@@ -69,3 +69,53 @@ func Test_APIDifferentNameCsse(t *testing.T) {
 	}
 
 }
+
+func Test_APICsseGeoJSON(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/csse/Russia/geojson", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{
+		"country": "Russia",
+	})
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(GeoJSONHandle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var fc mcsse.FeatureCollection
+	json.Unmarshal([]byte(rr.Body.String()), &fc)
+
+	if fc.Type != "FeatureCollection" {
+		t.Errorf("Wrong type %s", fc.Type)
+	}
+	if len(fc.Features) == 0 {
+		t.Errorf("No features")
+	}
+}
+
+func Test_APICsseSummary(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/csse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(SummaryHandle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var summary mcsse.Summary
+	json.Unmarshal([]byte(rr.Body.String()), &summary)
+
+	if len(summary.Countries) == 0 || summary.Totals.Cases == 0 {
+		t.Errorf("No summary %+v", summary)
+	}
+}
@@ -0,0 +1,187 @@
+package curvectl
+
+/*
+	Controller used for the endpoints:
+		/api/curve/provinces
+		/api/curve/{country}/{province}
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	curve "github.com/junkd0g/covid/lib/curve"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	registry "github.com/junkd0g/covid/lib/registry"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+	merror "github.com/junkd0g/neji"
+)
+
+/*
+	Get request to /api/curve/{country}/{province}, the curves (total and
+	per day) of a province or state of a country
+
+	/api/curve/China/Hubei
+
+	Response:
+
+{
+    "country": "China",
+    "province": "hubei",
+    "cases": [
+        { "date": "2020-01-22", "value": 444 },
+        { "date": "2020-01-23", "value": 444 }
+    ],
+    "casesPerDay": [
+        { "date": "2020-01-23", "value": 0 }
+    ],
+    "deaths": [
+        { "date": "2020-01-22", "value": 17 },
+        { "date": "2020-01-23", "value": 17 }
+    ],
+    "deathsPerDay": [
+        { "date": "2020-01-23", "value": 0 }
+    ],
+    "recovered": [
+        { "date": "2020-01-22", "value": 28 },
+        { "date": "2020-01-23", "value": 28 }
+    ],
+    "recoveredPerDay": [
+        { "date": "2020-01-23", "value": 0 }
+    ]
+}
+*/
+func Handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	jsonBody, status, stale := perform(vars["country"], vars["province"])
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "curvectl", "Handle",
+		"Endpoint /api/curve called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//Perform used in the /api/curve/{country}/{province} endpoint's handle to
+//return the curves of a province. It responds with 400 when there is no
+//country or province and 404 when there are no curves for either
+//	@param country string name of the country
+//	@param province string name of the province
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func perform(country string, province string) ([]byte, int, bool) {
+	if strings.TrimSpace(country) == "" || strings.TrimSpace(province) == "" {
+		errRequired := errors.New("country and province are required")
+		applogger.Log("ERROR", "curvectl", "perform", errRequired.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errRequired)
+		return errorJSONBody, 400, false
+	}
+
+	countries, err := curve.GetAllCountries()
+	if err != nil {
+		return errorResponse("perform", err)
+	}
+
+	provinceCurve, err := curve.GetProvince(country, province, countries)
+	if err != nil {
+		return errorResponse("perform", err)
+	}
+
+	_, provinceCurve.Stale = curve.Stale()
+	return response("perform", provinceCurve, provinceCurve.Stale)
+}
+
+/*
+	Get request to /api/curve/provinces, the countries that have curves per
+	province or state with their provinces
+
+	Response:
+
+{
+    "data": [
+        {
+            "country": "Australia",
+            "provinces": [
+                "australian capital territory",
+                "new south wales"
+            ]
+        },
+        {
+            "country": "China",
+            "provinces": [
+                "beijing",
+                "hubei"
+            ]
+        }
+    ]
+}
+*/
+func ProvincesHandle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status, stale := performProvinces()
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "curvectl", "ProvincesHandle",
+		"Endpoint /api/curve/provinces called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//performProvinces used in the /api/curve/provinces endpoint's handle to
+//return the provinces of every country
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func performProvinces() ([]byte, int, bool) {
+	countries, err := curve.GetAllCountries()
+	if err != nil {
+		return errorResponse("performProvinces", err)
+	}
+
+	_, stale := curve.Stale()
+	return response("performProvinces", mcountry.Provinces{Data: curve.Provinces(countries), Stale: stale}, stale)
+}
+
+//errorResponse returns 404 when there are no curves for the country or
+//the province and the status of upstream.HTTPStatus for any other error
+func errorResponse(function string, err error) ([]byte, int, bool) {
+	applogger.Log("ERROR", "curvectl", function, err.Error())
+	if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+		return notFoundJSONBody, 404, false
+	}
+
+	status := upstream.HTTPStatus(err)
+	if errors.Is(err, curve.ErrProvinceNotFound) {
+		status = 404
+	}
+	errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+	return errorJSONBody, status, false
+}
+
+//response returns the json of data with the staleness it was read with
+func response(function string, data interface{}, stale bool) ([]byte, int, bool) {
+	jsonBody, jsonBodyErr := json.Marshal(data)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "curvectl", function, jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, stale
+}
@@ -0,0 +1,87 @@
+package curvectl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	curve "github.com/junkd0g/covid/lib/curve"
+)
+
+type ProvinceExpectedResponse struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+}
+
+type ProvincesExpectedResponse struct {
+	Data []struct {
+		Country   string   `json:"country"`
+		Provinces []string `json:"provinces"`
+	} `json:"data"`
+}
+
+func serve(t *testing.T, url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/curve/provinces", ProvincesHandle).Methods("GET")
+	router.HandleFunc("/api/curve/{country}/{province}", Handle).Methods("GET")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_APICurveProvince(t *testing.T) {
+	rr := serve(t, "/api/curve/China/Hubei")
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var per ProvinceExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &per)
+
+	if per.Country != "China" || per.Province != "hubei" {
+		t.Errorf("Wrong province %s of %s", per.Province, per.Country)
+	}
+}
+
+func Test_APICurveProvinces(t *testing.T) {
+	rr := serve(t, "/api/curve/provinces")
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var per ProvincesExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &per)
+
+	if len(per.Data) == 0 {
+		t.Errorf("Expecting countries with provinces")
+	}
+}
+
+func Test_APICurveBadRequest(t *testing.T) {
+	for _, vars := range [][2]string{{" ", "hubei"}, {"China", " "}} {
+		if _, status, _ := perform(vars[0], vars[1]); status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, http.StatusBadRequest)
+		}
+	}
+}
+
+func Test_APICurveProvinceNotFound(t *testing.T) {
+	err := fmt.Errorf("%w: %q of China", curve.ErrProvinceNotFound, "Atlantis")
+	if _, status, _ := errorResponse("Test", err); status != http.StatusNotFound {
+		t.Errorf("errorResponse returned wrong status code: got %v want %v",
+			status, http.StatusNotFound)
+	}
+}
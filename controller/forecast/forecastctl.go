@@ -0,0 +1,143 @@
+package forecastctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	curve "github.com/junkd0g/covid/lib/curve"
+	cworld "github.com/junkd0g/covid/lib/cworld"
+	forecast "github.com/junkd0g/covid/lib/forecast"
+	mforecast "github.com/junkd0g/covid/lib/model/forecast"
+	registry "github.com/junkd0g/covid/lib/registry"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+	merror "github.com/junkd0g/neji"
+)
+
+/*
+	Get request to /api/forecast/{country}, the projection of a metric of a
+	country, /api/forecast/world for the world. The optional parameters are
+	days (1 to 60, 14 by default), metric (any metric of /api/compare, cases
+	by default) and method (holt or loglinear, holt by default).
+	lower and upper are the 95% prediction interval and backtest the mean
+	absolute percentage error of the method on the last days of the series
+
+	/api/forecast/Greece?days=3&metric=cases&method=holt
+
+	Response:
+
+{
+    "country": "Greece",
+    "metric": "cases",
+    "method": "holt",
+    "days": 3,
+    "confidence": 0.95,
+    "forecast": [
+        { "date": "2020-06-13", "value": 3121.2, "lower": 3098.5, "upper": 3143.9 },
+        { "date": "2020-06-14", "value": 3137.4, "lower": 3100.1, "upper": 3174.7 },
+        { "date": "2020-06-15", "value": 3153.6, "lower": 3099.2, "upper": 3208 }
+    ],
+    "backtest": {
+        "days": 3,
+        "mape": 0.41
+    }
+}
+*/
+func Handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	country := mux.Vars(r)["country"]
+	jsonBody, status, stale := perform(country, r.URL.Query())
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "forecastctl", "Handle",
+		"Endpoint /api/forecast called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//Perform used in the /api/forecast endpoint's handle to return the
+//projection of a metric of a country or of the world. It responds with 400
+//when a parameter is invalid, 404 when there are no curves for the country
+//and 422 when the series is too short to project
+//	@param country string name of the country or world
+//	@param query url.Values the parameters days, metric and method
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func perform(country string, query url.Values) ([]byte, int, bool) {
+	if strings.TrimSpace(country) == "" {
+		return badRequest(errors.New("country is required"))
+	}
+
+	var options forecast.Options
+	if days := query.Get("days"); days != "" {
+		i, errAtoi := strconv.Atoi(days)
+		if errAtoi != nil || i < 1 {
+			return badRequest(fmt.Errorf("%w %q", forecast.ErrInvalidDays, days))
+		}
+		options.Days = i
+	}
+	options.Method = query.Get("method")
+
+	metric, options, errValidate := forecast.ValidateMetric(query.Get("metric"), options)
+	if errValidate != nil {
+		return badRequest(errValidate)
+	}
+
+	var projection mforecast.Forecast
+	var err error
+	if cworld.IsWorld(country) {
+		projection, err = cworld.Forecast(metric, options)
+	} else {
+		projection, err = forecast.Country(country, metric, options)
+	}
+	if err != nil {
+		applogger.Log("ERROR", "forecastctl", "perform", err.Error())
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(err)
+		if errors.Is(err, forecast.ErrNotEnoughData) {
+			status = 422
+		}
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return errorJSONBody, status, false
+	}
+
+	_, projection.Stale = staleSince(country)
+	jsonBody, jsonBodyErr := json.Marshal(projection)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "forecastctl", "perform", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, projection.Stale
+}
+
+func badRequest(err error) ([]byte, int, bool) {
+	applogger.Log("ERROR", "forecastctl", "perform", err.Error())
+	errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, err)
+	return errorJSONBody, 400, false
+}
+
+// staleSince returns since when the data the forecast of country is
+// computed from are served from their last known good copy
+func staleSince(country string) (time.Time, bool) {
+	if cworld.IsWorld(country) {
+		return cworld.Stale()
+	}
+	return curve.Stale()
+}
@@ -0,0 +1,68 @@
+package forecastctl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type ForecastExpectedResponse struct {
+	Country  string        `json:"country"`
+	Metric   string        `json:"metric"`
+	Forecast []interface{} `json:"forecast"`
+}
+
+func serve(t *testing.T, url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/forecast/{country}", Handle).Methods("GET")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_APIForecast(t *testing.T) {
+	for url, country := range map[string]string{
+		"/api/forecast/Greece?days=7":                    "Greece",
+		"/api/forecast/world?days=7&metric=deathsPerDay": "World",
+		"/api/forecast/Greece?days=7&method=loglinear":   "Greece",
+	} {
+		rr := serve(t, url)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, http.StatusOK)
+		}
+
+		var fer ForecastExpectedResponse
+		json.Unmarshal([]byte(rr.Body.String()), &fer)
+
+		if fer.Country != country || len(fer.Forecast) != 7 {
+			t.Errorf("Wrong forecast of %s: %s", country, rr.Body.String())
+		}
+	}
+}
+
+func Test_APIForecastBadRequest(t *testing.T) {
+	for _, url := range []string{
+		"/api/forecast/Greece?days=two",
+		"/api/forecast/Greece?days=0",
+		"/api/forecast/Greece?days=61",
+		"/api/forecast/Greece?metric=hospitalized",
+		"/api/forecast/world?method=arima",
+	} {
+		rr := serve(t, url)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v",
+				url, status, http.StatusBadRequest)
+		}
+	}
+}
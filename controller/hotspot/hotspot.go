@@ -2,18 +2,30 @@ package hotspot
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	analytics "github.com/junkd0g/covid/lib/analytics"
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	curve "github.com/junkd0g/covid/lib/curve"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
 /*
-	Get request to /api/hotspot with no parameters
+	Get request to /api/hotspot/{days}, days from 1 to 365, with the optional parameter smoothing
+	(trailing:N, centered:N, exponential:A, clip or a method and clip like
+	trailing:7,clip) to smooth the per day series before taking their last days
+
+	/api/hotspot/3?smoothing=trailing:7
 
 	Response:
 
@@ -21,107 +33,104 @@ import (
     "mostCases": {
         "country": "Brazil",
         "data": [
-            20599,
-            26417,
-            26928,
-            33274,
-            16409,
-            11598,
-            28936,
-            28633,
-            30925,
-            30830,
-            27075
+            { "date": "2020-06-10", "value": 20599 },
+            { "date": "2020-06-11", "value": 26417 },
+            { "date": "2020-06-12", "value": 26928 }
         ]
     },
     "secondCases": {
         "country": "USA",
         "data": [
-            18263,
-            22577,
-            24266,
-            24146,
-            20007,
-            20848,
-            20801,
-            19699,
-            21140,
-            24720,
-            22681
+            { "date": "2020-06-10", "value": 18263 },
+            { "date": "2020-06-11", "value": 22577 },
+            { "date": "2020-06-12", "value": 24266 }
         ]
     },
     "thirdCases": {
         "country": "Russia",
         "data": [
-            8338,
-            8371,
-            8572,
-            8952,
-            9268,
-            8485,
-            8858,
-            8529,
-            8823,
-            8718,
-            8846
+            { "date": "2020-06-10", "value": 8338 },
+            { "date": "2020-06-11", "value": 8371 },
+            { "date": "2020-06-12", "value": 8572 }
         ]
     },
     "mostDeaths": {
         "country": "USA",
         "data": [
-            1505,
-            1199,
-            1193,
-            967,
-            605,
-            768,
-            1031,
-            995,
-            1036,
-            921,
-            670
+            { "date": "2020-06-10", "value": 1505 },
+            { "date": "2020-06-11", "value": 1199 },
+            { "date": "2020-06-12", "value": 1193 }
         ]
     },
     "secondDeaths": {
         "country": "Brazil",
         "data": [
-            1086,
-            1156,
-            1124,
-            956,
-            480,
-            623,
-            1262,
-            1349,
-            1473,
-            1005,
-            904
+            { "date": "2020-06-10", "value": 1086 },
+            { "date": "2020-06-11", "value": 1156 },
+            { "date": "2020-06-12", "value": 1124 }
         ]
     },
     "thirdDeaths": {
         "country": "Mexico",
         "data": [
-            463,
-            447,
-            371,
-            364,
-            151,
-            237,
-            470,
-            1092,
-            816,
-            625,
-            341
+            { "date": "2020-06-10", "value": 463 },
+            { "date": "2020-06-11", "value": 447 },
+            { "date": "2020-06-12", "value": 371 }
         ]
     }
 }
+
+	With any of the optional parameters top (3 by default), metric
+	(casesPerDay, deathsPerDay or recoveredPerDay, casesPerDay by default),
+	by (absolute, perCapita or acceleration, absolute by default) and
+	continent it returns the top countries of the metric over the last days.
+	absolute ranks by the sum of the last days, perCapita by that sum per
+	100k people and acceleration by how much it grew from the days before
+
+	/api/hotspot/7?top=2&metric=deathsPerDay&by=acceleration&continent=Europe
+
+	Response:
+
+{
+    "metric": "deathsPerDay",
+    "days": 7,
+    "by": "acceleration",
+    "continent": "Europe",
+    "countries": [
+        {
+            "rank": 1,
+            "country": "Sweden",
+            "value": 31,
+            "total": 198,
+            "previous": 167,
+            "data": [
+                { "date": "2020-06-06", "value": 19 },
+                ...
+            ]
+        },
+        {
+            "rank": 2,
+            "country": "Portugal",
+            "value": 4,
+            "total": 15,
+            "previous": 11,
+            "data": [
+                { "date": "2020-06-06", "value": 3 },
+                ...
+            ]
+        }
+    ]
+}
 */
 func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
-	jsonBody, status := perform(vars["days"])
+	jsonBody, status, stale := perform(vars["days"], r.URL.Query())
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -129,29 +138,91 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		"Endpoint /api/hotspot called with response JSON body "+string(jsonBody), status, elapsed)
 }
 
-//Perform used in the /api/hotspot endpoint's handle to return
+//Perform used in the /api/hotspot endpoint's handle to return the three
+//countries with the most cases and deaths in the last days or, when the
+//request has a ranking parameter, the ranking of analytics.Rank. It
+//responds with 400 when days is not between 1 and analytics.MaxWindow
+//	@param days string number of the last days
+//	@param query url.Values the parameters smoothing, top, metric, by and continent
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform(days string) ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform(days string, query url.Values) ([]byte, int, bool) {
 	i, errAtoi := strconv.Atoi(days)
 	if errAtoi != nil {
-		applogger.Log("ERROR", "hotspot", "perform", errAtoi.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errAtoi)
-		return statsErrJSONBody, 400
+		return badRequest(errAtoi)
+	}
+	if errWindow := analytics.ValidateWindow(i); errWindow != nil {
+		return badRequest(errWindow)
+	}
+
+	smoothingOptions, errSmoothing := smoothing.Parse(query.Get("smoothing"))
+	if errSmoothing != nil {
+		return badRequest(errSmoothing)
+	}
+
+	var data interface{}
+	var err error
+	var stale bool
+	if isRanking(query) {
+		q := analytics.RankQuery{
+			Days:      i,
+			Metric:    query.Get("metric"),
+			By:        query.Get("by"),
+			Continent: query.Get("continent"),
+			Smoothing: smoothingOptions,
+		}
+		if top := query.Get("top"); top != "" {
+			if q.Top, err = strconv.Atoi(top); err != nil || q.Top < 1 {
+				return badRequest(fmt.Errorf("%w %q", analytics.ErrInvalidTop, top))
+			}
+		}
+		if q, err = q.Validate(); err != nil {
+			return badRequest(err)
+		}
+
+		ranking, errRank := analytics.Rank(q)
+		_, stale = curve.Stale()
+		ranking.Stale = stale
+		data, err = ranking, errRank
+	} else {
+		hotspot, errHotspot := analytics.MostCasesDeathsNearPast(i, smoothingOptions)
+		_, stale = curve.Stale()
+		hotspot.Stale = stale
+		data, err = hotspot, errHotspot
 	}
 
-	worldData, err := analytics.MostCasesDeathsNearPast(i)
 	if err != nil {
+		if errors.Is(err, stats.ErrUnknownContinent) {
+			return badRequest(err)
+		}
 		applogger.Log("ERROR", "hotspot", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
-	jsonBody, jsonBodyErr := json.Marshal(worldData)
+	jsonBody, jsonBodyErr := json.Marshal(data)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "hotspot", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
-	return jsonBody, 200
+	return jsonBody, 200, stale
+}
+
+// isRanking reports whether the request has a ranking parameter
+func isRanking(query url.Values) bool {
+	for _, param := range []string{"top", "metric", "by", "continent"} {
+		if _, exist := query[param]; exist {
+			return true
+		}
+	}
+	return false
+}
+
+func badRequest(err error) ([]byte, int, bool) {
+	applogger.Log("ERROR", "hotspot", "perform", err.Error())
+	statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, err)
+	return statsErrJSONBody, 400, false
 }
@@ -10,30 +10,35 @@ import (
 	"github.com/gorilla/mux"
 )
 
+type TimelinePointExpectedResponse struct {
+	Date  string `json:"date"`
+	Value int    `json:"value"`
+}
+
 type HotspotExpectedResponse struct {
 	MostCases struct {
-		Country string `json:"country"`
-		Data    []int  `json:"data"`
+		Country string                          `json:"country"`
+		Data    []TimelinePointExpectedResponse `json:"data"`
 	} `json:"mostCases"`
 	SecondCases struct {
-		Country string `json:"country"`
-		Data    []int  `json:"data"`
+		Country string                          `json:"country"`
+		Data    []TimelinePointExpectedResponse `json:"data"`
 	} `json:"secondCases"`
 	ThirdCases struct {
-		Country string `json:"country"`
-		Data    []int  `json:"data"`
+		Country string                          `json:"country"`
+		Data    []TimelinePointExpectedResponse `json:"data"`
 	} `json:"thirdCases"`
 	MostDeaths struct {
-		Country string `json:"country"`
-		Data    []int  `json:"data"`
+		Country string                          `json:"country"`
+		Data    []TimelinePointExpectedResponse `json:"data"`
 	} `json:"mostDeaths"`
 	SecondDeaths struct {
-		Country string `json:"country"`
-		Data    []int  `json:"data"`
+		Country string                          `json:"country"`
+		Data    []TimelinePointExpectedResponse `json:"data"`
 	} `json:"secondDeaths"`
 	ThirdDeaths struct {
-		Country string `json:"country"`
-		Data    []int  `json:"data"`
+		Country string                          `json:"country"`
+		Data    []TimelinePointExpectedResponse `json:"data"`
 	} `json:"thirdDeaths"`
 }
 
@@ -140,10 +145,112 @@ func Test_APIHotspotHandle(t *testing.T) {
 	}
 }
 
-func calculateTotalAmmount(arr []int) int {
+func calculateTotalAmmount(arr []TimelinePointExpectedResponse) int {
 	total := 0
 	for _, v := range arr {
-		total = total + v
+		total = total + v.Value
 	}
 	return total
 }
+
+func Test_APIHotspotSmoothingError(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/hotspot/3?smoothing=median:7", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req = mux.SetURLVars(req, map[string]string{
+		"days": "3",
+	})
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusBadRequest)
+	}
+
+	var eer ErrorExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &eer)
+
+	if !strings.Contains(eer.Message, "median:7") {
+		t.Errorf("Wrong message value")
+	}
+}
+
+type RankingExpectedResponse struct {
+	Metric    string `json:"metric"`
+	Countries []struct {
+		Rank    int    `json:"rank"`
+		Country string `json:"country"`
+	} `json:"countries"`
+}
+
+func Test_APIHotspotRanking(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/hotspot/7?top=5&metric=deathsPerDay&by=acceleration", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req = mux.SetURLVars(req, map[string]string{
+		"days": "7",
+	})
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var rer RankingExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &rer)
+
+	if rer.Metric != "deathsPerDay" || len(rer.Countries) != 5 {
+		t.Errorf("Wrong ranking %s", rr.Body.String())
+	}
+}
+
+func Test_APIHotspotRankingBadRequest(t *testing.T) {
+	for _, query := range []string{"top=0", "top=three", "metric=cases", "by=relative"} {
+		req, err := http.NewRequest("GET", "/api/hotspot/7?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = mux.SetURLVars(req, map[string]string{
+			"days": "7",
+		})
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(Handle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v",
+				query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func Test_APIHotspotInvalidWindow(t *testing.T) {
+	for _, days := range []string{"0", "-3", "100000"} {
+		req, err := http.NewRequest("GET", "/api/hotspot/"+days, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = mux.SetURLVars(req, map[string]string{
+			"days": days,
+		})
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(Handle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s days: handler returned wrong status code: got %v want %v",
+				days, status, http.StatusBadRequest)
+		}
+	}
+}
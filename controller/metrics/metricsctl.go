@@ -0,0 +1,140 @@
+package metricsctl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	analytics "github.com/junkd0g/covid/lib/analytics"
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	curve "github.com/junkd0g/covid/lib/curve"
+	cworld "github.com/junkd0g/covid/lib/cworld"
+	mmetrics "github.com/junkd0g/covid/lib/model/metrics"
+	registry "github.com/junkd0g/covid/lib/registry"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+	merror "github.com/junkd0g/neji"
+)
+
+/*
+	Get request to /api/metrics/{country}, the growth metrics of the cases
+	and the deaths of a country, /api/metrics/world for the world.
+	growthRate is the daily growth rate over the last week, doublingTime the
+	days to double at that rate, weekOverWeek the change of the new values
+	of the last week from the week before (0.25 is 25% more) and rt the
+	estimate of the effective reproduction number from the new cases
+
+	/api/metrics/Greece
+
+	Response:
+
+{
+    "country": "Greece",
+    "cases": {
+        "growthRate": [
+            { "date": "2020-06-12", "value": 0.0021 }
+        ],
+        "doublingTime": [
+            { "date": "2020-06-12", "value": 330.4 }
+        ],
+        "weekOverWeek": [
+            { "date": "2020-06-12", "value": 0.35 }
+        ],
+        "rt": [
+            { "date": "2020-06-12", "value": 1.12 }
+        ],
+        "latest": {
+            "date": "2020-06-12",
+            "growthRate": 0.0021,
+            "doublingTime": 330.4,
+            "weekOverWeek": 0.35,
+            "rt": 1.12
+        }
+    },
+    "deaths": {
+        "growthRate": [
+            { "date": "2020-06-12", "value": 0.0008 }
+        ],
+        "doublingTime": [
+            { "date": "2020-06-12", "value": 866.2 }
+        ],
+        "weekOverWeek": [],
+        "latest": {
+            "date": "2020-06-12",
+            "growthRate": 0.0008,
+            "doublingTime": 866.2,
+            "weekOverWeek": null
+        }
+    }
+}
+*/
+func Handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	country := mux.Vars(r)["country"]
+	jsonBody, status, stale := perform(country)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "metricsctl", "Handle",
+		"Endpoint /api/metrics called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//Perform used in the /api/metrics endpoint's handle to return the growth
+//metrics of a country or of the world. It responds with 400 when there
+//is no country and 404 when there are no curves for the country
+//	@param country string name of the country or world
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func perform(country string) ([]byte, int, bool) {
+	if strings.TrimSpace(country) == "" {
+		errCountry := errors.New("country is required")
+		applogger.Log("ERROR", "metricsctl", "perform", errCountry.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errCountry)
+		return errorJSONBody, 400, false
+	}
+
+	var metrics mmetrics.GrowthMetrics
+	var err error
+	if cworld.IsWorld(country) {
+		metrics, err = analytics.WorldGrowthMetrics()
+	} else {
+		metrics, err = analytics.CountryGrowthMetrics(country)
+	}
+	if err != nil {
+		applogger.Log("ERROR", "metricsctl", "perform", err.Error())
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(err)
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return errorJSONBody, status, false
+	}
+
+	_, metrics.Stale = staleSince(country)
+	jsonBody, jsonBodyErr := json.Marshal(metrics)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "metricsctl", "perform", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, metrics.Stale
+}
+
+// staleSince returns since when the data the metrics of country are
+// computed from are served from their last known good copy
+func staleSince(country string) (time.Time, bool) {
+	if cworld.IsWorld(country) {
+		return cworld.Stale()
+	}
+	return curve.Stale()
+}
@@ -0,0 +1,61 @@
+package metricsctl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type MetricsExpectedResponse struct {
+	Country string `json:"country"`
+}
+
+func serve(t *testing.T, url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/metrics/{country}", Handle).Methods("GET")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_APIMetrics(t *testing.T) {
+	for url, country := range map[string]string{"/api/metrics/Greece": "Greece", "/api/metrics/world": "World"} {
+		rr := serve(t, url)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, http.StatusOK)
+		}
+
+		var mer MetricsExpectedResponse
+		json.Unmarshal([]byte(rr.Body.String()), &mer)
+
+		if mer.Country != country {
+			t.Errorf("Country field seems to be broken has value %s but expected value is %s", mer.Country, country)
+		}
+	}
+}
+
+func Test_APIMetricsNotFound(t *testing.T) {
+	rr := serve(t, "/api/metrics/Atlantis")
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func Test_APIMetricsBadRequest(t *testing.T) {
+	if _, status, _ := perform(" "); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusBadRequest)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	mnews "github.com/junkd0g/covid/lib/model/news"
 	news "github.com/junkd0g/covid/lib/news"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
@@ -89,7 +91,10 @@ func NewsAllHandle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform()
+	jsonBody, status, stale := perform()
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -97,27 +102,30 @@ func NewsAllHandle(w http.ResponseWriter, r *http.Request) {
 		"Endpoint /api/news/all called with response JSON body "+string(jsonBody), status, elapsed)
 }
 
-func perform() ([]byte, int) {
+func perform() ([]byte, int, bool) {
 
 	generalNews, err := news.GetNews()
 	if err != nil {
 		applogger.Log("ERROR", "crnews", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
 	newsTreatment, errNewsTreatment := news.GetTreatmentNews()
 	if errNewsTreatment != nil {
 		applogger.Log("ERROR", "crnews", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, errNewsTreatment)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(errNewsTreatment)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, errNewsTreatment)
+		return statsErrJSONBody, status, false
 	}
 
 	newsVaccine, errNewsVaccine := news.GetVaccineNews()
 	if errNewsVaccine != nil {
 		applogger.Log("ERROR", "crnews", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, errNewsVaccine)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(errNewsVaccine)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, errNewsVaccine)
+		return statsErrJSONBody, status, false
 	}
 
 	var allArticlesData mnews.AllArticlesData
@@ -125,12 +133,13 @@ func perform() ([]byte, int) {
 	allArticlesData.TreatmentArticles = newsTreatment
 	allArticlesData.VaccineArticles = newsVaccine
 
+	_, allArticlesData.Stale = news.Stale()
 	jsonBody, jsonBodyErr := json.Marshal(allArticlesData)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "crnews", "perform", err.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
 
-	return jsonBody, 200
+	return jsonBody, 200, allArticlesData.Stale
 }
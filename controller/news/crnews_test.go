@@ -5,6 +5,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	caching "github.com/junkd0g/covid/lib/caching"
+	mnews "github.com/junkd0g/covid/lib/model/news"
+	news "github.com/junkd0g/covid/lib/news"
 )
 
 type AllNewsExpectedResponses struct {
@@ -78,3 +82,99 @@ func Test_APINewsAll(t *testing.T) {
 	}
 
 }
+
+type PageNewsExpectedResponses struct {
+	Data []struct {
+		Title       string `json:"title"`
+		PublishedAt string `json:"publishedAt"`
+	} `json:"data"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+func Test_APINews(t *testing.T) {
+	handles := map[string]http.HandlerFunc{
+		"/api/news":           NewsHandle,
+		"/api/news/vaccine":   VaccineNewsHandle,
+		"/api/news/treatment": TreatmentNewsHandle,
+	}
+
+	for endpoint, handle := range handles {
+		req, err := http.NewRequest("GET", endpoint+"?limit=2&offset=1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handle.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v",
+				endpoint, status, http.StatusOK)
+		}
+
+		var pner PageNewsExpectedResponses
+		json.Unmarshal([]byte(rr.Body.String()), &pner)
+
+		if len(pner.Data) > 2 || pner.Limit != 2 || pner.Offset != 1 {
+			t.Errorf("%s returned a wrong page %v", endpoint, pner)
+		}
+	}
+}
+
+func Test_APINewsBadParams(t *testing.T) {
+	for _, query := range []string{"?limit=-1", "?offset=one", "?since=08/06/2020"} {
+		req, err := http.NewRequest("GET", "/api/news"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(NewsHandle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("/api/news%s returned wrong status code: got %v want %v",
+				query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func Test_APINewsStaleType(t *testing.T) {
+	store := caching.NewMemoryStore()
+	news.SetCache(store)
+	defer news.SetCache(caching.NewMemoryStore())
+	store.MarkStale(news.VaccineNews)
+
+	getNews := func() (mnews.ArticlesData, error) {
+		return mnews.ArticlesData{Articles: []mnews.Article{{Title: "Vaccine trial"}}}, nil
+	}
+
+	for newsType, stale := range map[string]bool{news.VaccineNews: true, news.TreatmentNews: false} {
+		req, err := http.NewRequest("GET", "/api/news/"+newsType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handleType(rr, req, "/api/news/"+newsType, "Test_APINewsStaleType", newsType, getNews)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("%s news returned wrong status code: got %v want %v", newsType, status, http.StatusOK)
+		}
+
+		if header := rr.Header().Get(caching.StaleHeader) == "true"; header != stale {
+			t.Errorf("%s news returned wrong stale header: got %v want %v", newsType, header, stale)
+		}
+
+		var page mnews.ArticlesPage
+		if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+
+		if page.Stale != stale {
+			t.Errorf("%s news returned wrong stale body: got %v want %v", newsType, page.Stale, stale)
+		}
+	}
+}
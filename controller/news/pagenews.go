@@ -0,0 +1,153 @@
+package crnews
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mnews "github.com/junkd0g/covid/lib/model/news"
+	news "github.com/junkd0g/covid/lib/news"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+	merror "github.com/junkd0g/neji"
+)
+
+/*
+	Get request to /api/news with optional parameters
+		limit  maximum number of articles, all of them when it is missing
+		offset number of articles to skip
+		since  only articles published at or after since, YYYY-MM-DD or RFC 3339
+
+	/api/news?limit=1&offset=1&since=2020-06-08
+
+	Response:
+
+{
+    "data": [
+        {
+            "title": "How Reskilling Can Soften the Economic Blow of Covid-19 - Harvard Business Review",
+            "description": "<a href=\"https://hbr.org/2020/06/how-reskilling-can-soften-the-economic-blow-of-covid-19\" target=\"_blank\">How Reskilling Can Soften the Economic Blow of Covid-19</a>&nbsp;&nbsp;<font color=\"#6f6f6f\">Harvard Business Review</font>",
+            "url": "https://hbr.org/2020/06/how-reskilling-can-soften-the-economic-blow-of-covid-19",
+            "urlToImage": "",
+            "publishedAt": "Mon, 08 Jun 2020 12:10:46 GMT",
+            "source": "Harvard Business Review",
+            "sourceURL": "https://hbr.org"
+        }
+    ],
+    "total": 2,
+    "limit": 1,
+    "offset": 1
+}
+*/
+func NewsHandle(w http.ResponseWriter, r *http.Request) {
+	handleType(w, r, "/api/news", "NewsHandle", news.GeneralNews, news.GetNews)
+}
+
+/*
+	Get request to /api/news/vaccine with the same optional parameters
+	and response as /api/news
+*/
+func VaccineNewsHandle(w http.ResponseWriter, r *http.Request) {
+	handleType(w, r, "/api/news/vaccine", "VaccineNewsHandle", news.VaccineNews, news.GetVaccineNews)
+}
+
+/*
+	Get request to /api/news/treatment with the same optional parameters
+	and response as /api/news
+*/
+func TreatmentNewsHandle(w http.ResponseWriter, r *http.Request) {
+	handleType(w, r, "/api/news/treatment", "TreatmentNewsHandle", news.TreatmentNews, news.GetTreatmentNews)
+}
+
+func handleType(w http.ResponseWriter, r *http.Request, endpoint string, function string,
+	newsType string, getNews func() (mnews.ArticlesData, error)) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status, stale := performType(r.URL.Query(), newsType, getNews)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "crnews", function,
+		"Endpoint "+endpoint+" called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//PerformType used in the handles of a news type's endpoint to return a
+//page of its articles
+//	@param query url.Values the limit, offset and since parameters
+//	@param newsType string the news type whose staleness is reported
+//	@param getNews func returning the articles of the news type
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the articles are served from their last known good copy
+func performType(query url.Values, newsType string, getNews func() (mnews.ArticlesData, error)) ([]byte, int, bool) {
+	limit, offset, since, errParams := pageParams(query)
+	if errParams != nil {
+		applogger.Log("ERROR", "crnews", "performType", errParams.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errParams)
+		return errorJSONBody, 400, false
+	}
+
+	articles, err := getNews()
+	if err != nil {
+		applogger.Log("ERROR", "crnews", "performType", err.Error())
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
+	}
+
+	page := news.Page(articles, since, limit, offset)
+	_, page.Stale = news.StaleType(newsType)
+	jsonBody, jsonBodyErr := json.Marshal(page)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "crnews", "performType", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, page.Stale
+}
+
+//pageParams parses the limit and offset, which must not be negative,
+//and since, as a date or an RFC 3339 time, of a news request
+func pageParams(query url.Values) (int, int, time.Time, error) {
+	var limit, offset int
+	var since time.Time
+
+	if v := query.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 0 {
+			return 0, 0, since, errors.New("limit must be a non negative integer")
+		}
+		limit = l
+	}
+
+	if v := query.Get("offset"); v != "" {
+		o, err := strconv.Atoi(v)
+		if err != nil || o < 0 {
+			return 0, 0, since, errors.New("offset must be a non negative integer")
+		}
+		offset = o
+	}
+
+	if v := query.Get("since"); v != "" {
+		s, err := time.Parse(mcountry.DateLayout, v)
+		if err != nil {
+			s, err = time.Parse(time.RFC3339, v)
+		}
+		if err != nil {
+			return 0, 0, since, errors.New("since must be a date (YYYY-MM-DD) or an RFC 3339 time")
+		}
+		since = s
+	}
+
+	return limit, offset, since, nil
+}
@@ -0,0 +1,186 @@
+package snapshotctl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	msnapshot "github.com/junkd0g/covid/lib/model/snapshot"
+	registry "github.com/junkd0g/covid/lib/registry"
+	snapshot "github.com/junkd0g/covid/lib/snapshot"
+	merror "github.com/junkd0g/neji"
+)
+
+/*
+	Get request to /api/snapshot/{country}/{date} with date as YYYY-MM-DD
+
+	Response:
+
+{
+    "date": "2020-06-07",
+    "takenAt": "2020-06-07T23:32:58.51Z",
+    "data": {
+        "country": "Greece",
+        "cases": 3049,
+        "todayCases": 9,
+        "deaths": 183,
+        "todayDeaths": 0,
+        "recovered": 1374,
+        "active": 1492,
+        "critical": 10,
+        "casesPerOneMillion": 293,
+        "tests": 244588,
+        "testsPerOneMillion": 23473
+    }
+}
+*/
+func Handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	jsonBody, status := perform(vars["country"], vars["date"])
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "snapshotctl", "Handle",
+		"Endpoint /api/snapshot/{country}/{date} called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+/*
+	Get request to /api/snapshot/{country}/{date}/diff with date as YYYY-MM-DD
+
+	Response:
+
+{
+    "country": "Greece",
+    "date": "2020-06-07",
+    "previousDate": "2020-06-06",
+    "diff": {
+        "cases": 16,
+        "todayCases": 7,
+        "deaths": 1,
+        "todayDeaths": 1,
+        "recovered": 0,
+        "active": 15,
+        "critical": -1,
+        "casesPerOneMillion": 2,
+        "tests": 3124,
+        "testsPerOneMillion": 300
+    }
+}
+*/
+func DiffHandle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	jsonBody, status := performDiff(vars["country"], vars["date"])
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "snapshotctl", "DiffHandle",
+		"Endpoint /api/snapshot/{country}/{date}/diff called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+/*
+	Get request to /api/snapshot/dates with no parameters
+
+	Response:
+
+{
+    "dates": [
+        "2020-06-05",
+        "2020-06-06",
+        "2020-06-07"
+    ]
+}
+*/
+func DatesHandle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status := performDates()
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "snapshotctl", "DatesHandle",
+		"Endpoint /api/snapshot/dates called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//Perform used in the /api/snapshot/{country}/{date} endpoint's handle to return
+//the stats a country had on a date
+//	@param country string name of the country
+//	@param date string date as YYYY-MM-DD
+//	@return array of bytes of the json object
+//	@return int http code status
+func perform(country string, date string) ([]byte, int) {
+	data, err := snapshot.Default.Country(country, date)
+	if err != nil {
+		return errorResponse("perform", err)
+	}
+
+	return response("perform", data)
+}
+
+//PerformDiff used in the /api/snapshot/{country}/{date}/diff endpoint's handle
+//to return how the stats of a country changed from the day before date
+//	@param country string name of the country
+//	@param date string date as YYYY-MM-DD
+//	@return array of bytes of the json object
+//	@return int http code status
+func performDiff(country string, date string) ([]byte, int) {
+	data, err := snapshot.Default.Diff(country, date)
+	if err != nil {
+		return errorResponse("performDiff", err)
+	}
+
+	return response("performDiff", data)
+}
+
+//PerformDates used in the /api/snapshot/dates endpoint's handle to return
+//the dates that have snapshots
+//	@return array of bytes of the json object
+//	@return int http code status
+func performDates() ([]byte, int) {
+	dates, err := snapshot.Default.Dates()
+	if err != nil {
+		return errorResponse("performDates", err)
+	}
+
+	return response("performDates", msnapshot.Dates{Dates: dates})
+}
+
+//errorResponse returns 400 for an invalid date, 404 when there is no
+//snapshot or the country is not in it and 500 for any other error
+func errorResponse(function string, err error) ([]byte, int) {
+	applogger.Log("ERROR", "snapshotctl", function, err.Error())
+	if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+		return notFoundJSONBody, 404
+	}
+
+	status := 500
+	switch {
+	case errors.Is(err, snapshot.ErrInvalidDate):
+		status = 400
+	case errors.Is(err, snapshot.ErrNotFound):
+		status = 404
+	}
+
+	errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+	return errorJSONBody, status
+}
+
+func response(function string, data interface{}) ([]byte, int) {
+	jsonBody, jsonBodyErr := json.Marshal(data)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "snapshotctl", function, jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500
+	}
+
+	return jsonBody, 200
+}
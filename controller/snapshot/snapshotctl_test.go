@@ -0,0 +1,81 @@
+package snapshotctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	mregistry "github.com/junkd0g/covid/lib/model/registry"
+	registry "github.com/junkd0g/covid/lib/registry"
+)
+
+type ExpectedStructureResponse struct {
+	Dates []string `json:"dates"`
+}
+
+func serve(t *testing.T, url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/snapshot/dates", DatesHandle)
+	router.HandleFunc("/api/snapshot/{country}/{date}", Handle)
+	router.HandleFunc("/api/snapshot/{country}/{date}/diff", DiffHandle)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_APISnapshotDates(t *testing.T) {
+	rr := serve(t, "/api/snapshot/dates")
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var esr ExpectedStructureResponse
+	json.Unmarshal([]byte(rr.Body.String()), &esr)
+
+	if esr.Dates == nil {
+		t.Errorf("Expecting a list of dates")
+	}
+}
+
+func Test_APISnapshotErrors(t *testing.T) {
+	tests := []struct {
+		url    string
+		status int
+	}{
+		{"/api/snapshot/Greece/06-06-2020", http.StatusBadRequest},
+		{"/api/snapshot/Greece/06-06-2020/diff", http.StatusBadRequest},
+		{"/api/snapshot/Greece/2020-06-06", http.StatusNotFound},
+		{"/api/snapshot/Greece/2020-06-06/diff", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		if status := serve(t, test.url).Code; status != test.status {
+			t.Errorf("%s returned wrong status code: got %v want %v",
+				test.url, status, test.status)
+		}
+	}
+}
+
+func Test_APISnapshotCountryNotFound(t *testing.T) {
+	err := fmt.Errorf("%w on 2020-06-06", &registry.NotFoundError{Name: "Grece", Suggestions: []string{"Greece"}})
+	body, status := errorResponse("Test", err)
+	if status != http.StatusNotFound {
+		t.Errorf("errorResponse returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+
+	var response mregistry.NotFound
+	json.Unmarshal(body, &response)
+	if response.Country != "Grece" || len(response.Suggestions) != 1 || response.Suggestions[0] != "Greece" {
+		t.Errorf("Wrong not found response %s", body)
+	}
+}
@@ -2,19 +2,37 @@ package sortcon
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
-	mcountry "github.com/junkd0g/covid/lib/model/country"
+	caching "github.com/junkd0g/covid/lib/caching"
 	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
-//SortRequest used for the https request's body
+//SortRequest used for the https request's body, Type is a single field
+//sorted in descending order kept for the older clients
 type SortRequest struct {
-	Type string `json:"type"`
+	Type  string      `json:"type"`
+	Sort  []SortField `json:"sort"`
+	Limit int         `json:"limit"`
+}
+
+//SortField is a sort key of the request, Order is asc or desc (default)
+type SortField struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+//typeKeys are the sort keys of the types that break ties by another field
+var typeKeys = map[string][]stats.SortKey{
+	"deaths": {{Field: "deaths"}, {Field: "cases"}},
+	"cases":  {{Field: "cases"}, {Field: "deaths"}},
 }
 
 /*
@@ -26,6 +44,19 @@ type SortRequest struct {
 		"type" : "deaths"
 	}
 
+	or sorting by a list of keys, any field of a country or one of the
+	ratios deathRate, recoveryRate, criticalRate, positivityRate and
+	todayCasesRate, in asc or desc (default) order keeping the first limit
+	countries
+
+	{
+		"sort" : [
+			{ "field" : "deathRate", "order" : "desc" },
+			{ "field" : "country", "order" : "asc" }
+		],
+		"limit" : 2
+	}
+
 	Response
 
 	{
@@ -62,7 +93,10 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform(r)
+	jsonBody, status, stale := perform(r)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -71,17 +105,15 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 }
 
 //Perform used in the /sort endpoint's handle to return
-//	the structs.Countries struct as a json response by calling
-//	stats.SortByDeaths() or tats.GetAllCountries() or stats.GetAllCountries()
-//  or stats.SortByCasesPerOneMillion() or stats.SortByCritical() or
-//  stats.SortByActive() or stats.SortByRecovered() or stats.SortByTodayDeaths()
-//  or stats.SortByTodayCases() or stats.SortByCases()
-//  which get and return sorted by field data: array
+//	the mcountry.Countries struct as a json response by calling
+//	stats.SortCountries with the sort keys of the request, a field
+//	unknown to stats.Sort or an order other than asc and desc is a 400
 //
-//	CompareRequest used as the struct for the request
+//	SortRequest used as the struct for the request
 //		example:
 //			{
-//				"type" : "deaths"
+//				"sort" : [{ "field" : "deaths", "order" : "desc" }],
+//				"limit" : 10
 //			}
 //
 //	In this JSON format
@@ -96,17 +128,6 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //			"active": 88274,
 //			"critical": 3994,
 //			"casesPerOneMillion": 2061
-//		},
-//		{
-//			"country": "Spain",
-//			"cases": 124736,
-//			"todayCases": 5537,
-//			"deaths": 11744,
-//			"todayDeaths": 546,
-//			"recovered": 34219,
-//			"active": 78773,
-//			"critical": 6416,
-//			"casesPerOneMillion": 2668
 //		}]
 //	}
 //
@@ -115,99 +136,81 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 //
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform(r *http.Request) ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform(r *http.Request) ([]byte, int, bool) {
 	var sortRequest SortRequest
 
 	b, errIoutilReadAll := ioutil.ReadAll(r.Body)
 	if errIoutilReadAll != nil {
 		applogger.Log("ERROR", "sortcon", "perform", errIoutilReadAll.Error())
 		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errIoutilReadAll)
-		return statsErrJSONBody, 400
+		return statsErrJSONBody, 400, false
 	}
 
 	unmarshallError := json.Unmarshal(b, &sortRequest)
 	if unmarshallError != nil {
 		applogger.Log("ERROR", "sortcon", "perform", unmarshallError.Error())
 		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, unmarshallError)
-		return statsErrJSONBody, 400
+		return statsErrJSONBody, 400, false
 	}
 
-	sortType := sortRequest.Type
-	var countries mcountry.Countries
-	var countriesError error
-
-	switch sortType {
-	case "deaths":
-		countries, countriesError = stats.SortByDeaths()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Deaths sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "cases":
-		countries, countriesError = stats.SortByCases()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Cases sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "todayCases":
-		countries, countriesError = stats.SortByTodayCases()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Today cases sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "todayDeaths":
-		countries, countriesError = stats.SortByTodayDeaths()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Today deaths sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "recovered":
-		countries, countriesError = stats.SortByRecovered()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Recovered sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "active":
-		countries, countriesError = stats.SortByActive()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Active sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "critical":
-		countries, countriesError = stats.SortByCritical()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Critical sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	case "casesPerOneMillion":
-		countries, countriesError = stats.SortByCasesPerOneMillion()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Cases per one million sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
-		}
-	default:
-		countries, countriesError = stats.GetAllCountries()
-		if countriesError != nil {
-			applogger.Log("ERROR", "sortcon", "perform", "Default sorting error: "+countriesError.Error())
-			statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, countriesError)
-			return statsErrJSONBody, 500
+	keys, keysError := sortKeys(sortRequest)
+	if keysError != nil {
+		applogger.Log("ERROR", "sortcon", "perform", keysError.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, keysError)
+		return statsErrJSONBody, 400, false
+	}
+
+	countries, countriesError := stats.SortCountries(keys, sortRequest.Limit)
+	if countriesError != nil {
+		applogger.Log("ERROR", "sortcon", "perform", "Sorting error: "+countriesError.Error())
+		status := upstream.HTTPStatus(countriesError)
+		if errors.Is(countriesError, stats.ErrUnknownField) {
+			status = 400
 		}
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, countriesError)
+		return statsErrJSONBody, status, false
 	}
 
+	_, countries.Stale = stats.Stale()
 	jsonBody, err := json.Marshal(countries)
 	if err != nil {
 		applogger.Log("ERROR", "sortcon", "perform", err.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
+	}
+
+	return jsonBody, 200, countries.Stale
+}
+
+//sortKeys returns the stats.SortKey of a request, the keys of its Type
+//when it has no Sort and no keys when it has neither
+func sortKeys(sortRequest SortRequest) ([]stats.SortKey, error) {
+	if sortRequest.Limit < 0 {
+		return nil, errors.New("limit must not be negative")
+	}
+
+	if len(sortRequest.Sort) == 0 {
+		if sortRequest.Type == "" {
+			return nil, nil
+		}
+		if keys, exist := typeKeys[sortRequest.Type]; exist {
+			return keys, nil
+		}
+		return []stats.SortKey{{Field: sortRequest.Type}}, nil
+	}
+
+	keys := make([]stats.SortKey, 0, len(sortRequest.Sort))
+	for _, v := range sortRequest.Sort {
+		switch v.Order {
+		case "", "desc":
+			keys = append(keys, stats.SortKey{Field: v.Field})
+		case "asc":
+			keys = append(keys, stats.SortKey{Field: v.Field, Ascending: true})
+		default:
+			return nil, fmt.Errorf("unknown order %q of field %q, expecting asc or desc", v.Order, v.Field)
+		}
 	}
 
-	return jsonBody, 200
+	return keys, nil
 }
@@ -255,3 +255,55 @@ func Test_APISortByTodayCasesPerOneMillion(t *testing.T) {
 		t.Errorf("Critical seems not to be sorted when type is casesPerOneMillion")
 	}
 }
+
+func Test_APISortByKeys(t *testing.T) {
+	var jsonStr = []byte(`{"sort" : [{"field" : "testsPerOneMillion", "order" : "asc"}, {"field" : "country"}], "limit" : 5}`)
+
+	req, err := http.NewRequest("POST", "/api/sort", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var ser SortExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &ser)
+
+	if len(ser.Data) != 5 {
+		t.Fatalf("Expecting 5 countries having %d", len(ser.Data))
+	}
+
+	if ser.Data[0].TestsPerOneMillion > ser.Data[1].TestsPerOneMillion {
+		t.Errorf("TestsPerOneMillion seems not to be sorted in ascending order")
+	}
+}
+
+func Test_APISortBadRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"type" : "vaccinated"}`,
+		`{"sort" : [{"field" : "cases"}, {"field" : "vaccinated"}]}`,
+		`{"sort" : [{"field" : "cases", "order" : "up"}]}`,
+		`{"sort" : [{"field" : "cases"}], "limit" : -1}`,
+	} {
+		req, err := http.NewRequest("POST", "/api/sort", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(Handle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v",
+				body, status, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package statsctl
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
+	registry "github.com/junkd0g/covid/lib/registry"
+	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+	merror "github.com/junkd0g/neji"
+)
+
+//StatsRequest used for the https request's body
+type StatsRequest struct {
+	Name string `json:"country"`
+}
+
+/*
+	POST request to /api/stats
+	Request:
+
+	{
+		"country" : "Greece"
+	}
+
+	Response
+
+	{
+		"country": "Greece",
+		"todayPerCentOfTotalCases": 0,
+		"todayPerCentOfTotalDeaths": 2
+	}
+
+*/
+func Handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status, stale := perform(r)
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "statsctl", "Handle",
+		"Endpoint /api/stats called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//Perform used in the /stats endpoint's handle to return today's cases and
+//deaths of a country as a percentance of its total cases and deaths by
+//calling stats.PercentancePerCountry. It responds with 400 when the
+//request has no country and 404 when there are no stats for the country
+//	@param r *http.Request used to get http request's body
+//	@return array of bytes of the json object
+//	@return int http code status
+//	@return bool whether the data are served from their last known good copy
+func perform(r *http.Request) ([]byte, int, bool) {
+	var statsRequest StatsRequest
+
+	b, errIoutilReadAll := ioutil.ReadAll(r.Body)
+	if errIoutilReadAll != nil {
+		applogger.Log("ERROR", "statsctl", "perform", errIoutilReadAll.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, errIoutilReadAll)
+		return statsErrJSONBody, 500, false
+	}
+
+	if errUnmarshal := json.Unmarshal(b, &statsRequest); errUnmarshal != nil {
+		applogger.Log("ERROR", "statsctl", "perform", errUnmarshal.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errUnmarshal)
+		return statsErrJSONBody, 400, false
+	}
+
+	if strings.TrimSpace(statsRequest.Name) == "" {
+		errCountry := errors.New("country is required")
+		applogger.Log("ERROR", "statsctl", "perform", errCountry.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errCountry)
+		return statsErrJSONBody, 400, false
+	}
+
+	countryStats, err := stats.PercentancePerCountry(statsRequest.Name)
+	if err != nil {
+		applogger.Log("ERROR", "statsctl", "perform", err.Error())
+		if notFoundJSONBody, notFound := registry.NotFoundResponse(err); notFound {
+			return notFoundJSONBody, 404, false
+		}
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
+	}
+
+	jsonBody, jsonBodyErr := json.Marshal(countryStats)
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "statsctl", "perform", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500, false
+	}
+
+	_, stale := stats.Stale()
+	return jsonBody, 200, stale
+}
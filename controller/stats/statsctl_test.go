@@ -0,0 +1,58 @@
+package statsctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type StatsExpectedResponse struct {
+	Country                   string `json:"country"`
+	TodayPerCentOfTotalCases  int    `json:"todayPerCentOfTotalCases"`
+	TodayPerCentOfTotalDeaths int    `json:"todayPerCentOfTotalDeaths"`
+}
+
+func Test_APIStats(t *testing.T) {
+	var jsonStr = []byte(`{"country" : "Greece"}`)
+
+	req, err := http.NewRequest("POST", "/api/stats", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var ser StatsExpectedResponse
+	json.Unmarshal([]byte(rr.Body.String()), &ser)
+
+	if ser.Country != "Greece" {
+		t.Errorf("Country field seems to be broken has value %s but expected value is %s", ser.Country, "Greece")
+	}
+}
+
+func Test_APIStatsBadRequest(t *testing.T) {
+	for _, body := range []string{``, `{"country" : ""}`, `{"country" : 1}`} {
+		req, err := http.NewRequest("POST", "/api/stats", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(Handle)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code for %q: got %v want %v",
+				body, status, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package statusctl
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	scheduler "github.com/junkd0g/covid/lib/scheduler"
+	merror "github.com/junkd0g/neji"
+)
+
+/*
+	Get request to /api/status with no parameters
+
+	Response:
+
+{
+    "running": true,
+    "jobs": [
+        {
+            "name": "countries",
+            "interval": 1800,
+            "runs": 3,
+            "consecutiveFailures": 0,
+            "lastSuccess": "2020-06-07T16:32:58.51Z",
+            "nextRun": "2020-06-07T17:04:12.04Z"
+        },
+        {
+            "name": "curve",
+            "interval": 1800,
+            "runs": 4,
+            "consecutiveFailures": 1,
+            "lastSuccess": "2020-06-07T16:02:58.01Z",
+            "lastFailure": "2020-06-07T16:33:01.92Z",
+            "lastError": "Get https://corona.lmao.ninja/v2/historical/?lastdays=all: EOF",
+            "nextRun": "2020-06-07T16:33:29.37Z"
+        }
+    ]
+}
+*/
+func Handle(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	jsonBody, status := perform()
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+	elapsed := time.Since(start).Seconds()
+	applogger.LogHTTP("INFO", "statusctl", "Handle",
+		"Endpoint /api/status called with response JSON body "+string(jsonBody), status, elapsed)
+}
+
+//Perform used in the /status endpoint's handle to return the last
+//success and failure of every dataset's background refresh
+//	@return array of bytes of the json object
+//	@return int http code status
+func perform() ([]byte, int) {
+	jsonBody, jsonBodyErr := json.Marshal(scheduler.Default.Status())
+	if jsonBodyErr != nil {
+		applogger.Log("ERROR", "statusctl", "perform", jsonBodyErr.Error())
+		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
+		return errorJSONBody, 500
+	}
+
+	return jsonBody, 200
+}
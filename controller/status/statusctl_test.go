@@ -0,0 +1,43 @@
+package statusctl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ExpectedStructureResponse struct {
+	Running bool `json:"running"`
+	Jobs    []struct {
+		Name     string  `json:"name"`
+		Interval float64 `json:"interval"`
+	} `json:"jobs"`
+}
+
+func Test_APIStatus(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var esr ExpectedStructureResponse
+	json.Unmarshal([]byte(rr.Body.String()), &esr)
+
+	if esr.Running {
+		t.Errorf("Scheduler should not be running in tests")
+	}
+
+	if len(esr.Jobs) != 8 {
+		t.Errorf("Expecting 8 jobs having %d", len(esr.Jobs))
+	}
+}
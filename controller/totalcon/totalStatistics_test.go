@@ -2,9 +2,16 @@ package totalcon
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	caching "github.com/junkd0g/covid/lib/caching"
+	pconf "github.com/junkd0g/covid/lib/config"
+	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 )
 
 type TotalExpectedResponse struct {
@@ -58,3 +65,41 @@ func Test_APITotal(t *testing.T) {
 		t.Errorf("Today per cent of total cases deaths seems to be broken")
 	}
 }
+
+// failingUpstream responds to every request with a 500
+type failingUpstream struct{}
+
+func (failingUpstream) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("Internal Server Error")),
+		Request:    r,
+	}, nil
+}
+
+func Test_APITotalUpstreamDown(t *testing.T) {
+	client := upstream.New(pconf.UpstreamConfig{})
+	client.HTTPClient.Transport = failingUpstream{}
+	defaultClient := upstream.Default
+	upstream.Default = client
+	stats.SetCache(caching.NewMemoryStore())
+	defer func() {
+		upstream.Default = defaultClient
+		stats.SetCache(caching.NewMemoryStore())
+	}()
+
+	req, err := http.NewRequest("GET", "/api/total", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadGateway {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusBadGateway)
+	}
+}
@@ -6,71 +6,60 @@ import (
 	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
+	caching "github.com/junkd0g/covid/lib/caching"
 	cworld "github.com/junkd0g/covid/lib/cworld"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 	merror "github.com/junkd0g/neji"
 )
 
 /*
-	Get request to /api/world with no parameters
+	Get request to /api/world with the optional parameter smoothing
+	(trailing:N, centered:N, exponential:A, clip or a method and clip like
+	trailing:7,clip) to smooth the daily series
+
+	/api/world?smoothing=centered:7
 
 	Response:
 
 {
     "cases": [
-        555,
-        654,
-        941,
-        1434,
-
+        { "date": "2020-01-22", "value": 555 },
+        { "date": "2020-01-23", "value": 654 },
+        { "date": "2020-01-24", "value": 941 }
     ],
     "deaths": [
-        17,
-        18,
-        26,
-        42,
-        56,
-        82,
+        { "date": "2020-01-22", "value": 17 },
+        { "date": "2020-01-23", "value": 18 },
+        { "date": "2020-01-24", "value": 26 }
     ],
     "recovered": [
-        28,
-        30,
-        36,
-        39,
-        52,
+        { "date": "2020-01-22", "value": 28 },
+        { "date": "2020-01-23", "value": 30 },
+        { "date": "2020-01-24", "value": 36 }
     ],
     "casesDaily": [
-        99,
-        287,
-        493,
-        684,
-        809,
+        { "date": "2020-01-23", "value": 99 },
+        { "date": "2020-01-24", "value": 287 }
     ],
     "deathsDaily": [
-        1,
-        8,
-        16,
-        14,
-        26,
-        49,
+        { "date": "2020-01-23", "value": 1 },
+        { "date": "2020-01-24", "value": 8 }
     ],
     "recoveredDaily": [
-        2,
-        6,
-        3,
-        13,
-        9,
-        46,
-        19,
-        17,
+        { "date": "2020-01-23", "value": 2 },
+        { "date": "2020-01-24", "value": 6 }
     ]
 }
-}
 */
 func Handle(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
-	jsonBody, status := perform()
+	jsonBody, status, stale := perform(r.URL.Query().Get("smoothing"))
+	if stale {
+		w.Header().Set(caching.StaleHeader, "true")
+	}
 	w.WriteHeader(status)
 	w.Write(jsonBody)
 	elapsed := time.Since(start).Seconds()
@@ -78,23 +67,34 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		"Endpoint /api/world called with response JSON body "+string(jsonBody), status, elapsed)
 }
 
-//Perform used in the /compare endpoint's handle to return
+//Perform used in the /world endpoint's handle to return
+//	@param smoothingParam string smoothing of the daily series
 //	@return array of bytes of the json object
 //	@return int http code status
-func perform() ([]byte, int) {
+//	@return bool whether the data are served from their last known good copy
+func perform(smoothingParam string) ([]byte, int, bool) {
+	smoothingOptions, errSmoothing := smoothing.Parse(smoothingParam)
+	if errSmoothing != nil {
+		applogger.Log("ERROR", "worldct", "perform", errSmoothing.Error())
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(400, errSmoothing)
+		return statsErrJSONBody, 400, false
+	}
 
 	worldData, err := cworld.GetaWorldHistory()
 	if err != nil {
 		applogger.Log("ERROR", "worldct", "perform", err.Error())
-		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, err)
-		return statsErrJSONBody, 500
+		status := upstream.HTTPStatus(err)
+		statsErrJSONBody, _ := merror.SimpeErrorResponseWithStatus(status, err)
+		return statsErrJSONBody, status, false
 	}
 
+	worldData = cworld.Smooth(worldData, smoothingOptions)
+	_, worldData.Stale = cworld.Stale()
 	jsonBody, jsonBodyErr := json.Marshal(worldData)
 	if jsonBodyErr != nil {
 		applogger.Log("ERROR", "worldct", "perform", jsonBodyErr.Error())
 		errorJSONBody, _ := merror.SimpeErrorResponseWithStatus(500, jsonBodyErr)
-		return errorJSONBody, 500
+		return errorJSONBody, 500, false
 	}
-	return jsonBody, 200
+	return jsonBody, 200, worldData.Stale
 }
@@ -7,13 +7,18 @@ import (
 	"testing"
 )
 
+type TimelinePointExpectedResponse struct {
+	Date  string `json:"date"`
+	Value int    `json:"value"`
+}
+
 type ExpectedStructureResponse struct {
-	Cases          []int `json:"cases"`
-	Deaths         []int `json:"deaths"`
-	Recovered      []int `json:"recovered"`
-	CasesDaily     []int `json:"casesDaily"`
-	DeathsDaily    []int `json:"deathsDaily"`
-	RecoveredDaily []int `json:"recoveredDaily"`
+	Cases          []TimelinePointExpectedResponse `json:"cases"`
+	Deaths         []TimelinePointExpectedResponse `json:"deaths"`
+	Recovered      []TimelinePointExpectedResponse `json:"recovered"`
+	CasesDaily     []TimelinePointExpectedResponse `json:"casesDaily"`
+	DeathsDaily    []TimelinePointExpectedResponse `json:"deathsDaily"`
+	RecoveredDaily []TimelinePointExpectedResponse `json:"recoveredDaily"`
 }
 
 func Test_APIWorld(t *testing.T) {
@@ -57,3 +62,19 @@ func Test_APIWorld(t *testing.T) {
 		t.Errorf("RecoveredDaily array is empty")
 	}
 }
+
+func Test_APIWorldSmoothingError(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/world?smoothing=trailing:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(Handle)
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusBadRequest)
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"testing"
 
 	mcountry "github.com/junkd0g/covid/lib/model/country"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
 )
 
+func timelineMock(data map[string]float64) mcountry.Timeline {
+	timeline, _ := mcountry.NewTimeline(data)
+	return timeline
+}
+
 type countryDataAnalytics struct{}
 
 var countryDataAnalyticsMock func() ([]mcountry.CountryCurve, error)
@@ -20,7 +26,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 	countryDataAnalyticsMock = func() ([]mcountry.CountryCurve, error) {
 		var articleArr []mcountry.CountryCurve
 		xTimeline := mcountry.TimelineStruct{
-			Cases: map[string]interface{}{
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 15.0,
 				"1/23/20": 2.0,
 				"1/24/20": 3.0,
@@ -29,8 +35,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
-			Deaths: map[string]interface{}{
+			}),
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 25.0,
 				"1/24/20": 3.0,
@@ -39,8 +45,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 3.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 13.0,
 				"1/23/20": 23.0,
 				"1/24/20": 3.0,
@@ -49,7 +55,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
+			}),
 		}
 		x := mcountry.CountryCurve{
 			Country:  "xCountry",
@@ -58,7 +64,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 		}
 
 		yTimeline := mcountry.TimelineStruct{
-			Cases: map[string]interface{}{
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 200.0,
 				"1/24/20": 3.0,
@@ -67,8 +73,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
-			Deaths: map[string]interface{}{
+			}),
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 2.0,
 				"1/24/20": 30.0,
@@ -77,8 +83,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 10.0,
 				"1/23/20": 2.0,
 				"1/24/20": 3.0,
@@ -87,7 +93,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
+			}),
 		}
 		y := mcountry.CountryCurve{
 			Country:  "yCountry",
@@ -96,7 +102,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 		}
 
 		zTimeline := mcountry.TimelineStruct{
-			Cases: map[string]interface{}{
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 243.0,
 				"1/24/20": 3.0,
@@ -105,8 +111,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
-			Deaths: map[string]interface{}{
+			}),
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 2.0,
 				"1/24/20": 366.0,
@@ -115,8 +121,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 15.0,
 				"1/23/20": 2.0,
 				"1/24/20": 3.0,
@@ -125,7 +131,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
+			}),
 		}
 		z := mcountry.CountryCurve{
 			Country:  "zCountry",
@@ -134,7 +140,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 		}
 
 		wTimeline := mcountry.TimelineStruct{
-			Cases: map[string]interface{}{
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 2.0,
 				"1/24/20": 34.0,
@@ -143,8 +149,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
-			Deaths: map[string]interface{}{
+			}),
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 20.0,
 				"1/24/20": 3.0,
@@ -153,8 +159,8 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 400.0,
 				"1/28/20": 5040.0,
 				"1/29/20": 601.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 1.0,
 				"1/23/20": 2.0,
 				"1/24/20": 3.0,
@@ -163,7 +169,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 				"1/27/20": 440.0,
 				"1/28/20": 500.0,
 				"1/29/20": 601.0,
-			},
+			}),
 		}
 		w := mcountry.CountryCurve{
 			Country:  "wCountry",
@@ -179,7 +185,7 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 	}
 
 	daysAmmount := 4
-	mcdnpOneDay, mcdnpOneDayError := MostCasesDeathsNearPast(daysAmmount)
+	mcdnpOneDay, mcdnpOneDayError := MostCasesDeathsNearPast(daysAmmount, smoothing.Options{})
 	if mcdnpOneDayError != nil {
 		t.Fatal(mcdnpOneDayError)
 	}
@@ -192,22 +198,45 @@ func TestMostCasesDeathsNearPast(t *testing.T) {
 		t.Fatalf("Wrong ammount of data in cases")
 	}
 
-	if mcdnpOneDay.MostDeaths.Country != "wCountry" {
-		t.Fatalf("Country with most cases looks wrong")
+	// the 4640 deaths of wCountry on 1/28 are corrected down to 601 on 1/29,
+	// counted with the correction it ties yCountry and zCountry and keeps its
+	// order, and the correction of xCountry on 1/29 leaves it out of the top 3
+	if mcdnpOneDay.MostDeaths.Country != "yCountry" ||
+		mcdnpOneDay.SecondDeaths.Country != "zCountry" ||
+		mcdnpOneDay.ThirdDeaths.Country != "wCountry" {
+		t.Fatalf("Countries with most deaths look wrong: %s, %s, %s", mcdnpOneDay.MostDeaths.Country,
+			mcdnpOneDay.SecondDeaths.Country, mcdnpOneDay.ThirdDeaths.Country)
 	}
 
-	if len(mcdnpOneDay.MostDeaths.Data) != daysAmmount {
+	if len(mcdnpOneDay.MostDeaths.Data) != daysAmmount || len(mcdnpOneDay.ThirdDeaths.Data) != daysAmmount {
 		t.Fatalf("Wrong ammount of data in deaths")
 	}
 
-	if calculateTotalAmmount(mcdnpOneDay.MostDeaths.Data) < calculateTotalAmmount(mcdnpOneDay.SecondDeaths.Data) {
+	correction := mcdnpOneDay.ThirdDeaths.Data[daysAmmount-1]
+	if correction.Date != "2020-01-29" || correction.Value != -4439 {
+		t.Fatalf("Deaths of wCountry on 1/29 should be the negative correction -4439, got %+v", correction)
+	}
+
+	if calculateTotalAmmount(mcdnpOneDay.MostDeaths.Data.Values()) < calculateTotalAmmount(mcdnpOneDay.SecondDeaths.Data.Values()) {
 		t.Fatalf("Wrong sum of data in deaths from most to second")
 	}
 
-	if calculateTotalAmmount(mcdnpOneDay.SecondDeaths.Data) < calculateTotalAmmount(mcdnpOneDay.ThirdDeaths.Data) {
+	if calculateTotalAmmount(mcdnpOneDay.SecondDeaths.Data.Values()) < calculateTotalAmmount(mcdnpOneDay.ThirdDeaths.Data.Values()) {
 		t.Fatalf("Wrong sum of data in deaths from second to third")
 	}
 
+	smoothed, smoothedError := MostCasesDeathsNearPast(daysAmmount, smoothing.Options{Method: smoothing.Trailing, Window: 3})
+	if smoothedError != nil {
+		t.Fatal(smoothedError)
+	}
+
+	if len(smoothed.MostCases.Data) != daysAmmount {
+		t.Fatalf("Wrong ammount of smoothed data in cases")
+	}
+
+	if smoothed.MostCases.Data[0].Date != mcdnpOneDay.MostCases.Data[0].Date {
+		t.Fatalf("Smoothing should keep the dates of the last days")
+	}
 }
 
 func calculateTotalAmmount(arr []float64) float64 {
@@ -0,0 +1,133 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mworld "github.com/junkd0g/covid/lib/model/world"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+)
+
+// fuzzSeries returns a cumulative series of the bytes, every byte is the
+// signed change of a day so the series has downward corrections, starting
+// start days after 2020-01-22
+func fuzzSeries(data []byte, start int, scale float64) mcountry.Timeline {
+	first, _ := time.Parse(mcountry.DateLayout, "2020-01-22")
+	series := make(mcountry.Timeline, 0, len(data))
+	total := 0.0
+	for i, b := range data {
+		total += float64(int8(b)) * scale
+		series = append(series, mcountry.TimelinePoint{
+			Date:  first.AddDate(0, 0, start+i).Format(mcountry.DateLayout),
+			Value: total,
+		})
+	}
+	return series
+}
+
+// fuzzCountries splits the bytes in countries of unequal lengths that
+// start on different days, the first byte of every country is its length
+func fuzzCountries(data []byte) []mcountry.CountryCurve {
+	countries := []mcountry.CountryCurve{}
+	for i := 0; len(data) > 0; i++ {
+		n := int(data[0]) % len(data)
+		series := fuzzSeries(data[1:n+1], i%5, 10)
+		data = data[n+1:]
+
+		countries = append(countries, mcountry.CountryCurve{
+			Country:  fmt.Sprintf("Country%d", i%4),
+			Timeline: mcountry.TimelineStruct{Cases: series, Deaths: series, Recovered: series},
+		})
+	}
+	return countries
+}
+
+type fuzzRankingData struct{}
+
+func (u fuzzRankingData) getPopulations() (map[string]int, error) {
+	return map[string]int{"Country0": 1, "Country1": 1000, "Country2": 0}, nil
+}
+
+func (u fuzzRankingData) getContinentCountries(continent string) (map[string]bool, error) {
+	return map[string]bool{"country0": true, "country3": true}, nil
+}
+
+// marshals fails the test if v can not be sent as JSON, like a NaN or an infinity
+func marshals(t *testing.T, v interface{}) {
+	t.Helper()
+	if _, err := json.Marshal(v); err != nil {
+		t.Fatalf("%v: %+v", err, v)
+	}
+}
+
+func FuzzRank(f *testing.F) {
+	f.Add([]byte{5, 1, 2, 3, 4, 5, 3, 200, 10, 10}, 3, 2, uint8(0), uint8(0), uint8(0))
+	f.Add([]byte{0, 0, 255, 1}, 1, 10, uint8(1), uint8(1), uint8(1))
+	f.Add([]byte{}, MaxWindow, 1, uint8(2), uint8(2), uint8(2))
+	f.Add([]byte{9, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 100000, -1, uint8(2), uint8(0), uint8(3))
+
+	bys := []string{RankAbsolute, RankPerCapita, RankAcceleration}
+	continents := []string{"", "Europe"}
+	f.Fuzz(func(t *testing.T, data []byte, days int, top int, by uint8, metric uint8, options uint8) {
+		countryData = countryDataAnalytics{}
+		countryDataAnalyticsMock = func() ([]mcountry.CountryCurve, error) {
+			return fuzzCountries(data), nil
+		}
+		rankingData = fuzzRankingData{}
+
+		q := RankQuery{
+			Days:      days,
+			Top:       top,
+			By:        bys[int(by)%len(bys)],
+			Metric:    rankMetrics[int(metric)%len(rankMetrics)],
+			Continent: continents[int(options)%len(continents)],
+			Smoothing: smoothing.Options{Method: smoothing.Trailing, Window: int(options%9) + 1, ClipNegative: options%2 == 0},
+		}
+
+		ranking, err := Rank(q)
+		if err != nil {
+			return
+		}
+		want := q.Top
+		if want == 0 {
+			want = DefaultTop
+		}
+		if len(ranking.Countries) > want {
+			t.Fatalf("%d countries when the top is %d", len(ranking.Countries), want)
+		}
+		for _, v := range ranking.Countries {
+			if len(v.Data) > days {
+				t.Fatalf("%d days when the window is %d", len(v.Data), days)
+			}
+		}
+		marshals(t, ranking)
+
+		if _, err := MostCasesDeathsNearPast(days, q.Smoothing); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzGrowth(f *testing.F) {
+	f.Add([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, 1.0)
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 200, 0}, 1e6)
+	f.Add([]byte{127, 129, 127, 129, 127, 129, 127, 129, 127}, -3.5)
+
+	f.Fuzz(func(t *testing.T, data []byte, scale float64) {
+		series := fuzzSeries(data, 0, scale)
+		marshals(t, Growth(series, true))
+
+		worldData = worldDataAnalytics{}
+		worldDataAnalyticsMock = func() (mworld.WorldTimeline, error) {
+			return mworld.WorldTimeline{Cases: series, Deaths: series[len(series)/2:]}, nil
+		}
+		metrics, err := WorldGrowthMetrics()
+		if err != nil {
+			t.Fatal(err)
+		}
+		marshals(t, metrics)
+	})
+}
@@ -0,0 +1,217 @@
+package analytics
+
+/*
+	Growth metrics of the cumulative series of cases and deaths:
+	growth rate, doubling time, week over week change and an
+	estimate of the effective reproduction number (Rt)
+*/
+
+import (
+	"math"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	curve "github.com/junkd0g/covid/lib/curve"
+	cworld "github.com/junkd0g/covid/lib/cworld"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mmetrics "github.com/junkd0g/covid/lib/model/metrics"
+	mworld "github.com/junkd0g/covid/lib/model/world"
+	registry "github.com/junkd0g/covid/lib/registry"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+)
+
+const (
+	// World is the name the growth metrics of the world are returned with
+	World = cworld.Name
+	// growthWindow is the number of days the growth rate is averaged over,
+	// a week so it does not follow the weekly reporting pattern
+	growthWindow = 7
+	// serialInterval is the mean number of days between the onset of the
+	// symptoms of a case and of the cases it infects
+	serialInterval = 4
+)
+
+// ErrCountryNotFound is returned when there are no curves for a country,
+// it is registry.ErrNotFound so the error has the suggestions of the registry
+var ErrCountryNotFound = registry.ErrNotFound
+
+var (
+	worldData getWorldData
+)
+
+func init() {
+	worldData = worldOB{}
+}
+
+type worldOB struct{}
+
+type getWorldData interface {
+	getWorldHistory() (mworld.WorldTimeline, error)
+}
+
+func (r worldOB) getWorldHistory() (mworld.WorldTimeline, error) {
+	return cworld.GetaWorldHistory()
+}
+
+// CountryGrowthMetrics returns the growth metrics of the cases and the
+// deaths of a country
+// It returns ErrCountryNotFound if there are no curves for the country
+func CountryGrowthMetrics(name string) (mmetrics.GrowthMetrics, error) {
+	countries, err := countryData.getAllCountries()
+	if err != nil {
+		applogger.Log("ERROR", "analytics", "CountryGrowthMetrics", err.Error())
+		return mmetrics.GrowthMetrics{}, err
+	}
+
+	country, err := curve.GetCountryBP(name, countries)
+	if err != nil {
+		applogger.Log("ERROR", "analytics", "CountryGrowthMetrics", err.Error())
+		return mmetrics.GrowthMetrics{}, err
+	}
+
+	return mmetrics.GrowthMetrics{
+		Country: country.Country,
+		Cases:   Growth(country.Timeline.Cases, true),
+		Deaths:  Growth(country.Timeline.Deaths, false),
+	}, nil
+}
+
+// WorldGrowthMetrics returns the growth metrics of the cases and the
+// deaths of the world
+func WorldGrowthMetrics() (mmetrics.GrowthMetrics, error) {
+	world, err := worldData.getWorldHistory()
+	if err != nil {
+		applogger.Log("ERROR", "analytics", "WorldGrowthMetrics", err.Error())
+		return mmetrics.GrowthMetrics{}, err
+	}
+
+	return mmetrics.GrowthMetrics{
+		Country: World,
+		Cases:   Growth(world.Cases, true),
+		Deaths:  Growth(world.Deaths, false),
+	}, nil
+}
+
+// Growth returns the growth metrics of a cumulative series, with the
+// Rt estimate of its per day series when withRt is true
+func Growth(cumulative mcountry.Timeline, withRt bool) mmetrics.SeriesMetrics {
+	metrics := mmetrics.SeriesMetrics{
+		GrowthRate:   GrowthRate(cumulative),
+		DoublingTime: DoublingTime(cumulative),
+		WeekOverWeek: WeekOverWeek(cumulative),
+	}
+	if withRt {
+		metrics.Rt = ReproductionNumber(cumulative.Daily())
+	}
+
+	if len(cumulative) == 0 {
+		return metrics
+	}
+
+	date := cumulative[len(cumulative)-1].Date
+	metrics.Latest = mmetrics.Latest{
+		Date:         date,
+		GrowthRate:   valueOn(metrics.GrowthRate, date),
+		DoublingTime: valueOn(metrics.DoublingTime, date),
+		WeekOverWeek: valueOn(metrics.WeekOverWeek, date),
+	}
+	if withRt {
+		metrics.Latest.Rt = valueOn(metrics.Rt, date)
+	}
+
+	return metrics
+}
+
+// GrowthRate returns the daily growth rate of a cumulative series, the
+// rate that compounded over the last week gives the growth of the week.
+// There is no point for the days that have nothing a week before them
+func GrowthRate(cumulative mcountry.Timeline) mcountry.Timeline {
+	rates := mcountry.Timeline{}
+	for i := growthWindow; i < len(cumulative); i++ {
+		previous, current := cumulative[i-growthWindow].Value, cumulative[i].Value
+		if previous <= 0 || current < 0 {
+			continue
+		}
+		rate := math.Pow(current/previous, 1/float64(growthWindow)) - 1
+		rates = append(rates, mcountry.TimelinePoint{Date: cumulative[i].Date, Value: rate})
+	}
+	return rates
+}
+
+// DoublingTime returns the number of days a cumulative series takes to
+// double at the growth rate of the last week. There is no point for
+// the days the series has not grown in the last week
+func DoublingTime(cumulative mcountry.Timeline) mcountry.Timeline {
+	days := mcountry.Timeline{}
+	for i := growthWindow; i < len(cumulative); i++ {
+		previous, current := cumulative[i-growthWindow].Value, cumulative[i].Value
+		if previous <= 0 || current <= previous {
+			continue
+		}
+		doubling := float64(growthWindow) * math.Ln2 / math.Log(current/previous)
+		days = append(days, mcountry.TimelinePoint{Date: cumulative[i].Date, Value: doubling})
+	}
+	return days
+}
+
+// WeekOverWeek returns the change of the new values of the last week
+// from the new values of the week before it as a fraction, 0.25 is 25%
+// more. There is no point for the days the week before had no new values
+func WeekOverWeek(cumulative mcountry.Timeline) mcountry.Timeline {
+	changes := mcountry.Timeline{}
+	for i := 2 * growthWindow; i < len(cumulative); i++ {
+		lastWeek := cumulative[i].Value - cumulative[i-growthWindow].Value
+		weekBefore := cumulative[i-growthWindow].Value - cumulative[i-2*growthWindow].Value
+		if weekBefore <= 0 {
+			continue
+		}
+		changes = append(changes, mcountry.TimelinePoint{
+			Date:  cumulative[i].Date,
+			Value: (lastWeek - weekBefore) / weekBefore,
+		})
+	}
+	return changes
+}
+
+// ReproductionNumber estimates the effective reproduction number (Rt)
+// of a per day series as the ratio of the new values of the last week
+// to the new values of the week a serial interval before it. Negative
+// values are corrections and count as zero. There is no point for the
+// days the earlier week had no new values
+func ReproductionNumber(daily mcountry.Timeline) mcountry.Timeline {
+	values := smoothing.ClipNegative(daily.Values())
+
+	// weekly[i] is the sum of the week that ends on day i+growthWindow-1
+	weekly := make([]float64, 0, len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= growthWindow {
+			sum -= values[i-growthWindow]
+		}
+		if i >= growthWindow-1 {
+			weekly = append(weekly, sum)
+		}
+	}
+
+	rt := mcountry.Timeline{}
+	for i := serialInterval; i < len(weekly); i++ {
+		if weekly[i-serialInterval] <= 0 {
+			continue
+		}
+		rt = append(rt, mcountry.TimelinePoint{
+			Date:  daily[i+growthWindow-1].Date,
+			Value: weekly[i] / weekly[i-serialInterval],
+		})
+	}
+	return rt
+}
+
+// valueOn returns the value of a timeline on date and nil if it has no
+// point for that date
+func valueOn(t mcountry.Timeline, date string) *float64 {
+	if len(t) == 0 || t[len(t)-1].Date != date {
+		return nil
+	}
+	value := t[len(t)-1].Value
+	return &value
+}
@@ -0,0 +1,142 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mworld "github.com/junkd0g/covid/lib/model/world"
+)
+
+type worldDataAnalytics struct{}
+
+var worldDataAnalyticsMock func() (mworld.WorldTimeline, error)
+
+func (u worldDataAnalytics) getWorldHistory() (mworld.WorldTimeline, error) {
+	return worldDataAnalyticsMock()
+}
+
+// doublingWeeklyMock is a cumulative series of days that doubles every week
+func doublingWeeklyMock(days int) mcountry.Timeline {
+	data := map[string]float64{}
+	for i := 0; i < days; i++ {
+		data[fmt.Sprintf("1/%d/20", i+1)] = 100 * math.Pow(2, float64(i)/7)
+	}
+	return timelineMock(data)
+}
+
+func almostEqual(t *testing.T, name string, got mcountry.Timeline, length int, want float64) {
+	t.Helper()
+	if len(got) != length {
+		t.Fatalf("Wrong number of %s points %d", name, len(got))
+	}
+	for _, v := range got {
+		if math.Abs(v.Value-want) > 1e-9 {
+			t.Fatalf("Wrong %s value %v on %s", name, v.Value, v.Date)
+		}
+	}
+}
+
+func TestGrowth(t *testing.T) {
+	cumulative := doublingWeeklyMock(30)
+	metrics := Growth(cumulative, true)
+
+	almostEqual(t, "growth rate", metrics.GrowthRate, 23, math.Pow(2, 1.0/7)-1)
+	almostEqual(t, "doubling time", metrics.DoublingTime, 23, 7)
+	almostEqual(t, "week over week", metrics.WeekOverWeek, 16, 1)
+	// 29 per day values, 23 weekly sums, 19 of them with one 4 days before
+	almostEqual(t, "rt", metrics.Rt, 19, math.Pow(2, 4.0/7))
+
+	if metrics.Latest.Date != "2020-01-30" || metrics.Latest.DoublingTime == nil ||
+		math.Abs(*metrics.Latest.DoublingTime-7) > 1e-9 || metrics.Latest.Rt == nil {
+		t.Fatalf("Wrong latest metrics %+v", metrics.Latest)
+	}
+
+	if metrics := Growth(cumulative, false); metrics.Rt != nil || metrics.Latest.Rt != nil {
+		t.Fatalf("Rt should only be estimated when asked")
+	}
+}
+
+func TestGrowthFlat(t *testing.T) {
+	cumulative := timelineMock(map[string]float64{
+		"1/1/20": 0, "1/2/20": 0, "1/3/20": 0, "1/4/20": 0, "1/5/20": 0,
+		"1/6/20": 0, "1/7/20": 0, "1/8/20": 5, "1/9/20": 5, "1/10/20": 5,
+		"1/11/20": 5, "1/12/20": 5, "1/13/20": 5, "1/14/20": 5, "1/15/20": 5,
+		"1/16/20": 5,
+	})
+	metrics := Growth(cumulative, true)
+
+	if len(metrics.DoublingTime) != 0 {
+		t.Fatalf("A series that does not grow has no doubling time %v", metrics.DoublingTime)
+	}
+	almostEqual(t, "growth rate", metrics.GrowthRate, 2, 0)
+	almostEqual(t, "week over week", metrics.WeekOverWeek, 2, -1)
+
+	latest := metrics.Latest
+	if latest.DoublingTime != nil || latest.GrowthRate == nil || *latest.GrowthRate != 0 {
+		t.Fatalf("Wrong latest metrics %+v", latest)
+	}
+
+	if metrics := Growth(mcountry.Timeline{}, true); len(metrics.GrowthRate) != 0 || metrics.Latest.Date != "" {
+		t.Fatalf("An empty series has no metrics %+v", metrics)
+	}
+}
+
+func TestReproductionNumberCorrections(t *testing.T) {
+	daily := mcountry.Timeline{}
+	for i := 0; i < 12; i++ {
+		daily = append(daily, mcountry.TimelinePoint{Date: fmt.Sprintf("2020-01-%02d", i+1), Value: 10})
+	}
+	daily[10].Value = -50
+
+	rt := ReproductionNumber(daily)
+	if len(rt) != 2 || rt[0].Date != "2020-01-11" || rt[0].Value != 6.0/7 || rt[1].Value != 6.0/7 {
+		t.Fatalf("Wrong rt %v", rt)
+	}
+}
+
+func TestCountryGrowthMetrics(t *testing.T) {
+	countryData = countryDataAnalytics{}
+	countryDataAnalyticsMock = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{{
+			Country:  "Greece",
+			Timeline: mcountry.TimelineStruct{Cases: doublingWeeklyMock(30), Deaths: doublingWeeklyMock(30)},
+		}}, nil
+	}
+
+	metrics, err := CountryGrowthMetrics("Greece")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Country != "Greece" || len(metrics.Cases.Rt) == 0 || len(metrics.Deaths.Rt) != 0 {
+		t.Fatalf("Wrong metrics %+v", metrics)
+	}
+
+	if _, err := CountryGrowthMetrics("Atlantis"); !errors.Is(err, ErrCountryNotFound) {
+		t.Fatalf("Expected ErrCountryNotFound, got %v", err)
+	}
+}
+
+func TestWorldGrowthMetrics(t *testing.T) {
+	worldData = worldDataAnalytics{}
+	worldDataAnalyticsMock = func() (mworld.WorldTimeline, error) {
+		return mworld.WorldTimeline{Cases: doublingWeeklyMock(30), Deaths: doublingWeeklyMock(10)}, nil
+	}
+
+	metrics, err := WorldGrowthMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Country != World || len(metrics.Cases.WeekOverWeek) != 16 || len(metrics.Deaths.WeekOverWeek) != 0 {
+		t.Fatalf("Wrong metrics %+v", metrics)
+	}
+
+	worldDataAnalyticsMock = func() (mworld.WorldTimeline, error) {
+		return mworld.WorldTimeline{}, errors.New("upstream down")
+	}
+	if _, err := WorldGrowthMetrics(); err == nil {
+		t.Fatal("Expected the error of the world history")
+	}
+}
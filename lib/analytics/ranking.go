@@ -0,0 +1,250 @@
+package analytics
+
+/*
+	Ranking the countries by a per day metric over their last days
+*/
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	curve "github.com/junkd0g/covid/lib/curve"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mhotspot "github.com/junkd0g/covid/lib/model/hotspot"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+	stats "github.com/junkd0g/covid/lib/stats"
+)
+
+// What the countries are ranked by
+const (
+	// RankAbsolute ranks by the sum of the metric over the last days
+	RankAbsolute = "absolute"
+	// RankPerCapita ranks by the sum of the metric over the last days per
+	// 100k people, the countries whose population is unknown are left out
+	RankPerCapita = "perCapita"
+	// RankAcceleration ranks by how much the sum of the metric over the
+	// last days grew from the sum over the days before them
+	RankAcceleration = "acceleration"
+)
+
+const (
+	// DefaultRankMetric is the metric countries are ranked by when none is set
+	DefaultRankMetric = curve.MetricCasesPerDay
+	// DefaultTop is the number of countries of a ranking when none is set
+	DefaultTop = 3
+	// per100k is the number of people the per capita sums are of
+	per100k = 100000
+)
+
+var (
+	// ErrUnknownRanking is returned when the countries are ranked by
+	// something other than RankAbsolute, RankPerCapita or RankAcceleration
+	ErrUnknownRanking = errors.New("unknown ranking")
+	// ErrInvalidTop is returned when the number of countries of a ranking
+	// is not positive
+	ErrInvalidTop = errors.New("invalid number of countries")
+)
+
+// rankMetrics are the per day metrics the countries can be ranked by
+var rankMetrics = []string{curve.MetricCasesPerDay, curve.MetricDeathsPerDay, curve.MetricRecoveredPerDay}
+
+var (
+	rankingData getRankingData
+)
+
+func init() {
+	rankingData = rankingOB{}
+}
+
+type rankingOB struct{}
+
+type getRankingData interface {
+	getPopulations() (map[string]int, error)
+	getContinentCountries(continent string) (map[string]bool, error)
+}
+
+func (r rankingOB) getPopulations() (map[string]int, error) {
+	return curve.Populations()
+}
+
+func (r rankingOB) getContinentCountries(continent string) (map[string]bool, error) {
+	return stats.ContinentCountries([]string{continent})
+}
+
+// RankQuery is the metric to rank the countries by over the last Days
+// days, by RankAbsolute, RankPerCapita or RankAcceleration, keeping the
+// Top countries, of Continent when it is set. The per day series are
+// smoothed before taking their last days. DefaultRankMetric, DefaultTop
+// and RankAbsolute are used for the ones that are not set
+type RankQuery struct {
+	Metric    string
+	Days      int
+	Top       int
+	By        string
+	Continent string
+	Smoothing smoothing.Options
+}
+
+// Validate returns the query with its defaults set
+// It returns an error wrapping ErrInvalidWindow, curve.ErrUnknownMetric,
+// ErrUnknownRanking or ErrInvalidTop
+func (q RankQuery) Validate() (RankQuery, error) {
+	if err := ValidateWindow(q.Days); err != nil {
+		return RankQuery{}, err
+	}
+
+	if q.Metric == "" {
+		q.Metric = DefaultRankMetric
+	}
+	if q.By == "" {
+		q.By = RankAbsolute
+	}
+
+	known := false
+	for _, metric := range rankMetrics {
+		known = known || metric == q.Metric
+	}
+	if !known {
+		return RankQuery{}, fmt.Errorf("%w %q, expecting one of %s",
+			curve.ErrUnknownMetric, q.Metric, strings.Join(rankMetrics, ", "))
+	}
+
+	switch q.By {
+	case RankAbsolute, RankPerCapita, RankAcceleration:
+	default:
+		return RankQuery{}, fmt.Errorf("%w %q, expecting %s, %s or %s",
+			ErrUnknownRanking, q.By, RankAbsolute, RankPerCapita, RankAcceleration)
+	}
+
+	if q.Top < 0 {
+		return RankQuery{}, fmt.Errorf("%w %d", ErrInvalidTop, q.Top)
+	}
+	if q.Top == 0 {
+		q.Top = DefaultTop
+	}
+
+	return q, nil
+}
+
+// Rank returns the top countries of the query
+// It returns the errors of RankQuery.Validate and an error wrapping
+// stats.ErrUnknownContinent if the continent is unknown
+func Rank(q RankQuery) (mhotspot.Ranking, error) {
+	q, err := q.Validate()
+	if err != nil {
+		return mhotspot.Ranking{}, err
+	}
+
+	var inContinent map[string]bool
+	if q.Continent != "" {
+		if inContinent, err = rankingData.getContinentCountries(q.Continent); err != nil {
+			return mhotspot.Ranking{}, err
+		}
+	}
+
+	var populations map[string]int
+	if q.By == RankPerCapita {
+		if populations, err = rankingData.getPopulations(); err != nil {
+			applogger.Log("ERROR", "analytics", "Rank", err.Error())
+			return mhotspot.Ranking{}, err
+		}
+	}
+
+	countries, err := countryData.getAllCountries()
+	if err != nil {
+		applogger.Log("ERROR", "analytics", "Rank", err.Error())
+		return mhotspot.Ranking{}, err
+	}
+
+	ranked, err := rank(countries, q, inContinent, populations)
+	if err != nil {
+		return mhotspot.Ranking{}, err
+	}
+
+	return mhotspot.Ranking{
+		Metric:    q.Metric,
+		Days:      q.Days,
+		By:        q.By,
+		Continent: q.Continent,
+		Countries: ranked,
+	}, nil
+}
+
+// rank returns the top countries of a validated query, a country that has
+// more than one curve is ranked once. Every country is ranked over the
+// same days, the last days of the series that ends last. inContinent,
+// when not nil, are the lower case names of the countries allowed and
+// populations the populations of the countries when ranking per capita
+func rank(countries []mcountry.CountryCurve, q RankQuery, inContinent map[string]bool, populations map[string]int) ([]mhotspot.RankedCountry, error) {
+	type candidate struct {
+		name       string
+		series     mcountry.Timeline
+		population int
+	}
+
+	candidates := make([]candidate, 0, len(countries))
+	all := make([]mcountry.Timeline, 0, len(countries))
+	for _, v := range curve.ByCountry(countries) {
+		if inContinent != nil && !inContinent[strings.ToLower(v.Country)] {
+			continue
+		}
+
+		population := populations[curve.PopulationKey(v.Country)]
+		if q.By == RankPerCapita && population <= 0 {
+			continue
+		}
+
+		series, err := curve.Series(curve.CurveData(v), q.Metric)
+		if err != nil {
+			return nil, err
+		}
+		series = smoothing.Apply(series, q.Smoothing)
+
+		candidates = append(candidates, candidate{name: v.Country, series: series, population: population})
+		all = append(all, series)
+	}
+
+	window, _ := LastWindow(q.Days, all...)
+	ranked := make([]mhotspot.RankedCountry, 0, len(candidates))
+	for _, c := range candidates {
+		last := window.Of(c.series)
+		country := mhotspot.RankedCountry{Country: c.name, Total: sum(last), Data: last}
+
+		switch q.By {
+		case RankAbsolute:
+			country.Value = country.Total
+		case RankPerCapita:
+			country.Value = country.Total * per100k / float64(c.population)
+		case RankAcceleration:
+			previous := sum(window.Previous().Of(c.series))
+			country.Previous = &previous
+			country.Value = country.Total - previous
+		}
+
+		ranked = append(ranked, country)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Value > ranked[j].Value
+	})
+
+	if len(ranked) > q.Top {
+		ranked = ranked[:q.Top]
+	}
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+
+	return ranked, nil
+}
+
+func sum(t mcountry.Timeline) float64 {
+	total := 0.0
+	for _, v := range t {
+		total += v.Value
+	}
+	return total
+}
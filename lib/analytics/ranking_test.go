@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	curve "github.com/junkd0g/covid/lib/curve"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	stats "github.com/junkd0g/covid/lib/stats"
+)
+
+type rankingDataAnalytics struct{}
+
+func (u rankingDataAnalytics) getPopulations() (map[string]int, error) {
+	return map[string]int{"GRC": 10000000, "ITA": 60000000, "ESP": 0}, nil
+}
+
+func (u rankingDataAnalytics) getContinentCountries(continent string) (map[string]bool, error) {
+	if continent != "Europe" {
+		return nil, stats.ErrUnknownContinent
+	}
+	return map[string]bool{"greece": true, "italy": true, "spain": true}, nil
+}
+
+// cumulativeMock returns a cumulative series whose per day values are daily
+func cumulativeMock(daily ...float64) mcountry.Timeline {
+	data := map[string]float64{"1/1/20": 0}
+	total := 0.0
+	for i, v := range daily {
+		total += v
+		data[fmt.Sprintf("1/%d/20", i+2)] = total
+	}
+	return timelineMock(data)
+}
+
+func rankingMock() ([]mcountry.CountryCurve, error) {
+	countryCurve := func(name string, province string, daily ...float64) mcountry.CountryCurve {
+		return mcountry.CountryCurve{
+			Country:  name,
+			Province: province,
+			Timeline: mcountry.TimelineStruct{Cases: cumulativeMock(daily...), Deaths: cumulativeMock(daily...)},
+		}
+	}
+
+	return []mcountry.CountryCurve{
+		countryCurve("Italy", "", 50, 50, 10, 10),
+		countryCurve("Greece", "", 1, 1, 20, 30),
+		countryCurve("Greece", "Crete", 1, 1, 1, 1),
+		countryCurve("Spain", "", 5, 5, 15, 15),
+		countryCurve("USA", "", 100, 100, 100, 100),
+	}, nil
+}
+
+func countries(t *testing.T, q RankQuery) []string {
+	t.Helper()
+	ranking, err := Rank(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for i, v := range ranking.Countries {
+		if v.Rank != i+1 {
+			t.Fatalf("Wrong rank %d of %s", v.Rank, v.Country)
+		}
+		names = append(names, v.Country)
+	}
+	return names
+}
+
+func TestRank(t *testing.T) {
+	countryData = countryDataAnalytics{}
+	countryDataAnalyticsMock = rankingMock
+	rankingData = rankingDataAnalytics{}
+
+	tests := []struct {
+		q        RankQuery
+		expected []string
+	}{
+		{RankQuery{Days: 2}, []string{"USA", "Greece", "Spain"}},
+		{RankQuery{Days: 2, Top: 10}, []string{"USA", "Greece", "Spain", "Italy"}},
+		{RankQuery{Days: 2, Continent: "Europe"}, []string{"Greece", "Spain", "Italy"}},
+		{RankQuery{Days: 2, By: RankPerCapita}, []string{"Greece", "Italy"}},
+		{RankQuery{Days: 2, By: RankAcceleration, Top: 2}, []string{"Greece", "Spain"}},
+		{RankQuery{Days: 4, Metric: curve.MetricDeathsPerDay, Continent: "Europe", Top: 1}, []string{"Italy"}},
+	}
+
+	for _, test := range tests {
+		if names := countries(t, test.q); !equalNames(names, test.expected) {
+			t.Fatalf("Wrong ranking %v for %+v, expecting %v", names, test.q, test.expected)
+		}
+	}
+
+	ranking, _ := Rank(RankQuery{Days: 2, By: RankAcceleration, Top: 1})
+	greece := ranking.Countries[0]
+	if greece.Total != 50 || greece.Previous == nil || *greece.Previous != 2 || greece.Value != 48 || len(greece.Data) != 2 {
+		t.Fatalf("Wrong acceleration of Greece %+v", greece)
+	}
+
+	ranking, _ = Rank(RankQuery{Days: 2, By: RankPerCapita, Top: 1})
+	if greece := ranking.Countries[0]; greece.Value != 0.5 {
+		t.Fatalf("Wrong cases per 100k of Greece %+v", greece)
+	}
+}
+
+func TestRankInvalid(t *testing.T) {
+	rankingData = rankingDataAnalytics{}
+
+	tests := []struct {
+		q   RankQuery
+		err error
+	}{
+		{RankQuery{Days: 2, Metric: curve.MetricCases}, curve.ErrUnknownMetric},
+		{RankQuery{Days: 2, By: "relative"}, ErrUnknownRanking},
+		{RankQuery{Days: 2, Top: -1}, ErrInvalidTop},
+		{RankQuery{Days: 2, Continent: "Atlantis"}, stats.ErrUnknownContinent},
+	}
+
+	for _, test := range tests {
+		if _, err := Rank(test.q); !errors.Is(err, test.err) {
+			t.Fatalf("Expected %v for %+v, got %v", test.err, test.q, err)
+		}
+	}
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
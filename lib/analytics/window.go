@@ -0,0 +1,89 @@
+package analytics
+
+/*
+	Windows of the last days of series that can be of unequal lengths
+	or end on different days
+*/
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// MaxWindow is the most days a window can have
+const MaxWindow = 365
+
+// ErrInvalidWindow is returned when a window is not between 1 and MaxWindow days
+var ErrInvalidWindow = errors.New("invalid window")
+
+// ValidateWindow returns an error wrapping ErrInvalidWindow if a window
+// of days is not between 1 and MaxWindow days
+func ValidateWindow(days int) error {
+	if days < 1 || days > MaxWindow {
+		return fmt.Errorf("%w of %d days, expecting 1 to %d", ErrInvalidWindow, days, MaxWindow)
+	}
+	return nil
+}
+
+// Window is the days from From to To, both included, as YYYY-MM-DD
+type Window struct {
+	From string
+	To   string
+}
+
+// LastWindow returns the window of days that ends on the last day of
+// the series that ends last, so series of unequal lengths or that end
+// on different days are windowed on the same days
+func LastWindow(days int, series ...mcountry.Timeline) (Window, bool) {
+	to := ""
+	for _, t := range series {
+		if len(t) != 0 && t[len(t)-1].Date > to {
+			to = t[len(t)-1].Date
+		}
+	}
+
+	end, err := time.Parse(mcountry.DateLayout, to)
+	if err != nil || days < 1 {
+		return Window{}, false
+	}
+
+	return Window{From: end.AddDate(0, 0, 1-days).Format(mcountry.DateLayout), To: to}, true
+}
+
+// Previous returns the window of the same days that ends the day before w
+func (w Window) Previous() Window {
+	from, errFrom := time.Parse(mcountry.DateLayout, w.From)
+	to, errTo := time.Parse(mcountry.DateLayout, w.To)
+	if errFrom != nil || errTo != nil {
+		return Window{}
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	return Window{
+		From: from.AddDate(0, 0, -days).Format(mcountry.DateLayout),
+		To:   from.AddDate(0, 0, -1).Format(mcountry.DateLayout),
+	}
+}
+
+// Of returns the points of a timeline ordered by date that are in the
+// window, fewer than the days of the window when the timeline does not
+// cover all of them
+func (w Window) Of(t mcountry.Timeline) mcountry.Timeline {
+	start := len(t)
+	for i, v := range t {
+		if v.Date >= w.From {
+			start = i
+			break
+		}
+	}
+
+	end := start
+	for end < len(t) && t[end].Date <= w.To {
+		end++
+	}
+
+	return t[start:end]
+}
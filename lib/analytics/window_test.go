@@ -0,0 +1,80 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+)
+
+func TestValidateWindow(t *testing.T) {
+	for _, days := range []int{1, 7, MaxWindow} {
+		if err := ValidateWindow(days); err != nil {
+			t.Fatalf("%d days should be a valid window, got %v", days, err)
+		}
+	}
+	for _, days := range []int{-1, 0, MaxWindow + 1, 100000} {
+		if err := ValidateWindow(days); !errors.Is(err, ErrInvalidWindow) {
+			t.Fatalf("Expected ErrInvalidWindow for %d days, got %v", days, err)
+		}
+	}
+}
+
+func TestLastWindow(t *testing.T) {
+	long := cumulativeMock(1, 2, 3, 4, 5, 6)
+	short := timelineMock(map[string]float64{"1/2/20": 1, "1/3/20": 2})
+
+	window, ok := LastWindow(3, short, long, mcountry.Timeline{})
+	if !ok || window.From != "2020-01-05" || window.To != "2020-01-07" {
+		t.Fatalf("Wrong window %+v", window)
+	}
+
+	if of := window.Of(long); len(of) != 3 || of[0].Date != "2020-01-05" {
+		t.Fatalf("Wrong days of the long series %v", of)
+	}
+	if of := window.Of(short); len(of) != 0 {
+		t.Fatalf("The short series ends before the window %v", of)
+	}
+
+	previous := window.Previous()
+	if previous.From != "2020-01-02" || previous.To != "2020-01-04" {
+		t.Fatalf("Wrong previous window %+v", previous)
+	}
+	if of := previous.Of(short); len(of) != 2 {
+		t.Fatalf("Wrong days of the short series %v", of)
+	}
+	if of := previous.Previous().Of(long); len(of) != 1 || of[0].Date != "2020-01-01" {
+		t.Fatalf("The window should be clamped to the series %v", of)
+	}
+
+	if _, ok := LastWindow(3, mcountry.Timeline{}); ok {
+		t.Fatal("Empty series have no window")
+	}
+	if _, ok := LastWindow(0, long); ok {
+		t.Fatal("A window of no days is not a window")
+	}
+}
+
+func TestRankUnequalLengths(t *testing.T) {
+	countryData = countryDataAnalytics{}
+	countryDataAnalyticsMock = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{
+			{Country: "Short", Timeline: mcountry.TimelineStruct{Cases: cumulativeMock(100)}},
+			{Country: "Empty"},
+			{Country: "Long", Timeline: mcountry.TimelineStruct{Cases: cumulativeMock(1, 1, 2, 2)}},
+		}, nil
+	}
+
+	ranking, err := Rank(RankQuery{Days: 2, Top: 3, By: RankAcceleration})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranking.Countries) != 3 || ranking.Countries[0].Country != "Long" || ranking.Countries[0].Total != 4 {
+		t.Fatalf("Every country should be ranked on the same days %+v", ranking.Countries)
+	}
+
+	if _, err := MostCasesDeathsNearPast(MaxWindow+1, smoothing.Options{}); !errors.Is(err, ErrInvalidWindow) {
+		t.Fatalf("Expected ErrInvalidWindow, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package caching
+
+/*
+	Cache backends that can store the results of the external API requests
+*/
+
+import (
+	"fmt"
+	"time"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+)
+
+const (
+	// BackendRedis stores the cached data in the redis server of the config
+	BackendRedis = "redis"
+	// BackendMemory stores the cached data in an in-process LRU cache
+	BackendMemory = "memory"
+)
+
+// Cache is a key value store with an expiration time per key
+// Get returns false when the key does not exist or has expired.
+// Set with a ttl of zero or less stores a key that never expires
+// and TTL returns a negative duration for such a key.
+type Cache interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	TTL(key string) (time.Duration, bool, error)
+	Delete(key string) error
+}
+
+// NewCache creates the cache backend of the cache.backend config key,
+// redis is used when no backend is set
+// It returns Cache and an error if the backend is unknown
+func NewCache(conf pconf.AppConf) (Cache, error) {
+	switch conf.Cache.Backend {
+	case "", BackendRedis:
+		return NewRedisCache(conf.Redis), nil
+	case BackendMemory:
+		return NewMemoryCache(conf.Cache.Size), nil
+	}
+
+	return nil, fmt.Errorf("unknown cache backend %q", conf.Cache.Backend)
+}
@@ -1,16 +1,15 @@
 package caching
 
-//TODO adding expiration time - need to be more dynamic
-//TODO add expiration time on requestDat for redis
-
 /*
 	Caching the results of the external API request for covid-19 data
 */
 
 import (
 	"encoding/json"
+	"sync"
+	"time"
 
-	"github.com/gomodule/redigo/redis"
+	applogger "github.com/junkd0g/covid/lib/applogger"
 	pconf "github.com/junkd0g/covid/lib/config"
 	mcontinent "github.com/junkd0g/covid/lib/model/continent"
 	mcountry "github.com/junkd0g/covid/lib/model/country"
@@ -19,234 +18,308 @@ import (
 	mworld "github.com/junkd0g/covid/lib/model/world"
 )
 
-var (
-	serverConf = pconf.GetAppConfig()
-	RedisOB    redisOBInt
+const (
+	defaultTTL = 2500 * time.Second
+	newsTTL    = 7200 * time.Second
+
+	// lastKnownGoodSuffix is appended to the key of the copy of a
+	// dataset that never expires
+	lastKnownGoodSuffix = ":lastknowngood"
+
+	// StaleHeader is the response header that is set when the data
+	// of a response is served from its last known good copy
+	StaleHeader = "X-Data-Stale"
 )
 
-func init() {
-	RedisOB = RedisST{}
-}
-
-type RedisST struct{}
-type redisOBInt interface {
-	NewPool() *redis.Pool
-	SetCountriesData(countries mcountry.Countries) error
-	GetCountriesData() (mcountry.Countries, error)
-	SetCurveData(countries []mcountry.CountryCurve) error
-	GetCurveData() ([]mcountry.CountryCurve, error)
-	SetNewsData(newsType string, news mnews.ArticlesData) error
-	GetNewsData(newsType string) (mnews.ArticlesData, bool, error)
-	GetContinentData() (mcontinent.Response, bool, error)
-	SetContinetData(ctn mcontinent.Response) error
-	SetCSSEData(ctn []mcsse.ResponseCountry) error
-	GetCSSEData() ([]mcsse.ResponseCountry, error)
-	SetWorldData(ctn mworld.WorldTimeline) error
-	GetWorldData() (mworld.WorldTimeline, bool, error)
-}
-
-//NewPool connects to redis
-func (r RedisST) NewPool() *redis.Pool {
-	return &redis.Pool{
-		MaxIdle:   serverConf.Redis.MaxIdle,
-		MaxActive: serverConf.Redis.MaxActive,
-
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", serverConf.Redis.URL)
-			if err != nil {
-				panic(err.Error())
-			}
-			return c, err
-		},
-	}
-}
+// Keys of the cached datasets
+const (
+	CountriesKey = "total"
+	CurveKey     = "curve"
+	WorldKey     = "world"
+	ContinentKey = "continent"
+	CSSEKey      = "csse"
+)
 
-// SetCountriesData executes the redis SET command
-func (r RedisST) SetCountriesData(countries mcountry.Countries) error {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	out, _ := json.Marshal(countries)
+// TTLs is the expiration time of each cached dataset
+type TTLs struct {
+	Countries time.Duration
+	Curve     time.Duration
+	World     time.Duration
+	Continent time.Duration
+	CSSE      time.Duration
+	News      time.Duration
+}
 
-	_, err := conn.Do("SETEX", "total", 2500, string(out))
-	if err != nil {
-		return err
+// NewTTLs converts the ttl seconds of the config to TTLs,
+// using the default expiration time of a dataset when it is not set
+func NewTTLs(conf pconf.CacheTTLConfig) TTLs {
+	seconds := func(s int, def time.Duration) time.Duration {
+		if s <= 0 {
+			return def
+		}
+		return time.Duration(s) * time.Second
 	}
 
-	return nil
+	return TTLs{
+		Countries: seconds(conf.Countries, defaultTTL),
+		Curve:     seconds(conf.Curve, defaultTTL),
+		World:     seconds(conf.World, defaultTTL),
+		Continent: seconds(conf.Continent, defaultTTL),
+		CSSE:      seconds(conf.CSSE, defaultTTL),
+		News:      seconds(conf.News, newsTTL),
+	}
 }
 
-// GetCountriesData executes the redis GET command
-func (r RedisST) GetCountriesData() (mcountry.Countries, error) {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	s, err := redis.String(conn.Do("GET", "total"))
+// NewStoreFromConfig creates the cache backend of the cache.backend config
+// key and a Store on top of it, configured with the cache section of the config
+// It returns Store and an error if the backend is unknown
+func NewStoreFromConfig(conf pconf.AppConf) (Store, error) {
+	cache, err := NewCache(conf)
 	if err != nil {
-		return mcountry.Countries{}, nil
+		return Store{}, err
 	}
 
-	bytStr := []byte(s)
-	datsa := mcountry.Countries{}
+	return NewStore(cache, conf.Cache.Namespace, NewTTLs(conf.Cache.TTL)), nil
+}
 
-	erra := json.Unmarshal(bytStr, &datsa)
-	if erra != nil {
-		return mcountry.Countries{}, nil
-	}
-	return datsa, nil
+// NewMemoryStore creates a Store on top of an in-process MemoryCache
+// with the default expiration times, the lib packages use it until
+// the Store of the config is set
+func NewMemoryStore() Store {
+	return NewStore(NewMemoryCache(0), "", NewTTLs(pconf.CacheTTLConfig{}))
 }
 
-// SetCurveData executes the redis SET command
-// @param c redis.Conn redis connection
-func (r RedisST) SetCurveData(countries []mcountry.CountryCurve) error {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	vv, _ := json.Marshal(countries)
-	_, err := conn.Do("SETEX", "curve", 2500, vv)
-	if err != nil {
-		return err
-	}
+// Store reads and writes the covid-19 datasets as JSON in a Cache.
+// Every key is prefixed with the namespace of the Store.
+// Along with every dataset a last known good copy that never expires
+// is kept, so the data can still be served when the dataset has
+// expired and the third party API is down
+type Store struct {
+	cache         Cache
+	namespace     string
+	ttl           TTLs
+	lastKnownGood bool
+	state         *storeState
+}
 
-	return nil
+// storeState keeps the datasets that are served from their last known
+// good copy and the ones that are being refreshed in the background
+type storeState struct {
+	mu         sync.Mutex
+	staleSince map[string]time.Time
+	refreshing map[string]bool
 }
 
-// GetCurveData executes the redis GET command
-func (r RedisST) GetCurveData() ([]mcountry.CountryCurve, error) {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	s, err := redis.String(conn.Do("GET", "curve"))
-	if err != nil {
-		return []mcountry.CountryCurve{}, nil
+// NewStore creates a Store on top of a Cache backend
+func NewStore(cache Cache, namespace string, ttl TTLs) Store {
+	return Store{
+		cache:     cache,
+		namespace: namespace,
+		ttl:       ttl,
+		state: &storeState{
+			staleSince: make(map[string]time.Time),
+			refreshing: make(map[string]bool),
+		},
 	}
+}
 
-	var data []mcountry.CountryCurve
-	json.Unmarshal([]byte(s), &data)
+// LastKnownGood returns a Store that reads the last known good
+// copy of the datasets instead of the ones that expire
+func (s Store) LastKnownGood() Store {
+	s.lastKnownGood = true
+	return s
+}
 
-	return data, nil
+// TTL returns the expiration time of each dataset of the Store
+func (s Store) TTL() TTLs {
+	return s.ttl
+}
+
+// Key returns the namespaced key a dataset is stored under
+func (s Store) Key(key string) string {
+	if s.lastKnownGood {
+		return s.namespace + key + lastKnownGoodSuffix
+	}
+	return s.namespace + key
 }
 
-// SetNewsData executes the redis SET command
-func (r RedisST) SetNewsData(newsType string, news mnews.ArticlesData) error {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	vv, _ := json.Marshal(news)
-	_, err := conn.Do("SETEX", newsType, 7200, vv)
+// set stores the JSON encoding of data under key and as its last known
+// good copy, the dataset is not stale anymore
+func (s Store) set(key string, data interface{}, ttl time.Duration) error {
+	out, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
+	if err := s.cache.Set(s.namespace+key, out, ttl); err != nil {
+		return err
+	}
+
+	if err := s.cache.Set(s.namespace+key+lastKnownGoodSuffix, out, 0); err != nil {
+		return err
+	}
+
+	s.state.mu.Lock()
+	delete(s.state.staleSince, key)
+	s.state.mu.Unlock()
+
 	return nil
 }
 
-// GetNewsData executes the redis GET command
-func (r RedisST) GetNewsData(newsType string) (mnews.ArticlesData, bool, error) {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	s, err := redis.String(conn.Do("GET", newsType))
-	if err != nil {
+// MarkStale records that a dataset is served from its last known good copy
+// until it is set again
+func (s Store) MarkStale(key string) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
 
-		return mnews.ArticlesData{}, false, nil
+	if _, exist := s.state.staleSince[key]; !exist {
+		s.state.staleSince[key] = time.Now()
 	}
+}
 
-	var data mnews.ArticlesData
-	json.Unmarshal([]byte(s), &data)
+// StaleSince returns since when a dataset is served from its last known
+// good copy and false if the dataset is not stale
+func (s Store) StaleSince(key string) (time.Time, bool) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
 
-	return data, true, nil
+	since, exist := s.state.staleSince[key]
+	return since, exist
 }
 
-// GetContinentData executes the redis GET command
-func (r RedisST) GetContinentData() (mcontinent.Response, bool, error) {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	s, err := redis.String(conn.Do("GET", "continent"))
-	if err != nil {
+// Revalidate runs refresh for a dataset in the background unless
+// a refresh of the same dataset is already running
+func (s Store) Revalidate(key string, refresh func() error) {
+	s.state.mu.Lock()
+	if s.state.refreshing[key] {
+		s.state.mu.Unlock()
+		return
+	}
+	s.state.refreshing[key] = true
+	s.state.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.state.mu.Lock()
+			delete(s.state.refreshing, key)
+			s.state.mu.Unlock()
+		}()
+
+		if err := refresh(); err != nil {
+			applogger.Log("WARN", "caching", "Revalidate", key+": "+err.Error())
+		}
+	}()
+}
 
-		return mcontinent.Response{}, false, nil
+// get decodes the JSON stored under key into data
+// It returns false if the key does not exist or can not be decoded
+// and any error of the backend
+func (s Store) get(key string, data interface{}) (bool, error) {
+	b, exist, err := s.cache.Get(s.Key(key))
+	if err != nil || !exist {
+		return false, err
 	}
 
-	var data mcontinent.Response
-	json.Unmarshal([]byte(s), &data)
+	if errUnmarshal := json.Unmarshal(b, data); errUnmarshal != nil {
+		applogger.Log("WARN", "caching", "get", key+": "+errUnmarshal.Error())
+		return false, nil
+	}
 
-	return data, true, nil
+	return true, nil
 }
 
-// SetContinetData executes the redis SET command
-func (r RedisST) SetContinetData(ctn mcontinent.Response) error {
+// SetCountriesData caches the stats of all countries
+func (s Store) SetCountriesData(countries mcountry.Countries) error {
+	return s.set(CountriesKey, countries, s.ttl.Countries)
+}
 
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	out, _ := json.Marshal(ctn)
-	_, err := conn.Do("SETEX", "continent", 2500, string(out))
-	if err != nil {
-		return err
+// GetCountriesData gets the cached stats of all countries
+func (s Store) GetCountriesData() (mcountry.Countries, error) {
+	var data mcountry.Countries
+	exist, err := s.get(CountriesKey, &data)
+	if err != nil || !exist {
+		return mcountry.Countries{}, err
 	}
 
-	return nil
+	return data, nil
 }
 
-// GetWorldData executes the redis GET command
-func (r RedisST) GetWorldData() (mworld.WorldTimeline, bool, error) {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	s, err := redis.String(conn.Do("GET", "world"))
-	if err != nil {
-		return mworld.WorldTimeline{}, false, nil
+// SetCurveData caches the timelines of all countries
+func (s Store) SetCurveData(countries []mcountry.CountryCurve) error {
+	return s.set(CurveKey, countries, s.ttl.Curve)
+}
+
+// GetCurveData gets the cached timelines of all countries
+func (s Store) GetCurveData() ([]mcountry.CountryCurve, error) {
+	var data []mcountry.CountryCurve
+	exist, err := s.get(CurveKey, &data)
+	if err != nil || !exist {
+		return []mcountry.CountryCurve{}, err
 	}
 
-	var data mworld.WorldTimeline
-	json.Unmarshal([]byte(s), &data)
+	return data, nil
+}
 
-	return data, true, nil
+// SetNewsData caches the articles of a news type
+func (s Store) SetNewsData(newsType string, news mnews.ArticlesData) error {
+	return s.set(newsType, news, s.ttl.News)
 }
 
-// SetWorldData executes the redis SET command
-func (r RedisST) SetWorldData(ctn mworld.WorldTimeline) error {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	out, _ := json.Marshal(ctn)
-	_, err := conn.Do("SETEX", "world", 2500, string(out))
-	if err != nil {
-		return err
+// GetNewsData gets the cached articles of a news type
+func (s Store) GetNewsData(newsType string) (mnews.ArticlesData, bool, error) {
+	var data mnews.ArticlesData
+	exist, err := s.get(newsType, &data)
+	if err != nil || !exist {
+		return mnews.ArticlesData{}, false, err
 	}
 
-	return nil
+	return data, true, nil
 }
 
-// GetCSSEData executes the redis GET command
-func (r RedisST) GetCSSEData() ([]mcsse.ResponseCountry, error) {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	s, err := redis.String(conn.Do("GET", "csse"))
-	if err != nil {
-		return []mcsse.ResponseCountry{}, nil
+// GetContinentData gets the cached stats of all continents
+func (s Store) GetContinentData() (mcontinent.Response, bool, error) {
+	var data mcontinent.Response
+	exist, err := s.get(ContinentKey, &data)
+	if err != nil || !exist {
+		return mcontinent.Response{}, false, err
 	}
 
-	var data []mcsse.ResponseCountry
-	json.Unmarshal([]byte(s), &data)
+	return data, true, nil
+}
 
-	return data, nil
+// SetContinetData caches the stats of all continents
+func (s Store) SetContinetData(ctn mcontinent.Response) error {
+	return s.set(ContinentKey, ctn, s.ttl.Continent)
 }
 
-// SetCSSEData executes the redis SET command
-func (r RedisST) SetCSSEData(ctn []mcsse.ResponseCountry) error {
-	pool := r.NewPool()
-	conn := pool.Get()
-	defer conn.Close()
-	out, _ := json.Marshal(ctn)
+// GetWorldData gets the cached world timeline
+func (s Store) GetWorldData() (mworld.WorldTimeline, bool, error) {
+	var data mworld.WorldTimeline
+	exist, err := s.get(WorldKey, &data)
+	if err != nil || !exist {
+		return mworld.WorldTimeline{}, false, err
+	}
 
-	_, err := conn.Do("SETEX", "csse", 2500, string(out))
-	if err != nil {
-		return err
+	return data, true, nil
+}
+
+// SetWorldData caches the world timeline
+func (s Store) SetWorldData(ctn mworld.WorldTimeline) error {
+	return s.set(WorldKey, ctn, s.ttl.World)
+}
+
+// GetCSSEData gets the cached CSSE dataset
+func (s Store) GetCSSEData() (mcsse.Dataset, error) {
+	var data mcsse.Dataset
+	exist, err := s.get(CSSEKey, &data)
+	if err != nil || !exist {
+		return mcsse.Dataset{}, err
 	}
 
-	return nil
+	return data, nil
+}
+
+// SetCSSEData caches the CSSE dataset
+func (s Store) SetCSSEData(ctn mcsse.Dataset) error {
+	return s.set(CSSEKey, ctn, s.ttl.CSSE)
 }
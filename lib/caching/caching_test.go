@@ -0,0 +1,59 @@
+package caching
+
+import (
+	"testing"
+	"time"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mnews "github.com/junkd0g/covid/lib/model/news"
+)
+
+func TestNewTTLs(t *testing.T) {
+	ttl := NewTTLs(pconf.CacheTTLConfig{Countries: 60})
+
+	if ttl.Countries != time.Minute {
+		t.Fatalf("Wrong countries ttl %v", ttl.Countries)
+	}
+
+	if ttl.Curve != defaultTTL || ttl.News != newsTTL {
+		t.Fatalf("Expecting default ttls having %v %v", ttl.Curve, ttl.News)
+	}
+}
+
+func TestStoreNamespace(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryCache(10)
+	cache.now = func() time.Time { return now }
+	store := NewStore(cache, "covid:testing:", NewTTLs(pconf.CacheTTLConfig{Countries: 60}))
+
+	countries := mcountry.Countries{Data: []mcountry.Country{{Country: "Greece", Cases: 10}}}
+	if err := store.SetCountriesData(countries); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exist, _ := cache.Get("total"); exist {
+		t.Fatal("Key should be stored in the namespace")
+	}
+
+	ttl, exist, _ := cache.TTL("covid:testing:total")
+	if !exist || ttl != time.Minute {
+		t.Fatalf("Wrong ttl %v of namespaced key", ttl)
+	}
+
+	cached, err := store.GetCountriesData()
+	if err != nil || len(cached.Data) != 1 || cached.Data[0].Country != "Greece" {
+		t.Fatalf("Wrong cached data %v %v", cached, err)
+	}
+
+	other := NewStore(cache, "covid:production:", NewTTLs(pconf.CacheTTLConfig{}))
+	if _, exist, _ := other.GetNewsData("general"); exist {
+		t.Fatal("Namespaces should not share keys")
+	}
+
+	store.SetNewsData("general", mnews.ArticlesData{})
+	ttl, _, _ = cache.TTL("covid:testing:general")
+	if ttl != newsTTL {
+		t.Fatalf("Wrong news ttl %v", ttl)
+	}
+}
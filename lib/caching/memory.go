@@ -0,0 +1,134 @@
+package caching
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// defaultMemorySize is the number of entries a MemoryCache keeps
+// when no size is set in the config
+const defaultMemorySize = 128
+
+// MemoryCache is an in-process Cache that evicts the least recently
+// used key when it is full. It is safe for concurrent use
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache that keeps up to size entries
+func NewMemoryCache(size int) *MemoryCache {
+	if size <= 0 {
+		size = defaultMemorySize
+	}
+
+	return &MemoryCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns a copy of the value of a key that has not expired
+func (m *MemoryCache) Get(key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exist := m.lookup(key)
+	if !exist {
+		return nil, false, nil
+	}
+
+	value := make([]byte, len(entry.value))
+	copy(value, entry.value)
+	return value, true, nil
+}
+
+// Set stores a copy of the value, evicting the least recently used
+// key if the cache is full
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: make([]byte, len(value))}
+	copy(entry.value, value)
+	if ttl > 0 {
+		entry.expires = m.now().Add(ttl)
+	}
+
+	if el, exist := m.entries[key]; exist {
+		el.Value = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.size {
+		m.remove(m.order.Back())
+	}
+
+	return nil
+}
+
+// TTL returns the time left before a key expires
+func (m *MemoryCache) TTL(key string) (time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exist := m.lookup(key)
+	if !exist {
+		return 0, false, nil
+	}
+
+	if entry.expires.IsZero() {
+		return -1, true, nil
+	}
+
+	return entry.expires.Sub(m.now()), true, nil
+}
+
+// Delete removes a key
+func (m *MemoryCache) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, exist := m.entries[key]; exist {
+		m.remove(el)
+	}
+
+	return nil
+}
+
+// lookup returns the entry of a key marking it as recently used,
+// expired entries are removed. The caller must hold the lock
+func (m *MemoryCache) lookup(key string) (*memoryEntry, bool) {
+	el, exist := m.entries[key]
+	if !exist {
+		return nil, false
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		m.remove(el)
+		return nil, false
+	}
+
+	m.order.MoveToFront(el)
+	return entry, true
+}
+
+func (m *MemoryCache) remove(el *list.Element) {
+	m.order.Remove(el)
+	delete(m.entries, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,66 @@
+package caching
+
+import (
+	"testing"
+	"time"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Date(2020, 3, 22, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache(2)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("total", []byte("one"), time.Minute)
+	cache.Set("curve", []byte("two"), 0)
+
+	ttl, exist, _ := cache.TTL("curve")
+	if !exist || ttl >= 0 {
+		t.Fatalf("Key curve should not expire, having ttl %v", ttl)
+	}
+
+	value, exist, _ := cache.Get("total")
+	if !exist || string(value) != "one" {
+		t.Fatalf("Wrong value %s for key total", value)
+	}
+
+	// total was read last so curve is the least recently used key
+	cache.Set("world", []byte("three"), time.Minute)
+	if _, exist, _ := cache.Get("curve"); exist {
+		t.Fatal("Key curve should have been evicted")
+	}
+
+	now = now.Add(30 * time.Second)
+	ttl, _, _ = cache.TTL("total")
+	if ttl != 30*time.Second {
+		t.Fatalf("Wrong ttl %v for key total", ttl)
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, exist, _ := cache.Get("total"); exist {
+		t.Fatal("Key total should have expired")
+	}
+
+	cache.Delete("world")
+	if _, exist, _ := cache.Get("world"); exist {
+		t.Fatal("Key world should have been deleted")
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	conf := pconf.GetAppConfig()
+	conf.Cache.Backend = "memcached"
+	if _, err := NewCache(conf); err == nil {
+		t.Fatal("Expecting an error for an unknown backend")
+	}
+	if _, err := NewStoreFromConfig(conf); err == nil {
+		t.Fatal("Expecting an error for a store of an unknown backend")
+	}
+
+	conf.Cache.Backend = BackendMemory
+	cache, err := NewCache(conf)
+	if _, ok := cache.(*MemoryCache); err != nil || !ok {
+		t.Fatalf("Expecting a MemoryCache having %T %v", cache, err)
+	}
+}
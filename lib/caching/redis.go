@@ -0,0 +1,136 @@
+package caching
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	pconf "github.com/junkd0g/covid/lib/config"
+)
+
+const (
+	defaultRedisTimeout     = 5 * time.Second
+	defaultRedisIdleTimeout = 240 * time.Second
+	// redisCheckAfter is how long a connection can be idle in the
+	// pool before it is checked with a PING when borrowed
+	redisCheckAfter = time.Minute
+)
+
+// RedisCache is a Cache backed by a redis server. It keeps a single
+// pool of connections for its whole lifetime
+type RedisCache struct {
+	pool *redis.Pool
+}
+
+// NewRedisCache creates a RedisCache for the redis server of the config.
+// No connection is made until the cache is used
+func NewRedisCache(conf pconf.RedisConfig) *RedisCache {
+	return &RedisCache{pool: NewPool(conf)}
+}
+
+// NewPool creates a pool of connections to redis using the
+// timeouts of the config, or their defaults when they are not set.
+// Connections that have been idle for a while are checked before
+// they are used and dial errors are returned by the connection
+func NewPool(conf pconf.RedisConfig) *redis.Pool {
+	dialTimeout := durationOrDefault(conf.DialTimeout, defaultRedisTimeout)
+	readTimeout := durationOrDefault(conf.ReadTimeout, defaultRedisTimeout)
+	writeTimeout := durationOrDefault(conf.WriteTimeout, defaultRedisTimeout)
+
+	return &redis.Pool{
+		MaxIdle:     conf.MaxIdle,
+		MaxActive:   conf.MaxActive,
+		IdleTimeout: durationOrDefault(conf.IdleTimeout, defaultRedisIdleTimeout),
+
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", conf.URL,
+				redis.DialConnectTimeout(dialTimeout),
+				redis.DialReadTimeout(readTimeout),
+				redis.DialWriteTimeout(writeTimeout),
+			)
+		},
+
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < redisCheckAfter {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+}
+
+// durationOrDefault converts milliseconds of the config to a duration
+func durationOrDefault(milliseconds int, def time.Duration) time.Duration {
+	if milliseconds <= 0 {
+		return def
+	}
+	return time.Duration(milliseconds) * time.Millisecond
+}
+
+// Close closes the pool of connections
+func (r *RedisCache) Close() error {
+	return r.pool.Close()
+}
+
+// Get executes the redis GET command
+func (r *RedisCache) Get(key string) ([]byte, bool, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set executes the redis SETEX command or SET when the key
+// should not expire
+func (r *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	var err error
+	if ttl <= 0 {
+		_, err = conn.Do("SET", key, value)
+	} else {
+		_, err = conn.Do("PSETEX", key, ttl.Milliseconds(), value)
+	}
+
+	return err
+}
+
+// TTL executes the redis PTTL command
+func (r *RedisCache) TTL(key string) (time.Duration, bool, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	ttl, err := redis.Int64(conn.Do("PTTL", key))
+	if err != nil {
+		return 0, false, err
+	}
+
+	// -2 is returned for a key that does not exist and -1
+	// for a key without an expiration time
+	switch ttl {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return -1, true, nil
+	}
+
+	return time.Duration(ttl) * time.Millisecond, true, nil
+}
+
+// Delete executes the redis DEL command
+func (r *RedisCache) Delete(key string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", key)
+	return err
+}
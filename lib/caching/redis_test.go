@@ -0,0 +1,25 @@
+package caching
+
+import (
+	"testing"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+)
+
+func TestRedisCacheUnreachable(t *testing.T) {
+	cache := NewRedisCache(pconf.RedisConfig{
+		MaxIdle:     1,
+		MaxActive:   1,
+		URL:         "127.0.0.1:1",
+		DialTimeout: 200,
+	})
+	defer cache.Close()
+
+	if _, _, err := cache.Get("total"); err == nil {
+		t.Fatal("Expecting an error from an unreachable redis server")
+	}
+
+	if err := cache.Set("total", []byte("{}"), 0); err == nil {
+		t.Fatal("Expecting an error from an unreachable redis server")
+	}
+}
@@ -29,15 +29,32 @@ import (
 			"uri" 			: "redis://localhost",
 			"port"			: ":6379",
 			"queues" 		: ["myqueue","delimited","queues"]
+		},
+		"cache" : {
+			"backend" : "redis",
+			"size" : 128,
+			"namespace" : "covid:production:",
+			"ttl" : {
+				"countries" : 2500,
+				"curve" : 2500,
+				"world" : 2500,
+				"continent" : 2500,
+				"csse" : 2500,
+				"news" : 7200
+			}
 		}
 	}
 */
 
 //AppConf contains all main structs
 type AppConf struct {
-	Server ServerConfig `json:"server"`
-	API    APIConfig    `json:"API"`
-	Redis  RedisConfig  `json:"redis"`
+	Server    ServerConfig    `json:"server"`
+	API       APIConfig       `json:"API"`
+	Upstream  UpstreamConfig  `json:"upstream"`
+	Redis     RedisConfig     `json:"redis"`
+	Cache     CacheConfig     `json:"cache"`
+	Scheduler SchedulerConfig `json:"scheduler"`
+	Snapshot  SnapshotConfig  `json:"snapshot"`
 }
 
 //APIConfig contains the data for exernal API http calls
@@ -52,6 +69,18 @@ type APIConfig struct {
 	CSSE            string `json:"csse"`
 }
 
+//UpstreamConfig contains the data for the http client of the exernal APIs
+//Timeout, Backoff and MaxBackoff are in milliseconds, a default is used
+//when they are not set. Retries is how many times a request that failed
+//with a 5xx or 429 status code or a network error is retried
+type UpstreamConfig struct {
+	Timeout    int    `json:"timeout"`
+	Retries    int    `json:"retries"`
+	Backoff    int    `json:"backoff"`
+	MaxBackoff int    `json:"max_backoff"`
+	UserAgent  string `json:"user_agent"`
+}
+
 //ServerConfig contains the data for the server like port
 type ServerConfig struct {
 	Port string `json:"port"`
@@ -59,10 +88,71 @@ type ServerConfig struct {
 }
 
 //RedisConfig contains the data for the redis server
+//Timeouts are in milliseconds, a default is used when they are not set
 type RedisConfig struct {
-	MaxIdle   int    `json:"MaxIdle"`
-	MaxActive int    `json:"MaxActive"`
-	URL       string `json:"url"`
+	MaxIdle      int    `json:"MaxIdle"`
+	MaxActive    int    `json:"MaxActive"`
+	URL          string `json:"url"`
+	IdleTimeout  int    `json:"IdleTimeout"`
+	DialTimeout  int    `json:"DialTimeout"`
+	ReadTimeout  int    `json:"ReadTimeout"`
+	WriteTimeout int    `json:"WriteTimeout"`
+}
+
+//CacheConfig contains the data for the cache backend
+//Backend is "redis" (default) or "memory" for an in-process LRU cache
+//and Size is the maximum number of entries the in-process cache keeps.
+//Namespace is prepended to every key so several environments can
+//share a redis server
+type CacheConfig struct {
+	Backend   string         `json:"backend"`
+	Size      int            `json:"size"`
+	Namespace string         `json:"namespace"`
+	TTL       CacheTTLConfig `json:"ttl"`
+}
+
+//CacheTTLConfig contains the expiration time in seconds of each
+//cached dataset, a default is used when one is not set
+type CacheTTLConfig struct {
+	Countries int `json:"countries"`
+	Curve     int `json:"curve"`
+	World     int `json:"world"`
+	Continent int `json:"continent"`
+	CSSE      int `json:"csse"`
+	News      int `json:"news"`
+}
+
+//SchedulerConfig contains the data for the background refresh of the
+//datasets. Intervals, Retry and MaxBackoff are in seconds, Jitter is the
+//fraction of an interval the refresh time randomly moves by
+type SchedulerConfig struct {
+	Enabled    bool                     `json:"enabled"`
+	Jitter     float64                  `json:"jitter"`
+	Retry      int                      `json:"retry"`
+	MaxBackoff int                      `json:"max_backoff"`
+	Intervals  SchedulerIntervalsConfig `json:"intervals"`
+}
+
+//SchedulerIntervalsConfig contains how often in seconds each dataset is
+//refreshed, a dataset with no interval is not refreshed in the background
+type SchedulerIntervalsConfig struct {
+	Countries     int `json:"countries"`
+	Curve         int `json:"curve"`
+	World         int `json:"world"`
+	Continent     int `json:"continent"`
+	CSSE          int `json:"csse"`
+	News          int `json:"news"`
+	VaccineNews   int `json:"vaccine_news"`
+	TreatmentNews int `json:"treatment_news"`
+}
+
+//SnapshotConfig contains the data for the history of the countries' stats
+//Dir is the directory the daily snapshots are stored in, no snapshots are
+//kept when it is not set. Retention is the number of days the snapshots
+//are kept for, all of them are kept when it is not set
+type SnapshotConfig struct {
+	Dir       string `json:"dir"`
+	Retention int    `json:"retention"`
 }
 
 var (
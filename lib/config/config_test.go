@@ -26,10 +26,54 @@ func TestGetAppConfig(t *testing.T) {
 			Continent:       "https://corona.lmao.ninja/v2/continents",
 			CSSE:            "https://corona.lmao.ninja/v2/jhucsse",
 		},
+		Upstream: UpstreamConfig{
+			Timeout:    30000,
+			Retries:    3,
+			Backoff:    500,
+			MaxBackoff: 10000,
+			UserAgent:  "covid-api (+https://github.com/junkd0g/covid)",
+		},
 		Redis: RedisConfig{
-			URL:       "127.0.0.1:6379",
-			MaxActive: 1200,
-			MaxIdle:   80,
+			URL:          "127.0.0.1:6379",
+			MaxActive:    1200,
+			MaxIdle:      80,
+			IdleTimeout:  240000,
+			DialTimeout:  5000,
+			ReadTimeout:  5000,
+			WriteTimeout: 5000,
+		},
+		Cache: CacheConfig{
+			Backend:   "memory",
+			Size:      128,
+			Namespace: "covid:testing:",
+			TTL: CacheTTLConfig{
+				Countries: 2500,
+				Curve:     2500,
+				World:     2500,
+				Continent: 2500,
+				CSSE:      2500,
+				News:      7200,
+			},
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:    false,
+			Jitter:     0.1,
+			Retry:      30,
+			MaxBackoff: 600,
+			Intervals: SchedulerIntervalsConfig{
+				Countries:     1800,
+				Curve:         1800,
+				World:         1800,
+				Continent:     1800,
+				CSSE:          1800,
+				News:          3600,
+				VaccineNews:   3600,
+				TreatmentNews: 3600,
+			},
+		},
+		Snapshot: SnapshotConfig{
+			Dir:       "",
+			Retention: 90,
 		},
 	}
 
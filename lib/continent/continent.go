@@ -1,20 +1,24 @@
 package continent
 
 import (
+	"bytes"
 	"fmt"
-	"net/http"
+	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
 	caching "github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
 	mcontinent "github.com/junkd0g/covid/lib/model/continent"
+	singleflight "github.com/junkd0g/covid/lib/singleflight"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 )
 
 var (
 	serverConf      pconf.AppConf
 	reqDataOB       requestAPI
 	reqCacheOB      requestCache
-	redis           caching.RedisST
+	cache           = caching.NewMemoryStore()
+	fetches         singleflight.Group
 	continentObject mcontinent.ContinentOB
 )
 
@@ -24,6 +28,12 @@ func init() {
 	reqCacheOB = requestCacheData{}
 }
 
+// SetCache sets the Store the continent data are cached in, an in-process
+// store is used until it is set
+func SetCache(store caching.Store) {
+	cache = store
+}
+
 type requestData struct{}
 type requestAPI interface {
 	requestContinentData() (mcontinent.Response, error)
@@ -32,34 +42,24 @@ type requestAPI interface {
 type requestCacheData struct{}
 type requestCache interface {
 	getCacheData() (mcontinent.Response, error)
+	getStaleCacheData() (mcontinent.Response, error)
 	setCacheData(ctn mcontinent.Response) error
 }
 
 func (r requestCacheData) setCacheData(ctn mcontinent.Response) error {
-	err := redis.SetContinetData(ctn)
+	err := cache.SetContinetData(ctn)
 	return err
 }
 
 //requestContinentData does a GET http request to serverConf.API.Continent value ( https://corona.lmao.ninja​/v2/continents )
 func (r requestData) requestContinentData() (mcontinent.Response, error) {
-	client := &http.Client{}
-	requestURL := serverConf.API.Continent
-
-	req, reqErr := http.NewRequest("GET", requestURL, nil)
-	if reqErr != nil {
-		applogger.Log("ERROR", "continent", "requestContinentData", reqErr.Error())
-		return mcontinent.Response{}, reqErr
+	b, err := upstream.Default.Get(serverConf.API.Continent)
+	if err != nil {
+		applogger.Log("ERROR", "continent", "requestContinentData", err.Error())
+		return mcontinent.Response{}, err
 	}
-	res, resError := client.Do(req)
-	fmt.Println(res.Body)
 
-	if resError != nil {
-		applogger.Log("ERROR", "continent", "requestContinentData", resError.Error())
-		return mcontinent.Response{}, resError
-	}
-	defer res.Body.Close()
-
-	responseData, errUnmarshal := continentObject.UnmarshalContintent(res.Body)
+	responseData, errUnmarshal := continentObject.UnmarshalContintent(bytes.NewReader(b))
 	if errUnmarshal != nil {
 		applogger.Log("ERROR", "continent", "requestContinentData", errUnmarshal.Error())
 		return mcontinent.Response{}, errUnmarshal
@@ -69,29 +69,81 @@ func (r requestData) requestContinentData() (mcontinent.Response, error) {
 }
 
 func (r requestCacheData) getCacheData() (mcontinent.Response, error) {
-	cachedData, _, cacheGetError := redis.GetContinentData()
+	cachedData, _, cacheGetError := cache.GetContinentData()
+	return cachedData, cacheGetError
+}
+
+func (r requestCacheData) getStaleCacheData() (mcontinent.Response, error) {
+	cachedData, _, cacheGetError := cache.LastKnownGood().GetContinentData()
 	return cachedData, cacheGetError
 }
 
+// refresh requests the continent data from the 3rd party API and caches them.
+// Concurrent refreshes share one request and one cache write
+func refresh() (mcontinent.Response, error) {
+	data, err, _ := fetches.Do(caching.ContinentKey, func() (interface{}, error) {
+		data, err := reqDataOB.requestContinentData()
+		if err != nil {
+			return nil, err
+		}
+		// the data are returned with the error of a failed cache write,
+		// they are still served but Refresh reports the error
+		return data, reqCacheOB.setCacheData(data)
+	})
+	if err != nil {
+		applogger.Log("ERROR", "continent", "refresh", err.Error())
+		if data == nil {
+			return mcontinent.Response{}, err
+		}
+	}
+	refreshed, ok := data.(mcontinent.Response)
+	if !ok {
+		errResult := fmt.Errorf("unexpected refresh result %T", data)
+		applogger.Log("ERROR", "continent", "refresh", errResult.Error())
+		return mcontinent.Response{}, errResult
+	}
+	return refreshed, err
+}
+
 // GetContinentData checks if continent data are on redis and return them
-// else it request them using requestContinentData
+// else it serves their last known good copy while refreshing them in the
+// background, or request them using requestContinentData if there is no copy
 func GetContinentData() (mcontinent.Response, error) {
 	cachedData, cacheGetError := reqCacheOB.getCacheData()
 	if cacheGetError != nil {
 		applogger.Log("ERROR", "continent", "GetContinentData", cacheGetError.Error())
-		return mcontinent.Response{}, cacheGetError
+	} else if len(cachedData) != 0 {
+		return cachedData, nil
 	}
 
-	if len(cachedData) == 0 {
-		applogger.Log("INFO", "continent", "GetContinentData", "Request data instead of getting cached data")
-		data, err := reqDataOB.requestContinentData()
-		if err != nil {
-			applogger.Log("ERROR", "continent", "GetContinentData", err.Error())
-			return mcontinent.Response{}, err
-		}
-		reqCacheOB.setCacheData(data)
-		return data, nil
+	staleData, staleGetError := reqCacheOB.getStaleCacheData()
+	if staleGetError == nil && len(staleData) != 0 {
+		applogger.Log("WARN", "continent", "GetContinentData", "Serving last known good data while refreshing it")
+		cache.MarkStale(caching.ContinentKey)
+		cache.Revalidate(caching.ContinentKey, func() error {
+			_, err := refresh()
+			return err
+		})
+		return staleData, nil
 	}
 
-	return cachedData, nil
+	applogger.Log("INFO", "continent", "GetContinentData", "Request data instead of getting cached data")
+	data, err := refresh()
+	if err != nil && len(data) == 0 {
+		return mcontinent.Response{}, err
+	}
+	return data, nil
+}
+
+// Refresh requests the continent data from the 3rd party API and caches them
+// whether they have expired or not
+func Refresh() error {
+	_, err := refresh()
+	return err
+}
+
+// Stale returns since when the continent data are served from their
+// last known good copy and false if they are not stale
+func Stale() (time.Time, bool) {
+	return cache.StaleSince(caching.ContinentKey)
 }
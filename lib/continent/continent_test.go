@@ -22,6 +22,14 @@ var setCacheDataMockFunc func(ctn mcontinent.Response) error
 func (u requestCacheDataMock) getCacheData() (mcontinent.Response, error) {
 	return requestCacheDataMockFunc()
 }
+
+var requestStaleCacheDataMockFunc = func() (mcontinent.Response, error) {
+	return mcontinent.Response{}, nil
+}
+
+func (u requestCacheDataMock) getStaleCacheData() (mcontinent.Response, error) {
+	return requestStaleCacheDataMockFunc()
+}
 func (u requestCacheDataMock) setCacheData(ctn mcontinent.Response) error {
 	return setCacheDataMockFunc(ctn)
 }
@@ -1,21 +1,35 @@
 package csse
 
 import (
+	"bytes"
 	"fmt"
-	"net/http"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
 	caching "github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
 	mcsse "github.com/junkd0g/covid/lib/model/csse"
+	registry "github.com/junkd0g/covid/lib/registry"
+	singleflight "github.com/junkd0g/covid/lib/singleflight"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 )
 
 var (
 	serverConf pconf.AppConf
 	reqDataOB  requestAPI
 	reqCacheOB requestCache
-	redis      caching.RedisST
+	cache      = caching.NewMemoryStore()
+	fetches    singleflight.Group
 	csseObject mcsse.CSSEOB
+	indexed    indexedDataset
+
+	// ErrCountryNotFound is returned when there are no data for a country,
+	// it is registry.ErrNotFound so the error has the suggestions of the registry
+	ErrCountryNotFound = registry.ErrNotFound
 )
 
 func init() {
@@ -24,6 +38,43 @@ func init() {
 	reqCacheOB = requestCacheData{}
 }
 
+// SetCache sets the Store the csse data are cached in, an in-process
+// store is used until it is set
+func SetCache(store caching.Store) {
+	cache = store
+}
+
+// indexedDataset is the csse dataset decoded and sorted in process, so it
+// is not decoded from the cache for every request. It is replaced when the
+// dataset is refreshed or read again from the cache once it has expired
+type indexedDataset struct {
+	mu      sync.RWMutex
+	dataset mcsse.Dataset
+}
+
+// get returns the dataset and whether it has not expired yet, it expires
+// with its cached copy
+func (i *indexedDataset) get() (mcsse.Dataset, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	fresh := len(i.dataset.Countries) != 0 && time.Since(i.dataset.UpdatedAt) < cache.TTL().CSSE
+	return i.dataset, fresh
+}
+
+// set replaces the dataset with one that has been built or decoded,
+// sorting it if it has not been sorted yet
+func (i *indexedDataset) set(dataset mcsse.Dataset) mcsse.Dataset {
+	if len(dataset.Names) != len(dataset.Countries) {
+		dataset.Sort()
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.dataset = dataset
+	return dataset
+}
+
 type requestData struct{}
 
 type requestAPI interface {
@@ -32,29 +83,20 @@ type requestAPI interface {
 
 type requestCacheData struct{}
 type requestCache interface {
-	getCacheData() ([]mcsse.ResponseCountry, error)
-	setCacheData(ctn []mcsse.ResponseCountry) error
+	getCacheData() (mcsse.Dataset, error)
+	getStaleCacheData() (mcsse.Dataset, error)
+	setCacheData(ctn mcsse.Dataset) error
 }
 
 //requestCSSEData request csse data from external api
 func (r requestData) requestCSSEData() ([]mcsse.ResponseCountry, error) {
-	client := &http.Client{}
-	requestURL := serverConf.API.CSSE
-
-	req, reqErr := http.NewRequest("GET", requestURL, nil)
-	if reqErr != nil {
-		applogger.Log("ERROR", "csse", "requestCSSEData", reqErr.Error())
-		return []mcsse.ResponseCountry{}, reqErr
-	}
-
-	res, resError := client.Do(req)
-	if resError != nil {
-		applogger.Log("ERROR", "csse", "requestCSSEData", resError.Error())
-		return []mcsse.ResponseCountry{}, resError
+	b, err := upstream.Default.Get(serverConf.API.CSSE)
+	if err != nil {
+		applogger.Log("ERROR", "csse", "requestCSSEData", err.Error())
+		return []mcsse.ResponseCountry{}, err
 	}
-	defer res.Body.Close()
 
-	responseData, errUnmarshal := csseObject.UnmarshalCSSE(res.Body)
+	responseData, errUnmarshal := csseObject.UnmarshalCSSE(bytes.NewReader(b))
 	if errUnmarshal != nil {
 		applogger.Log("ERROR", "csse", "requestCSSEData", errUnmarshal.Error())
 		return []mcsse.ResponseCountry{}, errUnmarshal
@@ -63,113 +105,286 @@ func (r requestData) requestCSSEData() ([]mcsse.ResponseCountry, error) {
 	return responseData, nil
 }
 
-// getCacheData get data from redis for csse key
-func (r requestCacheData) getCacheData() ([]mcsse.ResponseCountry, error) {
-	cachedData, cacheGetError := redis.GetCSSEData()
+// getCacheData get the csse dataset from redis for csse key
+func (r requestCacheData) getCacheData() (mcsse.Dataset, error) {
+	cachedData, cacheGetError := cache.GetCSSEData()
 	return cachedData, cacheGetError
 }
 
-func (r requestCacheData) setCacheData(ctn []mcsse.ResponseCountry) error {
-	err := redis.SetCSSEData(ctn)
+// getStaleCacheData get the last known good copy of the csse dataset
+func (r requestCacheData) getStaleCacheData() (mcsse.Dataset, error) {
+	cachedData, cacheGetError := cache.LastKnownGood().GetCSSEData()
+	return cachedData, cacheGetError
+}
+
+func (r requestCacheData) setCacheData(ctn mcsse.Dataset) error {
+	err := cache.SetCSSEData(ctn)
 	return err
 }
 
-// GetCSSEData checks if continent data are on redis and return them
-// else it request them using requestContinentData
-func GetCSSEData() (mcsse.CSSEResponse, error) {
+// refresh requests the csse data from the 3rd party API, indexes them
+// and caches the dataset. Concurrent refreshes share one request and
+// one cache write
+func refresh() (mcsse.Dataset, error) {
+	data, err, _ := fetches.Do(caching.CSSEKey, func() (interface{}, error) {
+		rows, err := reqDataOB.requestCSSEData()
+		if err != nil {
+			return nil, err
+		}
+		dataset := indexed.set(index(rows))
+		// the data are returned with the error of a failed cache write,
+		// they are still served but Refresh reports the error
+		return dataset, reqCacheOB.setCacheData(dataset)
+	})
+	if err != nil {
+		applogger.Log("ERROR", "csse", "refresh", err.Error())
+		if data == nil {
+			return mcsse.Dataset{}, err
+		}
+	}
+	refreshed, ok := data.(mcsse.Dataset)
+	if !ok {
+		errResult := fmt.Errorf("unexpected refresh result %T", data)
+		applogger.Log("ERROR", "csse", "refresh", errResult.Error())
+		return mcsse.Dataset{}, errResult
+	}
+	return refreshed, err
+}
+
+// GetCSSEDataset returns the csse dataset decoded in process until it
+// expires, then checks if the csse dataset is on redis and return it
+// else it serves its last known good copy while refreshing it in the
+// background, or request the data using requestCSSEData if there is no copy
+func GetCSSEDataset() (mcsse.Dataset, error) {
+	current, fresh := indexed.get()
+	if fresh {
+		return current, nil
+	}
+
 	data, dataErr := reqCacheOB.getCacheData()
 	if dataErr != nil {
-		applogger.Log("ERROR", "csse", "GetCSSEData", dataErr.Error())
-		return mcsse.CSSEResponse{}, dataErr
+		applogger.Log("ERROR", "csse", "GetCSSEDataset", dataErr.Error())
+	} else if len(data.Countries) != 0 {
+		return indexed.set(data), nil
 	}
 
-	if len(data) == 0 {
-		applogger.Log("INFO", "csse", "GetCSSEData", "Request data instead of getting cached data")
-		data, dataErr = reqDataOB.requestCSSEData()
-		if dataErr != nil {
-			applogger.Log("ERROR", "csse", "GetCSSEData", dataErr.Error())
-			return mcsse.CSSEResponse{}, dataErr
+	// the expired dataset of the process is the last known good copy
+	// when there is one, so it is not decoded again until it is refreshed
+	staleData := current
+	var staleErr error
+	if len(staleData.Countries) == 0 {
+		staleData, staleErr = reqCacheOB.getStaleCacheData()
+		if staleErr == nil && len(staleData.Countries) != 0 {
+			staleData = indexed.set(staleData)
 		}
-		reqCacheOB.setCacheData(data)
 	}
-	var countries []mcsse.CSEECountryResponse
 
-	for _, k := range data {
-		countries = insertProvince(countries, k)
+	if staleErr == nil && len(staleData.Countries) != 0 {
+		applogger.Log("WARN", "csse", "GetCSSEDataset", "Serving last known good data while refreshing it")
+		cache.MarkStale(caching.CSSEKey)
+		cache.Revalidate(caching.CSSEKey, func() error {
+			_, err := refresh()
+			return err
+		})
+		return staleData, nil
+	}
+
+	applogger.Log("INFO", "csse", "GetCSSEDataset", "Request data instead of getting cached data")
+	data, dataErr = refresh()
+	if dataErr != nil && len(data.Countries) == 0 {
+		return mcsse.Dataset{}, dataErr
+	}
+	return data, nil
+}
+
+// GetCSSEData returns the csse data of every country ordered by name
+func GetCSSEData() (mcsse.CSSEResponse, error) {
+	dataset, err := GetCSSEDataset()
+	if err != nil {
+		return mcsse.CSSEResponse{}, err
+	}
+
+	countries := make([]mcsse.CSEECountryResponse, 0, len(dataset.Countries))
+	for _, name := range dataset.Names {
+		countries = append(countries, countryResponse(dataset.Countries[name]))
 	}
 
 	return mcsse.CSSEResponse{Data: countries}, nil
 }
 
-// GetCSSECountryData returns csse data for a specific country
-func GetCSSECountryData(country string) (mcsse.CSEECountryResponse, error) {
-	country = getCountriesName(country)
+// GetCSSESummary returns the totals of every country ordered by name and
+// of all of them
+func GetCSSESummary() (mcsse.Summary, error) {
+	dataset, err := GetCSSEDataset()
+	if err != nil {
+		return mcsse.Summary{}, err
+	}
+
+	summary := mcsse.Summary{Totals: dataset.Totals, Countries: make([]mcsse.CountryTotals, 0, len(dataset.Countries))}
+	for _, name := range dataset.Names {
+		c := dataset.Countries[name]
+		provinces := len(c.Provinces)
+		if _, exist := c.Provinces[""]; exist {
+			provinces--
+		}
+		summary.Countries = append(summary.Countries, mcsse.CountryTotals{Country: c.Country, Provinces: provinces, Totals: c.Totals})
+	}
+
+	return summary, nil
+}
+
+// Refresh requests the csse data from the 3rd party API and caches them
+// whether they have expired or not
+func Refresh() error {
+	_, err := refresh()
+	return err
+}
 
-	countriesData, err := GetCSSEData()
+// Stale returns since when the csse data are served from their
+// last known good copy and false if they are not stale
+func Stale() (time.Time, bool) {
+	return cache.StaleSince(caching.CSSEKey)
+}
+
+// GetCSSECountryData returns csse data for a specific country, the
+// country can be given by its name, ISO code or alias
+// It returns a *registry.NotFoundError if there are no data for the country
+func GetCSSECountryData(country string) (mcsse.CSEECountryResponse, error) {
+	dataset, err := GetCSSEDataset()
 	if err != nil {
 		applogger.Log("ERROR", "csse", "GetCSSECountryData", err.Error())
 		return mcsse.CSEECountryResponse{}, err
 	}
 
-	for _, v := range countriesData.Data {
-		if v.Country == country {
-			return v, nil
-		}
+	if c, exist := dataset.Countries[country]; exist {
+		return countryResponse(c), nil
+	}
+
+	resolved, resolveErr := registry.Resolve(country, dataset.Names)
+	if resolveErr != nil {
+		applogger.Log("ERROR", "csse", "GetCSSECountryData", resolveErr.Error())
+		return mcsse.CSEECountryResponse{}, resolveErr
 	}
-	return mcsse.CSEECountryResponse{}, nil
+
+	return countryResponse(dataset.Countries[resolved]), nil
 }
 
-// insertProvince normalise response data from having a one to
-// one releastion between country and provision to one country
-// having multiple provision (basically pu them into an array)
-func insertProvince(arr []mcsse.CSEECountryResponse, element mcsse.ResponseCountry) []mcsse.CSEECountryResponse {
-	for i := 0; i < len(arr); i++ {
-		if arr[i].Country == element.Country {
-			var cp mcsse.CSEEProvision
-			cp.Cases = element.Stats.Confirmed
-			cp.Deaths = element.Stats.Deaths
-			cp.Recovered = element.Stats.Recovered
-			cp.County = fmt.Sprintf("%v", element.County)
-			cp.Province = element.Province
-			arr[i].Data = append(arr[i].Data, cp)
+// index groups the rows of the third party API by country, province and
+// county and sums their totals. The stats of rows of the same county
+// are added up
+func index(rows []mcsse.ResponseCountry) mcsse.Dataset {
+	dataset := mcsse.Dataset{Countries: make(map[string]*mcsse.CountryIndex)}
+	for _, row := range rows {
+		cp := provision(row)
 
-			return arr
+		country, exist := dataset.Countries[row.Country]
+		if !exist {
+			country = &mcsse.CountryIndex{Country: row.Country, Provinces: make(map[string]*mcsse.ProvinceIndex)}
+			dataset.Countries[row.Country] = country
 		}
+
+		province, exist := country.Provinces[row.Province]
+		if !exist {
+			province = &mcsse.ProvinceIndex{Province: row.Province, Counties: make(map[string]mcsse.CSEEProvision)}
+			country.Provinces[row.Province] = province
+		}
+
+		dataset.Totals.Add(cp)
+		country.Totals.Add(cp)
+		province.Totals.Add(cp)
+
+		county := ""
+		if cp.County != nil {
+			county = *cp.County
+		}
+		if existing, exist := province.Counties[county]; exist {
+			existing.Cases += cp.Cases
+			existing.Deaths += cp.Deaths
+			existing.Recovered += cp.Recovered
+			cp = existing
+		}
+		province.Counties[county] = cp
+	}
+
+	dataset.UpdatedAt = time.Now()
+	dataset.Sort()
+	return dataset
+}
+
+// countryResponse returns the counties and the provinces of a country
+// ordered by province and county with the totals of each province
+func countryResponse(c *mcsse.CountryIndex) mcsse.CSEECountryResponse {
+	response := mcsse.CSEECountryResponse{
+		Country:   c.Country,
+		Totals:    c.Totals,
+		Provinces: make(map[string]mcsse.Totals, len(c.Provinces)),
+		Data:      []mcsse.CSEEProvision{},
 	}
 
-	var newC mcsse.CSEECountryResponse
-	newC.Country = element.Country
+	for _, name := range c.ProvinceNames {
+		province := c.Provinces[name]
+		if name != "" {
+			response.Provinces[name] = province.Totals
+		}
 
-	var cp mcsse.CSEEProvision
-	cp.Cases = element.Stats.Confirmed
-	cp.Deaths = element.Stats.Deaths
-	cp.Recovered = element.Stats.Recovered
-	cp.County = fmt.Sprintf("%v", element.County)
-	cp.Province = element.Province
+		for _, county := range province.CountyNames {
+			response.Data = append(response.Data, province.Counties[county])
+		}
+	}
 
-	var cpArr []mcsse.CSEEProvision
-	cpArr = append(cpArr, cp)
-	newC.Data = cpArr
-	arr = append(arr, newC)
-	return arr
+	return response
 }
 
-//getCountriesName converts some country's name to the values we are using across the API
-func getCountriesName(country string) string {
+// provision converts a row of the third party API to a CSEEProvision,
+// the coordinates and the update time are nil when they can not be parsed
+func provision(element mcsse.ResponseCountry) mcsse.CSEEProvision {
+	cp := mcsse.CSEEProvision{
+		County:    element.County,
+		Province:  element.Province,
+		Cases:     element.Stats.Confirmed,
+		Deaths:    element.Stats.Deaths,
+		Recovered: element.Stats.Recovered,
+	}
 
-	countriesMap := make(map[string]string)
-	countriesMap["Bosnia"] = "Bosnia and Herzegovina"
-	countriesMap["Côte d'Ivoire"] = "Cote d'Ivoire"
-	countriesMap["Holy See (Vatican City State)"] = "Holy See"
-	countriesMap["S.Korea"] = "Korea, South"
-	countriesMap["Lao People's Democratic Republic"] = "Laos"
-	countriesMap["Macedonia"] = "North Macedonia"
-	countriesMap["USA"] = "US"
-	countriesMap["UAE"] = "United Arab Emirates"
-	countriesMap["UK"] = "United Kingdom"
+	latitude, latitudeErr := strconv.ParseFloat(strings.TrimSpace(element.Coordinates.Latitude), 64)
+	longitude, longitudeErr := strconv.ParseFloat(strings.TrimSpace(element.Coordinates.Longitude), 64)
+	if latitudeErr == nil && longitudeErr == nil && math.Abs(latitude) <= 90 && math.Abs(longitude) <= 180 {
+		cp.Coordinates = &mcsse.Coordinates{Latitude: latitude, Longitude: longitude}
+	}
 
-	if countriesMap[country] != "" {
-		return countriesMap[country]
+	if updatedAt, err := time.Parse(mcsse.UpdatedAtLayout, element.UpdatedAt); err == nil {
+		cp.UpdatedAt = &updatedAt
 	}
-	return country
+
+	return cp
+}
+
+// GeoJSON returns the counties and the provinces of a country that have
+// coordinates as a GeoJSON FeatureCollection of points
+func GeoJSON(country mcsse.CSEECountryResponse) mcsse.FeatureCollection {
+	collection := mcsse.FeatureCollection{Type: "FeatureCollection", Features: []mcsse.Feature{}}
+	for _, v := range country.Data {
+		if v.Coordinates == nil {
+			continue
+		}
+
+		collection.Features = append(collection.Features, mcsse.Feature{
+			Type: "Feature",
+			Geometry: mcsse.Geometry{
+				Type:        "Point",
+				Coordinates: [2]float64{v.Coordinates.Longitude, v.Coordinates.Latitude},
+			},
+			Properties: mcsse.FeatureProperties{
+				Country:   country.Country,
+				Province:  v.Province,
+				County:    v.County,
+				Cases:     v.Cases,
+				Deaths:    v.Deaths,
+				Recovered: v.Recovered,
+				UpdatedAt: v.UpdatedAt,
+			},
+		})
+	}
+
+	return collection
 }
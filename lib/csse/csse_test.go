@@ -2,41 +2,77 @@ package csse
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	mcsse "github.com/junkd0g/covid/lib/model/csse"
+	registry "github.com/junkd0g/covid/lib/registry"
 )
 
-func TestInsertProvince(t *testing.T) {
-	jsonFile, _ := os.Open("../../../test/files/csse_t1.json")
+func TestIndex(t *testing.T) {
+	jsonFile, _ := os.Open("../../test/files/csse_t2re.json")
 	byteValue, _ := ioutil.ReadAll(jsonFile)
 
 	var sc []mcsse.ResponseCountry
 	json.Unmarshal(byteValue, &sc)
-	var countries []mcsse.CSEECountryResponse
+	if len(sc) == 0 {
+		t.Fatal("No csse rows")
+	}
 
-	for _, k := range sc {
-		countries = insertProvince(countries, k)
+	dataset := index(sc)
+	if len(dataset.Countries) != 3 {
+		t.Fatalf("Expecting 3 countries having %d", len(dataset.Countries))
 	}
 
-	for _, v := range countries {
-		if v.Country == "Italy" {
-			if len(v.Data) != 1 {
-				t.Fatalf("Ammount of data for Italy having %d", len(v.Data))
-			}
+	russia := dataset.Countries["Russia"]
+	if russia == nil || len(russia.Provinces) != 3 {
+		t.Fatalf("Ammount of provinces for Russia having %v", russia)
+	}
 
-			if v.Data[0].Province != "Abruzzo" {
-				t.Fatalf("Italy's province data was wrong wanted Abruzzo but having %s", v.Data[0].Province)
-			}
-		}
+	var cases int
+	for _, row := range sc {
+		cases += row.Stats.Confirmed
+	}
+	if dataset.Totals.Cases != cases {
+		t.Fatalf("Expecting %d total cases having %d", cases, dataset.Totals.Cases)
+	}
 
-		if v.Country == "Russia" {
-			if len(v.Data) != 3 {
-				t.Fatalf("Ammount of data for Russia having %d", len(v.Data))
-			}
-		}
+	abruzzo := dataset.Countries["Italy"].Provinces["Abruzzo"]
+	if abruzzo == nil || abruzzo.Totals.Cases != 3283 || abruzzo.Counties[""].Cases != 3283 {
+		t.Fatalf("Wrong province of Italy %+v", abruzzo)
+	}
+}
+
+func TestIndexCounties(t *testing.T) {
+	var rows []mcsse.ResponseCountry
+	json.Unmarshal([]byte(`[
+		{"country": "US", "county": "Abbeville", "stats": {"confirmed": 60, "deaths": 9}, "province": "South Carolina"},
+		{"country": "US", "county": "Aiken", "stats": {"confirmed": 40, "deaths": 1}, "province": "South Carolina"},
+		{"country": "US", "county": "Aiken", "stats": {"confirmed": 2, "deaths": 0}, "province": "South Carolina"},
+		{"country": "US", "county": "Acadia", "stats": {"confirmed": 80, "deaths": 3}, "province": "Louisiana"},
+		{"country": "US", "county": null, "stats": {"confirmed": 49, "deaths": 0}, "province": "Diamond Princess"}
+	]`), &rows)
+
+	dataset := index(rows)
+	us := dataset.Countries["US"]
+	if us.Totals.Cases != 231 || us.Totals.Deaths != 13 || dataset.Totals != us.Totals {
+		t.Fatalf("Wrong totals of US %+v", us.Totals)
+	}
+
+	southCarolina := us.Provinces["South Carolina"]
+	if southCarolina.Totals.Cases != 102 || len(southCarolina.Counties) != 2 || southCarolina.Counties["Aiken"].Cases != 42 {
+		t.Fatalf("Wrong counties of South Carolina %+v", southCarolina)
+	}
+
+	response := countryResponse(us)
+	if len(response.Data) != 4 || response.Data[0].Province != "Diamond Princess" || *response.Data[2].County != "Abbeville" {
+		t.Fatalf("Expecting the counties ordered by province and county having %+v", response.Data)
+	}
+	if response.Totals.Cases != 231 || response.Provinces["Louisiana"].Cases != 80 || len(response.Provinces) != 3 {
+		t.Fatalf("Wrong totals %+v %+v", response.Totals, response.Provinces)
 	}
 }
 
@@ -50,20 +86,36 @@ func (u requestDataMock) requestCSSEData() ([]mcsse.ResponseCountry, error) {
 
 type requestCacheDataMock struct{}
 
-var requestCacheDataMockFunc func() ([]mcsse.ResponseCountry, error)
-var setCacheDataMockFunc func(ctn []mcsse.ResponseCountry) error
+var requestCacheDataMockFunc func() (mcsse.Dataset, error)
+var setCacheDataMockFunc func(ctn mcsse.Dataset) error
 
-func (u requestCacheDataMock) getCacheData() ([]mcsse.ResponseCountry, error) {
+func (u requestCacheDataMock) getCacheData() (mcsse.Dataset, error) {
 	return requestCacheDataMockFunc()
 }
 
-func (u requestCacheDataMock) setCacheData(ctn []mcsse.ResponseCountry) error {
+var requestStaleCacheDataMockFunc = func() (mcsse.Dataset, error) {
+	return mcsse.Dataset{}, nil
+}
+
+func (u requestCacheDataMock) getStaleCacheData() (mcsse.Dataset, error) {
+	return requestStaleCacheDataMockFunc()
+}
+
+func (u requestCacheDataMock) setCacheData(ctn mcsse.Dataset) error {
 	return setCacheDataMockFunc(ctn)
 }
 
+// reset removes the dataset of the process so the mocks are used
+func (i *indexedDataset) reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.dataset = mcsse.Dataset{}
+}
+
 func TestCSSEData(t *testing.T) {
 	reqCacheOB = requestCacheDataMock{}
 	reqDataOB = requestDataMock{}
+	indexed.reset()
 
 	jsonFile, _ := os.Open("../../../test/files/csse_t2re.json")
 	byteValue, _ := ioutil.ReadAll(jsonFile)
@@ -75,12 +127,12 @@ func TestCSSEData(t *testing.T) {
 		return sc, nil
 	}
 
-	setCacheDataMockFunc = func(ctn []mcsse.ResponseCountry) error {
+	setCacheDataMockFunc = func(ctn mcsse.Dataset) error {
 		return nil
 	}
 
-	requestCacheDataMockFunc = func() ([]mcsse.ResponseCountry, error) {
-		return []mcsse.ResponseCountry{}, nil
+	requestCacheDataMockFunc = func() (mcsse.Dataset, error) {
+		return mcsse.Dataset{}, nil
 	}
 
 	withNoCashedData, err := GetCSSEData()
@@ -139,6 +191,7 @@ func TestCSSEData(t *testing.T) {
 func TestCSSECountryData(t *testing.T) {
 	reqCacheOB = requestCacheDataMock{}
 	reqDataOB = requestDataMock{}
+	indexed.reset()
 
 	jsonFile, _ := os.Open("../../test/files/csse_t2re.json")
 	byteValue, _ := ioutil.ReadAll(jsonFile)
@@ -150,8 +203,8 @@ func TestCSSECountryData(t *testing.T) {
 		return sc, nil
 	}
 
-	requestCacheDataMockFunc = func() ([]mcsse.ResponseCountry, error) {
-		return []mcsse.ResponseCountry{}, nil
+	requestCacheDataMockFunc = func() (mcsse.Dataset, error) {
+		return mcsse.Dataset{}, nil
 	}
 
 	withNoCashedData, err := GetCSSECountryData("Russia")
@@ -167,14 +220,181 @@ func TestCSSECountryData(t *testing.T) {
 	}
 }
 
-func TestGetCountriesName(t *testing.T) {
-	v1 := getCountriesName("USA")
-	expecetedV1 := "US"
-	if v1 != expecetedV1 {
-		t.Fatalf("Wrong value for %s which should be coverted to %s", v1, expecetedV1)
+func TestCSSECountryDataAliases(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	indexed.reset()
+
+	jsonFile, _ := os.Open("../../test/files/csse_t2re.json")
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var sc []mcsse.ResponseCountry
+	json.Unmarshal(byteValue, &sc)
+
+	requestDataMockFunc = func() ([]mcsse.ResponseCountry, error) {
+		return sc, nil
+	}
+
+	requestCacheDataMockFunc = func() (mcsse.Dataset, error) {
+		return mcsse.Dataset{}, nil
+	}
+
+	for _, name := range []string{"RU", "rus", "Russian Federation", "russia"} {
+		data, err := GetCSSECountryData(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.Country != "Russia" {
+			t.Fatalf("Wrong country data for %s needed Russia but having %s", name, data.Country)
+		}
+	}
+
+	if _, err := GetCSSECountryData("Greece"); !errors.Is(err, ErrCountryNotFound) {
+		t.Fatalf("Expecting not found for Greece but having %v", err)
+	}
+
+	var notFound *registry.NotFoundError
+	if _, err := GetCSSECountryData("Rusia"); err != nil {
+		t.Fatalf("Expecting Russia for a typo but having %v", err)
+	}
+	if _, err := GetCSSECountryData("Itl"); !errors.As(err, &notFound) || len(notFound.Suggestions) == 0 || notFound.Suggestions[0] != "Italy" {
+		t.Fatalf("Expecting the suggestion Italy but having %v", err)
+	}
+}
+
+func TestProvision(t *testing.T) {
+	var rows []mcsse.ResponseCountry
+	json.Unmarshal([]byte(`[
+		{"country": "US", "county": "Abbeville", "updatedAt": "2020-07-15 04:34:39",
+			"stats": {"confirmed": 60, "deaths": 9, "recovered": 0},
+			"coordinates": {"latitude": "34.22333378", "longitude": "-82.46170658"}, "province": "South Carolina"},
+		{"country": "US", "county": null, "updatedAt": "",
+			"stats": {"confirmed": 49, "deaths": 0, "recovered": 0},
+			"coordinates": {"latitude": "", "longitude": ""}, "province": "Diamond Princess"}
+	]`), &rows)
+
+	county := provision(rows[0])
+	if county.County == nil || *county.County != "Abbeville" || county.Cases != 60 || county.Deaths != 9 {
+		t.Fatalf("Wrong county %+v", county)
+	}
+	if county.Coordinates == nil || county.Coordinates.Latitude != 34.22333378 || county.Coordinates.Longitude != -82.46170658 {
+		t.Fatalf("Wrong coordinates %+v", county.Coordinates)
+	}
+	if county.UpdatedAt == nil || !county.UpdatedAt.Equal(time.Date(2020, 7, 15, 4, 34, 39, 0, time.UTC)) {
+		t.Fatalf("Wrong update time %v", county.UpdatedAt)
+	}
+
+	province := provision(rows[1])
+	if province.County != nil || province.Coordinates != nil || province.UpdatedAt != nil {
+		t.Fatalf("Expecting no county, coordinates and update time having %+v", province)
+	}
+
+	b, _ := json.Marshal(province)
+	if string(b) != `{"county":null,"province":"Diamond Princess","cases":49,"deaths":0,"recovered":0,"coordinates":null,"updatedAt":null}` {
+		t.Fatalf("Wrong json %s", b)
+	}
+
+	collection := GeoJSON(countryResponse(index(rows).Countries["US"]))
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+		t.Fatalf("Expecting a feature for the county with coordinates having %+v", collection)
+	}
+
+	feature := collection.Features[0]
+	if feature.Type != "Feature" || feature.Geometry.Type != "Point" ||
+		feature.Geometry.Coordinates != [2]float64{-82.46170658, 34.22333378} {
+		t.Fatalf("Wrong feature %+v", feature)
+	}
+	if feature.Properties.Country != "US" || feature.Properties.Province != "South Carolina" || *feature.Properties.County != "Abbeville" {
+		t.Fatalf("Wrong properties %+v", feature.Properties)
+	}
+
+	if empty := GeoJSON(mcsse.CSEECountryResponse{Country: "Greece"}); empty.Features == nil || len(empty.Features) != 0 {
+		t.Fatalf("Expecting an empty collection having %+v", empty)
+	}
+}
+
+func TestCSSEDatasetCached(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	indexed.reset()
+
+	jsonFile, _ := os.Open("../../test/files/csse_t2re.json")
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var sc []mcsse.ResponseCountry
+	json.Unmarshal(byteValue, &sc)
+
+	cached := index(sc)
+	requestCacheDataMockFunc = func() (mcsse.Dataset, error) {
+		return cached, nil
+	}
+	requestDataMockFunc = func() ([]mcsse.ResponseCountry, error) {
+		t.Fatal("Requesting the data while they are cached")
+		return nil, nil
+	}
+
+	russia, err := GetCSSECountryData("RU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if russia.Country != "Russia" || len(russia.Data) != 3 || russia.Totals != cached.Countries["Russia"].Totals {
+		t.Fatalf("Wrong data of Russia %+v", russia)
+	}
+
+	summary, err := GetCSSESummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Totals != cached.Totals || len(summary.Countries) != 3 || summary.Countries[0].Country != "Brazil" {
+		t.Fatalf("Wrong summary %+v", summary)
+	}
+	for _, c := range summary.Countries {
+		if c.Country == "Russia" && c.Provinces != 3 {
+			t.Fatalf("Expecting 3 provinces of Russia having %d", c.Provinces)
+		}
+	}
+}
+
+func TestCSSEDatasetInProcess(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	indexed.reset()
+
+	jsonFile, _ := os.Open("../../test/files/csse_t2re.json")
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var sc []mcsse.ResponseCountry
+	json.Unmarshal(byteValue, &sc)
+
+	cached, _ := json.Marshal(index(sc))
+	reads := 0
+	requestCacheDataMockFunc = func() (mcsse.Dataset, error) {
+		reads++
+		var dataset mcsse.Dataset
+		err := json.Unmarshal(cached, &dataset)
+		return dataset, err
+	}
+
+	for i := 0; i < 3; i++ {
+		dataset, err := GetCSSEDataset()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dataset.Names) != 3 || dataset.Names[0] != "Brazil" || dataset.Countries["Russia"].ProvinceNames == nil {
+			t.Fatalf("Expecting the sorted names of the dataset having %v", dataset.Names)
+		}
+	}
+	if reads != 1 {
+		t.Fatalf("Expecting the dataset to be decoded once having %d", reads)
+	}
+
+	expired, _ := indexed.get()
+	expired.UpdatedAt = expired.UpdatedAt.Add(-cache.TTL().CSSE)
+	indexed.set(expired)
+	if _, err := GetCSSEDataset(); err != nil {
+		t.Fatal(err)
 	}
-	v2 := getCountriesName("Greece")
-	if v2 != "Greece" {
-		t.Fatalf("Wrong value for %s which should not be coverted and stay %s", v2, v2)
+	if reads != 2 {
+		t.Fatalf("Expecting the expired dataset to be decoded again having %d reads", reads)
 	}
 }
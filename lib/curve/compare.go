@@ -0,0 +1,239 @@
+package curve
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+)
+
+// Metrics of the countries that can be compared
+const (
+	MetricDeaths                     = "deaths"
+	MetricDeathsPerDay               = "deathsPerDay"
+	MetricDeathsFromFirstDeath       = "deathsFromFirstDeath"
+	MetricDeathsPerDayFromFirstDeath = "deathsPerDayFromFirstDeath"
+	MetricCases                      = "cases"
+	MetricCasesPerDay                = "casesPerDay"
+	MetricRecovered                  = "recovered"
+	MetricRecoveredPerDay            = "recoveredPerDay"
+)
+
+// Normalizations of the compared series
+const (
+	// NormalizePer100k divides every series by the country's population
+	// in hundred thousands
+	NormalizePer100k = "per100k"
+	// NormalizePerMillion divides every series by the country's population
+	// in millions
+	NormalizePerMillion = "perMillion"
+)
+
+var (
+	// ErrUnknownMetric is returned when countries are compared on a
+	// metric that is not in Metrics
+	ErrUnknownMetric = errors.New("unknown metric")
+	// ErrNoCountries is returned when countries are compared with no country
+	ErrNoCountries = errors.New("no countries to compare")
+	// ErrUnknownNormalization is returned when the series are normalized
+	// other than per100k or perMillion
+	ErrUnknownNormalization = errors.New("unknown normalization")
+	// ErrUnknownPopulation is returned when the series of a country whose
+	// population is unknown are normalized
+	ErrUnknownPopulation = errors.New("unknown population")
+)
+
+// normalizations are the number of people of every normalization
+var normalizations = map[string]float64{
+	NormalizePer100k:    per100k,
+	NormalizePerMillion: perMillion,
+}
+
+// metricSeries return a series of the curves of a country
+var metricSeries = map[string]func(data mcountry.MainCurveData) mcountry.Timeline{
+	MetricDeaths:                     func(data mcountry.MainCurveData) mcountry.Timeline { return data.Deaths },
+	MetricDeathsPerDay:               func(data mcountry.MainCurveData) mcountry.Timeline { return data.DeathsPerDay },
+	MetricDeathsFromFirstDeath:       func(data mcountry.MainCurveData) mcountry.Timeline { return data.Deaths.FromFirstNonZero() },
+	MetricDeathsPerDayFromFirstDeath: func(data mcountry.MainCurveData) mcountry.Timeline { return data.DeathsPerDayFromFirstDeath },
+	MetricCases:                      func(data mcountry.MainCurveData) mcountry.Timeline { return data.Cases },
+	MetricCasesPerDay:                func(data mcountry.MainCurveData) mcountry.Timeline { return data.CasesPerDay },
+	MetricRecovered:                  func(data mcountry.MainCurveData) mcountry.Timeline { return data.Recovered },
+	MetricRecoveredPerDay:            func(data mcountry.MainCurveData) mcountry.Timeline { return data.RecoveredPerDay },
+}
+
+// dailyMetrics are the per day metrics, the ones that are smoothed
+var dailyMetrics = map[string]bool{
+	MetricDeathsPerDay:               true,
+	MetricDeathsPerDayFromFirstDeath: true,
+	MetricCasesPerDay:                true,
+	MetricRecoveredPerDay:            true,
+}
+
+// Metrics returns the metrics countries can be compared on
+func Metrics() []string {
+	return []string{
+		MetricDeaths, MetricDeathsPerDay, MetricDeathsFromFirstDeath, MetricDeathsPerDayFromFirstDeath,
+		MetricCases, MetricCasesPerDay, MetricRecovered, MetricRecoveredPerDay,
+	}
+}
+
+// Series returns the series of a metric of the curves of a country
+// It returns an error wrapping ErrUnknownMetric if the metric is unknown
+func Series(data mcountry.MainCurveData, metric string) (mcountry.Timeline, error) {
+	series, exist := metricSeries[metric]
+	if !exist {
+		return mcountry.Timeline{}, unknownMetric(metric)
+	}
+	return series(data), nil
+}
+
+func unknownMetric(metric string) error {
+	return fmt.Errorf("%w %q, expecting one of %s", ErrUnknownMetric, metric, strings.Join(Metrics(), ", "))
+}
+
+// CompareQuery is the countries to compare and the metrics to compare
+// them on, every metric when there is none. Normalize, when set, is
+// NormalizePer100k or NormalizePerMillion. Smoothing is applied to the
+// per day metrics
+type CompareQuery struct {
+	Countries []string
+	Metrics   []string
+	Normalize string
+	Smoothing smoothing.Options
+}
+
+// Compare returns the series of every metric of the query's countries,
+// a country that is in the query more than once is compared once
+// It returns mcountry.CompareCountries and any write error encountered,
+// ErrNoCountries if the query has no countries or an error wrapping
+// ErrUnknownMetric or ErrUnknownNormalization if a metric or the
+// normalization is unknown and ErrUnknownPopulation if the population of
+// a country is unknown when the series are normalized, ErrCountryNotFound
+// if there are no curves for a country.
+func Compare(q CompareQuery) (mcountry.CompareCountries, error) {
+	if len(q.Countries) == 0 {
+		return mcountry.CompareCountries{}, ErrNoCountries
+	}
+
+	metrics := q.Metrics
+	if len(metrics) == 0 {
+		metrics = Metrics()
+	}
+	for _, metric := range metrics {
+		if _, exist := metricSeries[metric]; !exist {
+			return mcountry.CompareCountries{}, unknownMetric(metric)
+		}
+	}
+
+	per, normalize := normalizations[q.Normalize]
+	if q.Normalize != "" && !normalize {
+		return mcountry.CompareCountries{}, fmt.Errorf("%w %q, expecting %s or %s",
+			ErrUnknownNormalization, q.Normalize, NormalizePer100k, NormalizePerMillion)
+	}
+
+	countries, err := GetAllCountries()
+	if err != nil {
+		applogger.Log("ERROR", "curve", "Compare", err.Error())
+		return mcountry.CompareCountries{}, err
+	}
+
+	var populations map[string]int
+	if normalize {
+		if populations, err = Populations(); err != nil {
+			return mcountry.CompareCountries{}, err
+		}
+	}
+
+	compare := mcountry.CompareCountries{Data: make(map[string]mcountry.CompareMetrics, len(q.Countries))}
+	for _, name := range q.Countries {
+		if _, exist := compare.Data[name]; exist {
+			continue
+		}
+
+		// the country is resolved once, the name of the API may not be
+		// the one asked for
+		country, countryErr := GetCountryBP(name, countries)
+		if countryErr != nil {
+			applogger.Log("ERROR", "curve", "Compare", countryErr.Error())
+			return mcountry.CompareCountries{}, countryErr
+		}
+		countryData := CurveData(country)
+
+		population := populations[PopulationKey(country.Country)]
+		if normalize && population <= 0 {
+			return mcountry.CompareCountries{}, fmt.Errorf("%w of %s", ErrUnknownPopulation, name)
+		}
+
+		series := make(mcountry.CompareMetrics, len(metrics))
+		for _, metric := range metrics {
+			series[metric] = metricSeries[metric](countryData)
+			if dailyMetrics[metric] {
+				series[metric] = smoothing.Apply(series[metric], q.Smoothing)
+			}
+			if normalize {
+				series[metric] = series[metric].PerCapita(population, per)
+			}
+		}
+		compare.Data[name] = series
+	}
+
+	return compare, nil
+}
+
+// compareTwo returns the series of a metric of two countries
+func compareTwo(nameOne string, nameTwo string, metric string) (mcountry.Compare, error) {
+	compare, err := Compare(CompareQuery{Countries: []string{nameOne, nameTwo}, Metrics: []string{metric}})
+	if err != nil {
+		return mcountry.Compare{}, err
+	}
+
+	return mcountry.Compare{
+		CountryOne: mcountry.CompareData{Country: nameOne, Data: compare.Data[nameOne][metric]},
+		CountryTwo: mcountry.CompareData{Country: nameTwo, Data: compare.Data[nameTwo][metric]},
+	}, nil
+}
+
+// CompareDeathsCountries returns two integer arrays (one per country passed
+// in parameter) which contain total number of deaths from  22/01/2020
+// It returns mcountry.Compare and any write error encountered.
+func CompareDeathsCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
+	return compareTwo(nameOne, nameTwo, MetricDeaths)
+}
+
+// CompareDeathsFromFirstDeathCountries returns two integer arrays (one per country passed
+// in parameter) which contain total number of deaths from  the first confirm death.
+// It returns mcountry.Compare and any write error encountered.
+func CompareDeathsFromFirstDeathCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
+	return compareTwo(nameOne, nameTwo, MetricDeathsFromFirstDeath)
+}
+
+// ComparePerDayDeathsCountries returns two integer arrays (one per country passed
+// in parameter) which contain unique per day number of deaths from first confrim death
+// It returns mcountry.Compare and any write error encountered.
+func ComparePerDayDeathsCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
+	return compareTwo(nameOne, nameTwo, MetricDeathsPerDay)
+}
+
+// CompareRecoveryCountries returns two integer arrays (one per country passed
+// in parameter) which contain total number of recovery patients from  22/01/2020
+// It returns mcountry.Compare and any write error encountered.
+func CompareRecoveryCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
+	return compareTwo(nameOne, nameTwo, MetricRecovered)
+}
+
+// CompareCasesCountries returns two integer arrays (one per country passed
+// in parameter) which contain total number of cases from  22/01/2020
+// It returns mcountry.Compare and any write error encountered.
+func CompareCasesCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
+	return compareTwo(nameOne, nameTwo, MetricCases)
+}
+
+// ComparePerDayCasesCountries returns two integer arrays (one per country passed
+// in parameter) which contain unique per day number of case from first confrim case
+// It returns mcountry.Compare and any write error encountered.
+func ComparePerDayCasesCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
+	return compareTwo(nameOne, nameTwo, MetricCasesPerDay)
+}
@@ -0,0 +1,190 @@
+package curve
+
+import (
+	"errors"
+	"testing"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+)
+
+func TestCompare(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return multipleCountriesMock(), nil
+	}
+
+	compare, err := Compare(CompareQuery{
+		Countries: []string{"Greece", "Italy", "UK", "Greece"},
+		Metrics:   []string{MetricDeaths, MetricDeathsPerDay},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(compare.Data) != 3 {
+		t.Fatalf("Expecting 3 countries having %d", len(compare.Data))
+	}
+
+	greece := compare.Data["Greece"]
+	if len(greece) != 2 {
+		t.Fatalf("Expecting 2 metrics having %d", len(greece))
+	}
+
+	if !equal(greece[MetricDeaths].Values(), []float64{0, 0, 0, 1, 1, 5, 23, 343, 75, 86, 92, 111, 112}) {
+		t.Fatalf("Wrong deaths %v", greece[MetricDeaths])
+	}
+
+	if !equal(greece[MetricDeathsPerDay].Values(), greece[MetricDeaths].Daily().Values()) {
+		t.Fatalf("Wrong deaths per day %v", greece[MetricDeathsPerDay])
+	}
+
+	all, err := Compare(CompareQuery{Countries: []string{"Italy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all.Data["Italy"]) != len(Metrics()) {
+		t.Fatalf("Expecting every metric having %d", len(all.Data["Italy"]))
+	}
+}
+
+func TestCompareInvalid(t *testing.T) {
+	if _, err := Compare(CompareQuery{}); !errors.Is(err, ErrNoCountries) {
+		t.Fatalf("Expecting no countries error having %v", err)
+	}
+
+	if _, err := Compare(CompareQuery{Countries: []string{"Greece"}, Metrics: []string{"population"}}); !errors.Is(err, ErrUnknownMetric) {
+		t.Fatalf("Expecting unknown metric error having %v", err)
+	}
+}
+
+func TestCompareUnknownCountry(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return multipleCountriesMock(), nil
+	}
+
+	if _, err := Compare(CompareQuery{Countries: []string{"Greece", "Atlantis"}}); !errors.Is(err, ErrCountryNotFound) {
+		t.Fatalf("Expecting country not found error having %v", err)
+	}
+
+	if _, err := GetCountryData("Atlantis", multipleCountriesMock()); !errors.Is(err, ErrCountryNotFound) {
+		t.Fatalf("Expecting country not found error having %v", err)
+	}
+}
+
+func TestCompareNormalize(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return multipleCountriesMock(), nil
+	}
+	requestPopulationsMockFunc = func() (map[string]int, error) {
+		return map[string]int{"Greece": 10000000, "Italy": 60000000}, nil
+	}
+
+	compare, err := Compare(CompareQuery{
+		Countries: []string{"Greece", "Italy"},
+		Metrics:   []string{MetricDeaths},
+		Normalize: NormalizePerMillion,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	greece := compare.Data["Greece"][MetricDeaths]
+	if greece[len(greece)-1].Value != 11.2 {
+		t.Fatalf("Wrong deaths per million %v", greece)
+	}
+
+	_, errPopulation := Compare(CompareQuery{Countries: []string{"UK"}, Normalize: NormalizePer100k})
+	if !errors.Is(errPopulation, ErrUnknownPopulation) {
+		t.Fatalf("Expecting unknown population error having %v", errPopulation)
+	}
+
+	_, errNormalize := Compare(CompareQuery{Countries: []string{"Greece"}, Normalize: "perCapita"})
+	if !errors.Is(errNormalize, ErrUnknownNormalization) {
+		t.Fatalf("Expecting unknown normalization error having %v", errNormalize)
+	}
+}
+
+func TestComparePopulationKeys(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return multipleCountriesMock(), nil
+	}
+	// the stats API names the country the history API calls UK
+	requestPopulationsMockFunc = func() (map[string]int, error) {
+		return map[string]int{"United Kingdom": 1100000}, nil
+	}
+	defer func() {
+		requestPopulationsMockFunc = func() (map[string]int, error) {
+			return map[string]int{}, nil
+		}
+	}()
+
+	compare, err := Compare(CompareQuery{
+		Countries: []string{"UK"},
+		Metrics:   []string{MetricDeaths},
+		Normalize: NormalizePerMillion,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uk := compare.Data["UK"][MetricDeaths]
+	if uk[len(uk)-1].Value != 100 {
+		t.Fatalf("Wrong deaths per million %v", uk)
+	}
+
+	if PopulationKey("UK") != PopulationKey("United Kingdom") || PopulationKey("Atlantis") != "Atlantis" {
+		t.Fatalf("Wrong population keys %s %s", PopulationKey("UK"), PopulationKey("Atlantis"))
+	}
+}
+
+func TestWithPopulation(t *testing.T) {
+	data := WithPopulation(mcountry.MainCurveData{
+		Cases: mcountry.Timeline{{Date: "2020-03-10", Value: 500}},
+	}, 1000000)
+
+	if data.Population != 1000000 || data.CasesPer100k[0].Value != 50 || data.CasesPerMillion[0].Value != 500 {
+		t.Fatalf("Wrong per capita series %v", data)
+	}
+
+	if len(WithPopulation(data, 0).CasesPer100k) != 0 {
+		t.Fatal("Expecting no per capita series for an unknown population")
+	}
+}
+
+func TestCompareSmoothing(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return multipleCountriesMock(), nil
+	}
+
+	o := smoothing.Options{Method: smoothing.Trailing, Window: 3, ClipNegative: true}
+	compare, err := Compare(CompareQuery{
+		Countries: []string{"Greece"},
+		Metrics:   []string{MetricDeaths, MetricDeathsPerDay},
+		Smoothing: o,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := Compare(CompareQuery{Countries: []string{"Greece"}, Metrics: []string{MetricDeaths, MetricDeathsPerDay}})
+
+	greece, rawGreece := compare.Data["Greece"], raw.Data["Greece"]
+	if !equal(greece[MetricDeathsPerDay].Values(), smoothing.Apply(rawGreece[MetricDeathsPerDay], o).Values()) {
+		t.Fatalf("Wrong smoothed deaths per day %v", greece[MetricDeathsPerDay])
+	}
+
+	if !equal(greece[MetricDeaths].Values(), rawGreece[MetricDeaths].Values()) {
+		t.Fatalf("The cumulative deaths should not be smoothed %v", greece[MetricDeaths])
+	}
+}
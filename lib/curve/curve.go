@@ -1,23 +1,35 @@
 package curve
 
 import (
-	"sort"
-
 	applogger "github.com/junkd0g/covid/lib/applogger"
 	caching "github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
 	mcountry "github.com/junkd0g/covid/lib/model/country"
+	registry "github.com/junkd0g/covid/lib/registry"
+	singleflight "github.com/junkd0g/covid/lib/singleflight"
+	stats "github.com/junkd0g/covid/lib/stats"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
+	"fmt"
+	"time"
+)
+
+const (
+	per100k    = 100000
+	perMillion = 1000000
 )
 
 var (
 	serverConf pconf.AppConf
 	reqDataOB  requestAPI
 	reqCacheOB requestCache
-	redis      caching.RedisST
+	cache      = caching.NewMemoryStore()
+	fetches    singleflight.Group
+
+	// ErrCountryNotFound is returned when there are no curves for a country,
+	// it is registry.ErrNotFound so the error has the suggestions of the registry
+	ErrCountryNotFound = registry.ErrNotFound
 )
 
 func init() {
@@ -26,24 +38,37 @@ func init() {
 	reqCacheOB = requestCacheData{}
 }
 
+// SetCache sets the Store the history data are cached in, an in-process
+// store is used until it is set
+func SetCache(store caching.Store) {
+	cache = store
+}
+
 type requestData struct{}
 type requestAPI interface {
 	requestHistoryData() ([]mcountry.CountryCurve, error)
+	requestPopulations() (map[string]int, error)
 }
 
 type requestCacheData struct{}
 type requestCache interface {
 	getCacheData() ([]mcountry.CountryCurve, error)
+	getStaleCacheData() ([]mcountry.CountryCurve, error)
 	setCacheData(ctn []mcountry.CountryCurve) error
 }
 
 func (r requestCacheData) setCacheData(ctn []mcountry.CountryCurve) error {
-	err := redis.SetCurveData(ctn)
+	err := cache.SetCurveData(ctn)
 	return err
 }
 
 func (r requestCacheData) getCacheData() ([]mcountry.CountryCurve, error) {
-	cachedData, cacheGetError := redis.GetCurveData()
+	cachedData, cacheGetError := cache.GetCurveData()
+	return cachedData, cacheGetError
+}
+
+func (r requestCacheData) getStaleCacheData() ([]mcountry.CountryCurve, error) {
+	cachedData, cacheGetError := cache.LastKnownGood().GetCurveData()
 	return cachedData, cacheGetError
 }
 
@@ -51,26 +76,10 @@ func (r requestCacheData) getCacheData() ([]mcountry.CountryCurve, error) {
 // contains covid-9 stats ' history (per day from 22/01/2020)
 // It returns []mcountry.Country and any write error encountered.
 func (r requestData) requestHistoryData() ([]mcountry.CountryCurve, error) {
-	client := &http.Client{}
-	requestURL := serverConf.API.URLHistory
-
-	req, reqErr := http.NewRequest("GET", requestURL, nil)
-	if reqErr != nil {
-		applogger.Log("ERROR", "curve", "requestHistoryData", reqErr.Error())
-		return []mcountry.CountryCurve{}, reqErr
-	}
-
-	res, resError := client.Do(req)
-	if resError != nil {
-		applogger.Log("ERROR", "curve", "requestHistoryData", resError.Error())
-		return []mcountry.CountryCurve{}, resError
-	}
-	defer res.Body.Close()
-
-	b, errorReadAll := ioutil.ReadAll(res.Body)
-	if errorReadAll != nil {
-		applogger.Log("ERROR", "curve", "requestHistoryData", errorReadAll.Error())
-		return []mcountry.CountryCurve{}, errorReadAll
+	b, err := upstream.Default.Get(serverConf.API.URLHistory)
+	if err != nil {
+		applogger.Log("ERROR", "curve", "requestHistoryData", err.Error())
+		return []mcountry.CountryCurve{}, err
 	}
 
 	keys := make([]mcountry.CountryCurve, 0)
@@ -82,352 +91,241 @@ func (r requestData) requestHistoryData() ([]mcountry.CountryCurve, error) {
 	return keys, nil
 }
 
+// refresh requests the history data from the 3rd party API and caches it.
+// Concurrent refreshes share one request and one cache write
+func refresh() ([]mcountry.CountryCurve, error) {
+	data, err, _ := fetches.Do(caching.CurveKey, func() (interface{}, error) {
+		data, err := reqDataOB.requestHistoryData()
+		if err != nil {
+			return nil, err
+		}
+		// the data are returned with the error of a failed cache write,
+		// they are still served but Refresh reports the error
+		return data, reqCacheOB.setCacheData(data)
+	})
+	if err != nil {
+		applogger.Log("ERROR", "curve", "refresh", err.Error())
+		if data == nil {
+			return []mcountry.CountryCurve{}, err
+		}
+	}
+	refreshed, ok := data.([]mcountry.CountryCurve)
+	if !ok {
+		errResult := fmt.Errorf("unexpected refresh result %T", data)
+		applogger.Log("ERROR", "curve", "refresh", errResult.Error())
+		return []mcountry.CountryCurve{}, errResult
+	}
+	return refreshed, err
+}
+
 // GetAllCountries returns an array of all countries per day
 // Covid-19 stats (data starts from date 22/01/2020)
-// Check if there are cached data if not does a HTTP
-// request to the 3rd party API (check requestHistoryData())
+// Check if there are cached data if not it serves the last known good
+// copy while refreshing it in the background, or does a HTTP request to
+// the 3rd party API (check requestHistoryData()) if there is no copy
 // It returns []structs.CountryCurve and any write error encountered.
 func GetAllCountries() ([]mcountry.CountryCurve, error) {
 	cachedData, cacheGetError := reqCacheOB.getCacheData()
 	if cacheGetError != nil {
 		applogger.Log("ERROR", "curve", "GetAllCountries", cacheGetError.Error())
-		return []mcountry.CountryCurve{}, cacheGetError
+	} else if len(cachedData) != 0 {
+		return cachedData, nil
 	}
 
-	if len(cachedData) == 0 {
-		applogger.Log("INFO", "stats", "GetAllCountries", "Request data instead of getting cached data")
-		data, err := reqDataOB.requestHistoryData()
-		if err != nil {
-			applogger.Log("ERROR", "curve", "GetAllCountries", err.Error())
-			return []mcountry.CountryCurve{}, err
-		}
-		reqCacheOB.setCacheData(data)
-		return data, nil
+	staleData, staleGetError := reqCacheOB.getStaleCacheData()
+	if staleGetError == nil && len(staleData) != 0 {
+		applogger.Log("WARN", "curve", "GetAllCountries", "Serving last known good data while refreshing it")
+		cache.MarkStale(caching.CurveKey)
+		cache.Revalidate(caching.CurveKey, func() error {
+			_, err := refresh()
+			return err
+		})
+		return staleData, nil
+	}
+
+	applogger.Log("INFO", "curve", "GetAllCountries", "Request data instead of getting cached data")
+	data, err := refresh()
+	if err != nil && len(data) == 0 {
+		return []mcountry.CountryCurve{}, err
 	}
+	return data, nil
+}
 
-	return cachedData, nil
+// Refresh requests the history data from the 3rd party API and caches them
+// whether they have expired or not
+func Refresh() error {
+	_, err := refresh()
+	return err
+}
+
+// Stale returns since when the history data are served from their
+// last known good copy and false if they are not stale
+func Stale() (time.Time, bool) {
+	return cache.StaleSince(caching.CurveKey)
 }
 
 // GetCountryBP seach through an array of structs.CountryCurve and
-// gets COVID-19 per day stats for that specific country
-// It returns mcountry.CountryCurve and any write error encountered.
+// gets COVID-19 per day stats for that specific country, the name can be
+// any name, ISO code or alias of the country in the registry. The curves of
+// the whole country are returned when it also has curves per province
+// (like UK and France), else the sum of the curves of its provinces
+// (like China and Canada)
+// It returns mcountry.CountryCurve and any write error encountered, a
+// *registry.NotFoundError if there are no curves for the country.
 func GetCountryBP(name string, allCountries []mcountry.CountryCurve) (mcountry.CountryCurve, error) {
+	resolved, err := registry.Resolve(name, Names(allCountries))
+	if err != nil {
+		return mcountry.CountryCurve{}, err
+	}
 
+	country := mcountry.CountryCurve{Country: resolved}
 	for _, v := range allCountries {
-		if name == "UK" && v.Country == "UK" {
-			if len(v.Province) == 0 {
-				return v, nil
-			}
-			continue
-		}
-		if name == "France" && v.Country == "France" {
-			if v.Province == "" {
-				return v, nil
-			}
+		if v.Country != resolved {
 			continue
 		}
-
-		if v.Country == name {
+		if v.Province == "" {
 			return v, nil
 		}
+		addProvince(&country, v)
 	}
 
-	applogger.Log("WARN", "curve", "GetCountry", "Returning empty country")
-	return mcountry.CountryCurve{}, nil
-}
-
-// CompareDeathsCountries returns two integer arrays (one per country passed
-// in parameter) which contain total number of deaths from  22/01/2020
-// It returns mcountry.Compare and any write error encountered.
-func CompareDeathsCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
-	countries, err := GetAllCountries()
-	if err != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", err.Error())
-		return mcountry.Compare{}, err
-	}
-	countryData, countryDataErr := GetCountryData(nameOne, countries)
-	if countryDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryDataErr.Error())
-		return mcountry.Compare{}, countryDataErr
-	}
-
-	countryTwoData, countryTwoDataErr := GetCountryData(nameTwo, countries)
-	if countryTwoDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryTwoDataErr.Error())
-		return mcountry.Compare{}, countryTwoDataErr
-	}
-
-	var countryOneStruct mcountry.CompareData
-	var countryTwoStruct mcountry.CompareData
-
-	countryOneStruct.Country = nameOne
-	countryOneStruct.Data = countryData.Deaths
-	countryTwoStruct.Country = nameTwo
-	countryTwoStruct.Data = countryTwoData.Deaths
-
-	compareStructs := mcountry.Compare{CountryOne: countryOneStruct, CountryTwo: countryTwoStruct}
-
-	return compareStructs, nil
+	return country, nil
 }
 
-// CompareDeathsFromFirstDeathCountries returns two integer arrays (one per country passed
-// in parameter) which contain total number of deaths from  the first confirm death.
-// It returns mcountry.Compare and any write error encountered.
-func CompareDeathsFromFirstDeathCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
-	countries, err := GetAllCountries()
-	if err != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", err.Error())
-		return mcountry.Compare{}, err
-	}
-
-	countryData, countryDataErr := GetCountryData(nameOne, countries)
-	if countryDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryDataErr.Error())
-		return mcountry.Compare{}, countryDataErr
-	}
-
-	countryTwoData, countryTwoDataErr := GetCountryData(nameTwo, countries)
-	if countryTwoDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryTwoDataErr.Error())
-		return mcountry.Compare{}, countryTwoDataErr
-	}
-
-	var countrySortedDeath []float64
-	var countryTwoSortedDeath []float64
-
-	for _, v := range countryData.Deaths {
-		if v == 0 {
+// ByCountry returns the curves of every country once, in the order of
+// their first row, built like GetCountryBP builds them without looking
+// the names up in the registry
+func ByCountry(allCountries []mcountry.CountryCurve) []mcountry.CountryCurve {
+	index := make(map[string]int, len(allCountries))
+	whole := make(map[string]bool)
+	countries := make([]mcountry.CountryCurve, 0, len(allCountries))
+	for _, v := range allCountries {
+		i, exist := index[v.Country]
+		if !exist {
+			i = len(countries)
+			index[v.Country] = i
+			countries = append(countries, mcountry.CountryCurve{Country: v.Country})
+		}
+		if whole[v.Country] {
 			continue
 		}
-		countrySortedDeath = append(countrySortedDeath, v)
-	}
-	for _, v := range countryTwoData.Deaths {
-		if v == 0 {
+		if v.Province == "" {
+			whole[v.Country] = true
+			countries[i] = v
 			continue
 		}
-		countryTwoSortedDeath = append(countryTwoSortedDeath, v)
+		addProvince(&countries[i], v)
 	}
 
-	var countryOneStruct mcountry.CompareData
-	var countryTwoStruct mcountry.CompareData
-
-	countryOneStruct.Country = nameOne
-	countryOneStruct.Data = countrySortedDeath
-	countryTwoStruct.Country = nameTwo
-	countryTwoStruct.Data = countryTwoSortedDeath
-
-	compareStructs := mcountry.Compare{CountryOne: countryOneStruct, CountryTwo: countryTwoStruct}
-
-	return compareStructs, nil
+	return countries
 }
 
-// ComparePerDayDeathsCountries returns two integer arrays (one per country passed
-// in parameter) which contain unique per day number of deaths from first confrim death
-// It returns mcountry.Compare and any write error encountered.
-func ComparePerDayDeathsCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
-	countries, err := GetAllCountries()
-	if err != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", err.Error())
-		return mcountry.Compare{}, err
-	}
-	countryData, countryDataErr := GetCountryData(nameOne, countries)
-	if countryDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryDataErr.Error())
-		return mcountry.Compare{}, countryDataErr
-	}
-
-	countryTwoData, countryTwoDataErr := GetCountryData(nameTwo, countries)
-	if countryTwoDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryTwoDataErr.Error())
-		return mcountry.Compare{}, countryTwoDataErr
-	}
-
-	var countryOneStruct mcountry.CompareData
-	var countryTwoStruct mcountry.CompareData
-
-	countryOneStruct.Country = nameOne
-	countryOneStruct.Data = countryData.DeathsPerDay
-	countryTwoStruct.Country = nameTwo
-	countryTwoStruct.Data = countryTwoData.DeathsPerDay
-
-	compareStructs := mcountry.Compare{CountryOne: countryOneStruct, CountryTwo: countryTwoStruct}
-	return compareStructs, nil
+// addProvince adds the curves of a province to the curves of its country
+func addProvince(country *mcountry.CountryCurve, province mcountry.CountryCurve) {
+	country.Timeline.Cases = country.Timeline.Cases.Add(province.Timeline.Cases)
+	country.Timeline.Deaths = country.Timeline.Deaths.Add(province.Timeline.Deaths)
+	country.Timeline.Recovered = country.Timeline.Recovered.Add(province.Timeline.Recovered)
 }
 
-// CompareRecoveryCountries returns two integer arrays (one per country passed
-// in parameter) which contain total number of recovery patients from  22/01/2020
-// It returns mcountry.Compare and any write error encountered.
-func CompareRecoveryCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
-	countries, err := GetAllCountries()
-	if err != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", err.Error())
-		return mcountry.Compare{}, err
-	}
-	countryData, countryDataErr := GetCountryData(nameOne, countries)
-	if countryDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryDataErr.Error())
-		return mcountry.Compare{}, countryDataErr
-	}
-
-	countryTwoData, countryTwoDataErr := GetCountryData(nameTwo, countries)
-	if countryTwoDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryTwoDataErr.Error())
-		return mcountry.Compare{}, countryTwoDataErr
+// Names returns the names of the countries that have curves
+func Names(allCountries []mcountry.CountryCurve) []string {
+	seen := make(map[string]bool, len(allCountries))
+	names := make([]string, 0, len(allCountries))
+	for _, v := range allCountries {
+		if !seen[v.Country] {
+			seen[v.Country] = true
+			names = append(names, v.Country)
+		}
 	}
-
-	var countryOneStruct mcountry.CompareData
-	var countryTwoStruct mcountry.CompareData
-
-	countryOneStruct.Country = nameOne
-	countryOneStruct.Data = countryData.Recovered
-	countryTwoStruct.Country = nameTwo
-	countryTwoStruct.Data = countryTwoData.Recovered
-
-	compareStructs := mcountry.Compare{CountryOne: countryOneStruct, CountryTwo: countryTwoStruct}
-
-	return compareStructs, nil
+	return names
 }
 
-// CompareCasesCountries returns two integer arrays (one per country passed
-// in parameter) which contain total number of cases from  22/01/2020
-// It returns mcountry.Compare and any write error encountered.
-func CompareCasesCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
-	countries, err := GetAllCountries()
+// GetCountryData returns the curves (total and per day) of deaths, cases and
+// recovered patients of a country, every curve is ordered by date.
+// The per capita curves are added by WithPopulation
+// It returns mcountry.MainCurveData and any write error encountered.
+func GetCountryData(countryName string, countries []mcountry.CountryCurve) (mcountry.MainCurveData, error) {
+	country, err := GetCountryBP(countryName, countries)
 	if err != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", err.Error())
-		return mcountry.Compare{}, err
-	}
-	countryData, countryDataErr := GetCountryData(nameOne, countries)
-	if countryDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryDataErr.Error())
-		return mcountry.Compare{}, countryDataErr
+		return mcountry.MainCurveData{}, err
 	}
 
-	countryTwoData, countryTwoDataErr := GetCountryData(nameTwo, countries)
-	if countryTwoDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryTwoDataErr.Error())
-		return mcountry.Compare{}, countryTwoDataErr
-	}
+	return CurveData(country), nil
+}
 
-	var countryOneStruct mcountry.CompareData
-	var countryTwoStruct mcountry.CompareData
+// CurveData returns the curves (total and per day) of deaths, cases and
+// recovered patients of a country that GetCountryBP has already resolved
+func CurveData(country mcountry.CountryCurve) mcountry.MainCurveData {
+	deaths := country.Timeline.Deaths
+	cases := country.Timeline.Cases
+	recovered := country.Timeline.Recovered
 
-	countryOneStruct.Country = nameOne
-	countryOneStruct.Data = countryData.Cases
-	countryTwoStruct.Country = nameTwo
-	countryTwoStruct.Data = countryTwoData.Cases
+	deathsPerDay := deaths.Daily()
 
-	compareStructs := mcountry.Compare{CountryOne: countryOneStruct, CountryTwo: countryTwoStruct}
+	return mcountry.MainCurveData{
+		Deaths:                     deaths,
+		DeathsPerDay:               deathsPerDay,
+		DeathsPerDayFromFirstDeath: deathsPerDay.FromFirstNonZero(),
+		Cases:                      cases,
+		CasesPerDay:                cases.Daily(),
+		Recovered:                  recovered,
+		RecoveredPerDay:            recovered.Daily(),
+	}
+}
 
-	return compareStructs, nil
+// WithPopulation returns the curves of a country with its population and
+// the per 100k and per million series of its deaths, cases and recovered
+func WithPopulation(data mcountry.MainCurveData, population int) mcountry.MainCurveData {
+	data.Population = population
+	data.DeathsPer100k = data.Deaths.PerCapita(population, per100k)
+	data.DeathsPerMillion = data.Deaths.PerCapita(population, perMillion)
+	data.CasesPer100k = data.Cases.PerCapita(population, per100k)
+	data.CasesPerMillion = data.Cases.PerCapita(population, perMillion)
+	data.RecoveredPer100k = data.Recovered.PerCapita(population, per100k)
+	data.RecoveredPerMillion = data.Recovered.PerCapita(population, perMillion)
+	return data
 }
 
-// ComparePerDayCasesCountries returns two integer arrays (one per country passed
-// in parameter) which contain unique per day number of case from first confrim case
-// It returns mcountry.Compare and any write error encountered.
-func ComparePerDayCasesCountries(nameOne string, nameTwo string) (mcountry.Compare, error) {
-	countries, err := GetAllCountries()
+// requestPopulations gets the population of every country from
+// their stats (check stats.GetAllCountries())
+// It returns map[string]int and any write error encountered.
+func (r requestData) requestPopulations() (map[string]int, error) {
+	countries, err := stats.GetAllCountries()
 	if err != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", err.Error())
-		return mcountry.Compare{}, err
-	}
-	countryData, countryDataErr := GetCountryData(nameOne, countries)
-	if countryDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryDataErr.Error())
-		return mcountry.Compare{}, countryDataErr
+		return nil, err
 	}
 
-	countryTwoData, countryTwoDataErr := GetCountryData(nameTwo, countries)
-	if countryTwoDataErr != nil {
-		applogger.Log("ERROR", "curve", "ComparePerDayCasesCountries", countryTwoDataErr.Error())
-		return mcountry.Compare{}, countryTwoDataErr
+	populations := make(map[string]int, len(countries.Data))
+	for _, v := range countries.Data {
+		populations[v.Country] = v.Population
 	}
-
-	var countryOneStruct mcountry.CompareData
-	var countryTwoStruct mcountry.CompareData
-
-	countryOneStruct.Country = nameOne
-	countryOneStruct.Data = countryData.CasesPerDay
-	countryTwoStruct.Country = nameTwo
-	countryTwoStruct.Data = countryTwoData.CasesPerDay
-
-	compareStructs := mcountry.Compare{CountryOne: countryOneStruct, CountryTwo: countryTwoStruct}
-	return compareStructs, nil
+	return populations, nil
 }
 
-func GetCountryData(countryName string, countries []mcountry.CountryCurve) (mcountry.MainCurveData, error) {
-	country, err := GetCountryBP(countryName, countries)
+// Populations returns the population of every country keyed by its
+// PopulationKey, the stats and the history API do not always use the same
+// name for a country
+// It returns map[string]int and any write error encountered.
+func Populations() (map[string]int, error) {
+	populations, err := reqDataOB.requestPopulations()
 	if err != nil {
-		return mcountry.MainCurveData{}, err
-	}
-
-	deaths := make([]float64, 0)
-	cases := make([]float64, 0)
-	recovered := make([]float64, 0)
-
-	for _, vv := range country.Timeline.Deaths.(map[string]interface{}) {
-		deaths = append(deaths, vv.(float64))
-	}
-
-	for _, vv := range country.Timeline.Cases.(map[string]interface{}) {
-		cases = append(cases, vv.(float64))
-	}
-
-	for _, v := range country.Timeline.Recovered.(map[string]interface{}) {
-		recovered = append(recovered, v.(float64))
+		applogger.Log("ERROR", "curve", "Populations", err.Error())
+		return nil, err
 	}
 
-	sort.Float64s(deaths)
-	sort.Float64s(cases)
-	sort.Float64s(recovered)
-
-	var tempCountryOneSortedDeath []float64
-	deathsPerDay := make([]float64, 0)
-
-	for i := 0; i < len(deaths); i++ {
-		tempCountryOneSortedDeath = append(tempCountryOneSortedDeath, deaths[i])
-		if i == 0 {
-			continue
-		}
-		deathsPerDay = append(deathsPerDay, (deaths[i] - tempCountryOneSortedDeath[i-1]))
+	keyed := make(map[string]int, len(populations))
+	for name, population := range populations {
+		keyed[PopulationKey(name)] = population
 	}
+	return keyed, nil
+}
 
-	var tempCountryOneSortedCases []float64
-	casesPerDay := make([]float64, 0)
-	for i := 0; i < len(cases); i++ {
-		tempCountryOneSortedCases = append(tempCountryOneSortedCases, cases[i])
-		if i == 0 {
-			continue
-		}
-		casesPerDay = append(casesPerDay, (cases[i] - tempCountryOneSortedCases[i-1]))
-	}
-
-	var tempCountryOneSortedRecovered []float64
-	recoveredPerDay := make([]float64, 0)
-	for i := 0; i < len(deaths); i++ {
-		tempCountryOneSortedRecovered = append(tempCountryOneSortedRecovered, recovered[i])
-		if i == 0 {
-			continue
-		}
-
-		recoveredPerDay = append(recoveredPerDay, (recovered[i] - tempCountryOneSortedRecovered[i-1]))
-	}
-
-	deathsPerDayFromFirst := make([]float64, 0)
-
-	for i := 0; i < len(deathsPerDay); i++ {
-		findFirstDeath := false
-		if deathsPerDay[i] == 0 && findFirstDeath == false {
-			continue
-		}
-
-		if deathsPerDay[i] != 0 {
-			findFirstDeath = true
-		}
-
-		deathsPerDayFromFirst = append(deathsPerDayFromFirst, deathsPerDay[i])
+// PopulationKey returns the key of a country in the map of Populations,
+// the ISO3 code of the country in the registry or its name if the
+// registry does not know it
+func PopulationKey(name string) string {
+	if c, exist := registry.Lookup(name); exist && c.ISO3 != "" {
+		return c.ISO3
 	}
-
-	return mcountry.MainCurveData{deaths, deathsPerDay, deathsPerDayFromFirst, cases, casesPerDay, recovered, recoveredPerDay}, nil
-
+	return name
 }
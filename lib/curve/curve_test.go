@@ -1,11 +1,20 @@
 package curve
 
 import (
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	mcountry "github.com/junkd0g/covid/lib/model/country"
 )
 
+func timelineMock(data map[string]float64) mcountry.Timeline {
+	timeline, _ := mcountry.NewTimeline(data)
+	return timeline
+}
+
 type requestDataMock struct{}
 
 var requestDataMockFunc func() ([]mcountry.CountryCurve, error)
@@ -14,6 +23,14 @@ func (u requestDataMock) requestHistoryData() ([]mcountry.CountryCurve, error) {
 	return requestDataMockFunc()
 }
 
+var requestPopulationsMockFunc = func() (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (u requestDataMock) requestPopulations() (map[string]int, error) {
+	return requestPopulationsMockFunc()
+}
+
 type requestCacheDataMock struct{}
 
 var requestCacheDataMockFunc func() ([]mcountry.CountryCurve, error)
@@ -22,6 +39,14 @@ func (u requestCacheDataMock) getCacheData() ([]mcountry.CountryCurve, error) {
 	return requestCacheDataMockFunc()
 }
 
+var requestStaleCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+	return []mcountry.CountryCurve{}, nil
+}
+
+func (u requestCacheDataMock) getStaleCacheData() ([]mcountry.CountryCurve, error) {
+	return requestStaleCacheDataMockFunc()
+}
+
 var setCacheDataMockFunc func(ctn []mcountry.CountryCurve) error
 
 func (u requestCacheDataMock) setCacheData(ctn []mcountry.CountryCurve) error {
@@ -32,19 +57,19 @@ func franceMonkData() []mcountry.CountryCurve {
 	france1 := mcountry.CountryCurve{
 		Country:  "France",
 		Province: "SomeRandom",
-		Timeline: mcountry.TimelineStruct{Cases: 44, Deaths: 43, Recovered: 44},
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 44}), Deaths: timelineMock(map[string]float64{"1/22/20": 43}), Recovered: timelineMock(map[string]float64{"1/22/20": 44})},
 	}
 
 	france2 := mcountry.CountryCurve{
 		Country:  "France",
 		Province: "",
-		Timeline: mcountry.TimelineStruct{Cases: 440, Deaths: 430, Recovered: 440},
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 440}), Deaths: timelineMock(map[string]float64{"1/22/20": 430}), Recovered: timelineMock(map[string]float64{"1/22/20": 440})},
 	}
 
 	france3 := mcountry.CountryCurve{
 		Country:  "France",
 		Province: "SomeRandom",
-		Timeline: mcountry.TimelineStruct{Cases: 4, Deaths: 4, Recovered: 4},
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 4}), Deaths: timelineMock(map[string]float64{"1/22/20": 4}), Recovered: timelineMock(map[string]float64{"1/22/20": 4})},
 	}
 
 	franceArray := []mcountry.CountryCurve{france1, france2, france3}
@@ -55,19 +80,19 @@ func ukMonkData() []mcountry.CountryCurve {
 	UK1 := mcountry.CountryCurve{
 		Country:  "UK",
 		Province: "SomeRandom",
-		Timeline: mcountry.TimelineStruct{Cases: 44, Deaths: 43, Recovered: 44},
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 44}), Deaths: timelineMock(map[string]float64{"1/22/20": 43}), Recovered: timelineMock(map[string]float64{"1/22/20": 44})},
 	}
 
 	UK2 := mcountry.CountryCurve{
 		Country:  "UK",
 		Province: "SomeRandom",
-		Timeline: mcountry.TimelineStruct{Cases: 440, Deaths: 430, Recovered: 440},
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 440}), Deaths: timelineMock(map[string]float64{"1/22/20": 430}), Recovered: timelineMock(map[string]float64{"1/22/20": 440})},
 	}
 
 	UK3 := mcountry.CountryCurve{
 		Country:  "UK",
 		Province: "",
-		Timeline: mcountry.TimelineStruct{Cases: 4, Deaths: 4, Recovered: 4},
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 4}), Deaths: timelineMock(map[string]float64{"1/22/20": 4}), Recovered: timelineMock(map[string]float64{"1/22/20": 4})},
 	}
 
 	UKArray := []mcountry.CountryCurve{UK1, UK2, UK3}
@@ -85,8 +110,8 @@ func TestGetCountryBP(t *testing.T) {
 		t.Fatalf("Wrong country name %s", el.Country)
 	}
 
-	if el.Timeline.Cases != 440 {
-		t.Fatalf("Wrong ammout of cases %d", el.Timeline.Cases)
+	if el.Timeline.Cases[0].Value != 440 {
+		t.Fatalf("Wrong ammout of cases %v", el.Timeline.Cases)
 	}
 
 	el2, err2 := GetCountryBP("UK", ukMonkData())
@@ -98,8 +123,32 @@ func TestGetCountryBP(t *testing.T) {
 		t.Fatalf("Wrong country name %s", el2.Country)
 	}
 
-	if el2.Timeline.Cases != 4 {
-		t.Fatalf("Wrong ammout of cases %d", el2.Timeline.Cases)
+	if el2.Timeline.Cases[0].Value != 4 {
+		t.Fatalf("Wrong ammout of cases %v", el2.Timeline.Cases)
+	}
+}
+
+func TestByCountry(t *testing.T) {
+	china := mcountry.CountryCurve{
+		Country:  "China",
+		Province: "Hubei",
+		Timeline: mcountry.TimelineStruct{Cases: timelineMock(map[string]float64{"1/22/20": 6})},
+	}
+	china2 := china
+	china2.Province = "Beijing"
+
+	countries := ByCountry(append(append(franceMonkData(), china, china2), ukMonkData()...))
+	if len(countries) != 3 {
+		t.Fatalf("Expected the curves of 3 countries got %d", len(countries))
+	}
+
+	for i, expected := range []struct {
+		country string
+		cases   float64
+	}{{"France", 440}, {"China", 12}, {"UK", 4}} {
+		if countries[i].Country != expected.country || countries[i].Timeline.Cases[0].Value != expected.cases {
+			t.Fatalf("Wrong curves of %s %+v", expected.country, countries[i])
+		}
 	}
 }
 
@@ -143,6 +192,108 @@ func TestGetAllCountries(t *testing.T) {
 	}
 }
 
+func TestGetAllCountriesCacheDown(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+
+	setCacheDataMockFunc = func(ctn []mcountry.CountryCurve) error {
+		return nil
+	}
+
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{}, errors.New("connection refused")
+	}
+
+	requestDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return ukMonkData(), nil
+	}
+
+	data, err := GetAllCountries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) == 0 || data[0].Country != "UK" {
+		t.Fatalf("Expected the data of the third party API got %v", data)
+	}
+
+	requestDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{}, errors.New("upstream is down")
+	}
+
+	if _, err := GetAllCountries(); err == nil {
+		t.Fatal("Expected an error when both the cache and the third party API are down")
+	}
+}
+
+func TestRefreshCacheWriteError(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+
+	setCacheDataMockFunc = func(ctn []mcountry.CountryCurve) error {
+		return errors.New("connection refused")
+	}
+
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{}, nil
+	}
+
+	requestDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return ukMonkData(), nil
+	}
+
+	if err := Refresh(); err == nil {
+		t.Fatal("Expected the error of the cache write")
+	}
+
+	data, err := GetAllCountries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) == 0 || data[0].Country != "UK" {
+		t.Fatalf("Expected the data of the third party API got %v", data)
+	}
+}
+
+func TestGetAllCountriesCoalesced(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+
+	var requests, cacheWrites int32
+	setCacheDataMockFunc = func(ctn []mcountry.CountryCurve) error {
+		atomic.AddInt32(&cacheWrites, 1)
+		return nil
+	}
+
+	requestCacheDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{}, nil
+	}
+
+	requestDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		return ukMonkData(), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := GetAllCountries()
+			if err != nil || len(data) == 0 {
+				t.Errorf("Wrong coalesced response %v %v", data, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 1 || cacheWrites != 1 {
+		t.Fatalf("Expecting one request and one cache write having %d and %d", requests, cacheWrites)
+	}
+}
+
 func TestCompareDeathsCountries(t *testing.T) {
 	setCacheDataMockFunc = func(ctn []mcountry.CountryCurve) error {
 		return nil
@@ -169,13 +320,13 @@ func TestCompareDeathsCountries(t *testing.T) {
 		t.Fatalf("Wrong country name %s", compareDeathsData.CountryTwo.Country)
 	}
 
-	mockCountryOneData := []float64{0, 0, 0, 1, 1, 5, 23, 75, 86, 92, 111, 112, 343}
-	if !equal(compareDeathsData.CountryOne.Data, mockCountryOneData) {
+	mockCountryOneData := []float64{0, 0, 0, 1, 1, 5, 23, 343, 75, 86, 92, 111, 112}
+	if !equal(compareDeathsData.CountryOne.Data.Values(), mockCountryOneData) {
 		t.Fatalf("Wrong data %v", compareDeathsData.CountryOne.Data)
 	}
 
-	mockCountryTwoData := []float64{0, 0, 0, 1, 1, 5, 23, 75, 86, 92, 1211, 1312, 3430}
-	if !equal(compareDeathsData.CountryTwo.Data, mockCountryTwoData) {
+	mockCountryTwoData := []float64{0, 0, 0, 1, 1, 5, 23, 3430, 75, 86, 92, 1211, 1312}
+	if !equal(compareDeathsData.CountryTwo.Data.Values(), mockCountryTwoData) {
 		t.Fatalf("Wrong data %v", compareDeathsData.CountryTwo.Data)
 	}
 
@@ -192,8 +343,12 @@ func TestCompareDeathsCountries(t *testing.T) {
 		t.Fatalf("Wrong country name %s", compareDeathsData.CountryTwo.Country)
 	}
 
-	mock2CountryTwoData := []float64{0, 0, 0, 1, 1, 5, 23, 75, 86, 92, 1211, 1312, 3430}
-	if !equal(compareDeathsData.CountryTwo.Data, mock2CountryTwoData) {
+	if compareDeathsData.CountryOne.Data[7].Date != "2020-03-19" {
+		t.Fatalf("Wrong date %s", compareDeathsData.CountryOne.Data[7].Date)
+	}
+
+	mock2CountryTwoData := []float64{0, 0, 0, 1, 1, 5, 23, 3430, 75, 86, 92, 1211, 1312}
+	if !equal(compareDeathsData.CountryTwo.Data.Values(), mock2CountryTwoData) {
 		t.Fatalf("Wrong data %v", compareDeathsData.CountryTwo.Data)
 	}
 
@@ -216,7 +371,7 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 		Country:  "UK",
 		Province: "",
 		Timeline: mcountry.TimelineStruct{
-			Deaths: map[string]interface{}{
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -233,8 +388,8 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 110.0,
-			},
-			Cases: map[string]interface{}{
+			}),
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -251,8 +406,8 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 110.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -269,14 +424,14 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 110.0,
-			}},
+			})},
 	}
 
 	Greece := mcountry.CountryCurve{
 		Country:  "Greece",
 		Province: "",
 		Timeline: mcountry.TimelineStruct{
-			Deaths: map[string]interface{}{
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -290,8 +445,8 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 92.0,
 				"3/27/20": 111.0,
 				"3/28/20": 112.0,
-			},
-			Cases: map[string]interface{}{
+			}),
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -308,8 +463,8 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 113.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -326,14 +481,14 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 113.0,
-			}},
+			})},
 	}
 
 	Italy := mcountry.CountryCurve{
 		Country:  "Italy",
 		Province: "",
 		Timeline: mcountry.TimelineStruct{
-			Deaths: map[string]interface{}{
+			Deaths: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -347,8 +502,8 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 92.0,
 				"3/27/20": 1211.0,
 				"3/28/20": 1312.0,
-			},
-			Cases: map[string]interface{}{
+			}),
+			Cases: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -365,8 +520,8 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 110.0,
-			},
-			Recovered: map[string]interface{}{
+			}),
+			Recovered: timelineMock(map[string]float64{
 				"1/22/20": 0.0,
 				"1/23/20": 0.0,
 				"1/24/20": 0.0,
@@ -383,7 +538,7 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 				"3/26/20": 94.0,
 				"3/27/20": 110.0,
 				"3/28/20": 110.0,
-			}},
+			})},
 	}
 
 	multiArray := []mcountry.CountryCurve{UK, Greece, Italy}
@@ -397,3 +552,16 @@ func multipleCountriesMock() []mcountry.CountryCurve {
 //CompareCasesCountries
 //ComparePerDayCasesCountries
 //GetCountryData
+
+func TestGetCountryDataEmptyTimeline(t *testing.T) {
+	countries := []mcountry.CountryCurve{{Country: "Greece"}}
+
+	countryData, err := GetCountryData("Greece", countries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(countryData.Cases) != 0 || len(countryData.CasesPerDay) != 0 {
+		t.Fatalf("Expecting empty curves but having %v", countryData)
+	}
+}
@@ -0,0 +1,87 @@
+package curve
+
+/*
+	The curves of the provinces and states of the countries the
+	third party API has them for
+*/
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	registry "github.com/junkd0g/covid/lib/registry"
+)
+
+// ErrProvinceNotFound is wrapped by the error returned when a country has
+// no curves for a province
+var ErrProvinceNotFound = errors.New("province not found")
+
+// GetProvince returns the curves of a province of a country, the country
+// is found like in GetCountryBP and the province ignoring case
+// It returns mcountry.ProvinceCurve and any write error encountered, a
+// *registry.NotFoundError if there are no curves for the country and an
+// error wrapping ErrProvinceNotFound if there are none for the province.
+func GetProvince(countryName string, provinceName string, allCountries []mcountry.CountryCurve) (mcountry.ProvinceCurve, error) {
+	resolved, err := registry.Resolve(countryName, Names(allCountries))
+	if err != nil {
+		return mcountry.ProvinceCurve{}, err
+	}
+
+	provinces := []string{}
+	for _, v := range allCountries {
+		if v.Country != resolved || v.Province == "" {
+			continue
+		}
+		if strings.EqualFold(v.Province, strings.TrimSpace(provinceName)) {
+			return provinceCurve(v), nil
+		}
+		provinces = append(provinces, v.Province)
+	}
+
+	err = fmt.Errorf("%w: %q of %s", ErrProvinceNotFound, provinceName, resolved)
+	if suggestions := registry.Suggest(provinceName, provinces); len(suggestions) != 0 {
+		err = fmt.Errorf("%w, did you mean %s?", err, strings.Join(suggestions, ", "))
+	}
+	applogger.Log("WARN", "curve", "GetProvince", err.Error())
+	return mcountry.ProvinceCurve{}, err
+}
+
+// provinceCurve returns the total and per day curves of a province
+func provinceCurve(c mcountry.CountryCurve) mcountry.ProvinceCurve {
+	return mcountry.ProvinceCurve{
+		Country:         c.Country,
+		Province:        c.Province,
+		Cases:           c.Timeline.Cases,
+		CasesPerDay:     c.Timeline.Cases.Daily(),
+		Deaths:          c.Timeline.Deaths,
+		DeathsPerDay:    c.Timeline.Deaths.Daily(),
+		Recovered:       c.Timeline.Recovered,
+		RecoveredPerDay: c.Timeline.Recovered.Daily(),
+	}
+}
+
+// Provinces returns the countries that have curves per province with their
+// provinces, both ordered by name
+func Provinces(allCountries []mcountry.CountryCurve) []mcountry.CountryProvinces {
+	byCountry := make(map[string][]string)
+	for _, v := range allCountries {
+		if v.Province != "" {
+			byCountry[v.Country] = append(byCountry[v.Country], v.Province)
+		}
+	}
+
+	countries := make([]mcountry.CountryProvinces, 0, len(byCountry))
+	for country, provinces := range byCountry {
+		sort.Strings(provinces)
+		countries = append(countries, mcountry.CountryProvinces{Country: country, Provinces: provinces})
+	}
+	sort.Slice(countries, func(i, j int) bool {
+		return countries[i].Country < countries[j].Country
+	})
+
+	return countries
+}
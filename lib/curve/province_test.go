@@ -0,0 +1,89 @@
+package curve
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+func provincesMock() []mcountry.CountryCurve {
+	province := func(country string, province string, cases map[string]float64, deaths map[string]float64) mcountry.CountryCurve {
+		return mcountry.CountryCurve{
+			Country:  country,
+			Province: province,
+			Timeline: mcountry.TimelineStruct{Cases: timelineMock(cases), Deaths: timelineMock(deaths), Recovered: timelineMock(map[string]float64{})},
+		}
+	}
+
+	return []mcountry.CountryCurve{
+		province("China", "hubei", map[string]float64{"1/22/20": 444, "1/23/20": 444}, map[string]float64{"1/22/20": 17, "1/23/20": 17}),
+		province("China", "beijing", map[string]float64{"1/22/20": 14, "1/23/20": 22}, map[string]float64{"1/22/20": 0, "1/23/20": 0}),
+		province("Greece", "", map[string]float64{"1/22/20": 1, "1/23/20": 2}, map[string]float64{"1/22/20": 0, "1/23/20": 0}),
+		province("UK", "", map[string]float64{"1/22/20": 5, "1/23/20": 8}, map[string]float64{"1/22/20": 1, "1/23/20": 1}),
+		province("UK", "Bermuda", map[string]float64{"1/22/20": 1, "1/23/20": 1}, map[string]float64{"1/22/20": 0, "1/23/20": 0}),
+	}
+}
+
+func TestGetCountryBPProvinces(t *testing.T) {
+	china, err := GetCountryBP("China", provincesMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if china.Country != "China" || china.Province != "" {
+		t.Fatalf("Wrong country %s %s", china.Country, china.Province)
+	}
+	if !reflect.DeepEqual(china.Timeline.Cases.Values(), []float64{458, 466}) || !reflect.DeepEqual(china.Timeline.Deaths.Values(), []float64{17, 17}) {
+		t.Fatalf("Expecting the sum of the provinces having %v %v", china.Timeline.Cases, china.Timeline.Deaths)
+	}
+
+	uk, err := GetCountryBP("UK", provincesMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(uk.Timeline.Cases.Values(), []float64{5, 8}) {
+		t.Fatalf("Expecting the curves of the whole country having %v", uk.Timeline.Cases)
+	}
+}
+
+func TestGetProvince(t *testing.T) {
+	hubei, err := GetProvince("CN", "Hubei", provincesMock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hubei.Country != "China" || hubei.Province != "hubei" {
+		t.Fatalf("Wrong province %s %s", hubei.Country, hubei.Province)
+	}
+	if !reflect.DeepEqual(hubei.CasesPerDay.Values(), []float64{0}) || len(hubei.Cases) != 2 {
+		t.Fatalf("Wrong curves of the province %+v", hubei)
+	}
+
+	if _, err := GetProvince("China", "Hubej", provincesMock()); !errors.Is(err, ErrProvinceNotFound) {
+		t.Fatalf("Expecting province not found having %v", err)
+	} else if err.Error() != `province not found: "Hubej" of China, did you mean hubei?` {
+		t.Fatalf("Wrong error %v", err)
+	}
+
+	if _, err := GetProvince("Greece", "Attica", provincesMock()); !errors.Is(err, ErrProvinceNotFound) {
+		t.Fatalf("Expecting province not found having %v", err)
+	}
+
+	if _, err := GetProvince("Atlantis", "hubei", provincesMock()); !errors.Is(err, ErrCountryNotFound) {
+		t.Fatalf("Expecting country not found having %v", err)
+	}
+}
+
+func TestProvinces(t *testing.T) {
+	provinces := Provinces(provincesMock())
+
+	expected := []mcountry.CountryProvinces{
+		{Country: "China", Provinces: []string{"beijing", "hubei"}},
+		{Country: "UK", Provinces: []string{"Bermuda"}},
+	}
+	if !reflect.DeepEqual(provinces, expected) {
+		t.Fatalf("Wrong provinces %v", provinces)
+	}
+}
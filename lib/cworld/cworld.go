@@ -1,26 +1,32 @@
 package cworld
 
-//TODO add fucking caching you piece of shit and add expiration time
-
 import (
-	"sort"
-
 	applogger "github.com/junkd0g/covid/lib/applogger"
 	"github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
+	forecast "github.com/junkd0g/covid/lib/forecast"
 	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mforecast "github.com/junkd0g/covid/lib/model/forecast"
 	mworld "github.com/junkd0g/covid/lib/model/world"
+	singleflight "github.com/junkd0g/covid/lib/singleflight"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
+	"fmt"
+	"strings"
+	"time"
 )
 
+// Name is the name the world history is projected with
+const Name = "World"
+
 var (
 	serverConf pconf.AppConf
 	reqDataOB  requestAPI
 	reqCacheOB requestCache
-	redis      caching.RedisST
+	cache      = caching.NewMemoryStore()
+	fetches    singleflight.Group
 )
 
 func init() {
@@ -29,6 +35,12 @@ func init() {
 	reqCacheOB = requestCacheData{}
 }
 
+// SetCache sets the Store the world history is cached in, an in-process
+// store is used until it is set
+func SetCache(store caching.Store) {
+	cache = store
+}
+
 type requestData struct{}
 type requestAPI interface {
 	requestHistoryData() (mworld.WorldTimeline, error)
@@ -37,16 +49,22 @@ type requestAPI interface {
 type requestCacheData struct{}
 type requestCache interface {
 	getCacheData() (mworld.WorldTimeline, bool, error)
+	getStaleCacheData() (mworld.WorldTimeline, bool, error)
 	setCacheData(ctn mworld.WorldTimeline) error
 }
 
 func (r requestCacheData) setCacheData(ctn mworld.WorldTimeline) error {
-	err := redis.SetWorldData(ctn)
+	err := cache.SetWorldData(ctn)
 	return err
 }
 
 func (r requestCacheData) getCacheData() (mworld.WorldTimeline, bool, error) {
-	cachedData, exist, cacheGetError := redis.GetWorldData()
+	cachedData, exist, cacheGetError := cache.GetWorldData()
+	return cachedData, exist, cacheGetError
+}
+
+func (r requestCacheData) getStaleCacheData() (mworld.WorldTimeline, bool, error) {
+	cachedData, exist, cacheGetError := cache.LastKnownGood().GetWorldData()
 	return cachedData, exist, cacheGetError
 }
 
@@ -54,26 +72,10 @@ func (r requestCacheData) getCacheData() (mworld.WorldTimeline, bool, error) {
 // contains covid-9 stats ' history (per day from 22/01/2020)
 // It returns []mcountry.Country and any write error encountered.
 func (r requestData) requestHistoryData() (mworld.WorldTimeline, error) {
-	client := &http.Client{}
-	requestURL := serverConf.API.URLWorldHistory
-
-	req, reqErr := http.NewRequest("GET", requestURL, nil)
-	if reqErr != nil {
-		applogger.Log("ERROR", "cworld", "requestHistoryData", reqErr.Error())
-		return mworld.WorldTimeline{}, reqErr
-	}
-
-	res, resError := client.Do(req)
-	if resError != nil {
-		applogger.Log("ERROR", "cworld", "requestHistoryData", resError.Error())
-		return mworld.WorldTimeline{}, resError
-	}
-	defer res.Body.Close()
-
-	b, errorReadAll := ioutil.ReadAll(res.Body)
-	if errorReadAll != nil {
-		applogger.Log("ERROR", "cworld", "requestHistoryData", errorReadAll.Error())
-		return mworld.WorldTimeline{}, errorReadAll
+	b, err := upstream.Default.Get(serverConf.API.URLWorldHistory)
+	if err != nil {
+		applogger.Log("ERROR", "cworld", "requestHistoryData", err.Error())
+		return mworld.WorldTimeline{}, err
 	}
 
 	var timeline mcountry.TimelineStruct
@@ -82,89 +84,122 @@ func (r requestData) requestHistoryData() (mworld.WorldTimeline, error) {
 		return mworld.WorldTimeline{}, errUnmarshal
 	}
 
-	deaths := make([]float64, 0)
-	cases := make([]float64, 0)
-	recovered := make([]float64, 0)
+	var worldTimeline mworld.WorldTimeline
 
-	for _, v := range timeline.Deaths.(map[string]interface{}) {
-		deaths = append(deaths, v.(float64))
-	}
+	worldTimeline.Deaths = timeline.Deaths
+	worldTimeline.Cases = timeline.Cases
+	worldTimeline.Recovered = timeline.Recovered
+	worldTimeline.DeathsDaily = timeline.Deaths.Daily()
+	worldTimeline.CasesDaily = timeline.Cases.Daily()
+	worldTimeline.RecoveredDaily = timeline.Recovered.Daily()
 
-	for _, v := range timeline.Cases.(map[string]interface{}) {
-		cases = append(cases, v.(float64))
-	}
+	return worldTimeline, nil
+}
 
-	for _, v := range timeline.Recovered.(map[string]interface{}) {
-		recovered = append(recovered, v.(float64))
+// refresh requests the world history from the 3rd party API and caches it.
+// Concurrent refreshes share one request and one cache write
+func refresh() (mworld.WorldTimeline, error) {
+	data, err, _ := fetches.Do(caching.WorldKey, func() (interface{}, error) {
+		data, err := reqDataOB.requestHistoryData()
+		if err != nil {
+			return nil, err
+		}
+		// the data are returned with the error of a failed cache write,
+		// they are still served but Refresh reports the error
+		return data, reqCacheOB.setCacheData(data)
+	})
+	if err != nil {
+		applogger.Log("ERROR", "cworld", "refresh", err.Error())
+		if data == nil {
+			return mworld.WorldTimeline{}, err
+		}
 	}
+	refreshed, ok := data.(mworld.WorldTimeline)
+	if !ok {
+		errResult := fmt.Errorf("unexpected refresh result %T", data)
+		applogger.Log("ERROR", "cworld", "refresh", errResult.Error())
+		return mworld.WorldTimeline{}, errResult
+	}
+	return refreshed, err
+}
 
-	sort.Float64s(deaths)
-	sort.Float64s(cases)
-	sort.Float64s(recovered)
-
-	var worldTimeline mworld.WorldTimeline
-
-	worldTimeline.Deaths = deaths
-	worldTimeline.Cases = cases
-	worldTimeline.Recovered = recovered
+//GetaWorldHistory returns world history for covid-19
+//When the cached data have expired it serves their last known good copy
+//while refreshing them in the background
+func GetaWorldHistory() (mworld.WorldTimeline, error) {
+	cachedData, exist, cacheGetError := reqCacheOB.getCacheData()
+	if cacheGetError != nil {
+		applogger.Log("ERROR", "cworld", "GetaWorldHistory", cacheGetError.Error())
+	} else if exist {
+		return cachedData, nil
+	}
 
-	var tempCountryOneSortedDeath []float64
-	deathsPerDay := make([]float64, 0)
-	for i := 0; i < len(deaths); i++ {
-		tempCountryOneSortedDeath = append(tempCountryOneSortedDeath, deaths[i])
-		if i == 0 {
-			continue
-		}
+	staleData, staleExist, staleGetError := reqCacheOB.getStaleCacheData()
+	if staleGetError == nil && staleExist {
+		applogger.Log("WARN", "cworld", "GetaWorldHistory", "Serving last known good data while refreshing it")
+		cache.MarkStale(caching.WorldKey)
+		cache.Revalidate(caching.WorldKey, func() error {
+			_, err := refresh()
+			return err
+		})
+		return staleData, nil
+	}
 
-		deathsPerDay = append(deathsPerDay, (deaths[i] - tempCountryOneSortedDeath[i-1]))
+	applogger.Log("INFO", "cworld", "GetaWorldHistory", "Request data instead of getting cached data")
+	data, err := refresh()
+	if err != nil && len(data.Cases) == 0 {
+		return mworld.WorldTimeline{}, err
 	}
+	return data, nil
+}
 
-	var tempCountryOneSortedCases []float64
-	casesPerDay := make([]float64, 0)
-	for i := 0; i < len(cases); i++ {
-		tempCountryOneSortedCases = append(tempCountryOneSortedCases, cases[i])
-		if i == 0 {
-			continue
-		}
+//IsWorld reports whether name is the name of the world, ignoring case
+func IsWorld(name string) bool {
+	return strings.EqualFold(name, Name)
+}
 
-		casesPerDay = append(casesPerDay, (cases[i] - tempCountryOneSortedCases[i-1]))
+//CurveData returns the world history as the curves of a country
+func CurveData(world mworld.WorldTimeline) mcountry.MainCurveData {
+	return mcountry.MainCurveData{
+		Deaths:                     world.Deaths,
+		DeathsPerDay:               world.DeathsDaily,
+		DeathsPerDayFromFirstDeath: world.DeathsDaily.FromFirstNonZero(),
+		Cases:                      world.Cases,
+		CasesPerDay:                world.CasesDaily,
+		Recovered:                  world.Recovered,
+		RecoveredPerDay:            world.RecoveredDaily,
 	}
+}
 
-	var tempCountryOneSortedRecovered []float64
-	recoveredPerDay := make([]float64, 0)
-	for i := 0; i < len(deaths); i++ {
-		tempCountryOneSortedRecovered = append(tempCountryOneSortedRecovered, recovered[i])
-		if i == 0 {
-			continue
-		}
-
-		recoveredPerDay = append(recoveredPerDay, (recovered[i] - tempCountryOneSortedRecovered[i-1]))
+//Forecast returns the projection of a metric of the world history
+//It returns the errors of forecast.Metric
+func Forecast(metric string, o forecast.Options) (mforecast.Forecast, error) {
+	world, err := GetaWorldHistory()
+	if err != nil {
+		applogger.Log("ERROR", "cworld", "Forecast", err.Error())
+		return mforecast.Forecast{}, err
 	}
 
-	worldTimeline.DeathsDaily = deathsPerDay
-	worldTimeline.CasesDaily = casesPerDay
-	worldTimeline.RecoveredDaily = recoveredPerDay
+	return forecast.Metric(Name, CurveData(world), metric, o)
+}
 
-	return worldTimeline, nil
+//Smooth returns the world history with its daily series smoothed
+func Smooth(data mworld.WorldTimeline, o smoothing.Options) mworld.WorldTimeline {
+	data.CasesDaily = smoothing.Apply(data.CasesDaily, o)
+	data.DeathsDaily = smoothing.Apply(data.DeathsDaily, o)
+	data.RecoveredDaily = smoothing.Apply(data.RecoveredDaily, o)
+	return data
 }
 
-//GetaWorldHistory returns world history for covid-19
-func GetaWorldHistory() (mworld.WorldTimeline, error) {
-	cachedData, exist, cacheGetError := reqCacheOB.getCacheData()
-	if cacheGetError != nil {
-		applogger.Log("ERROR", "cworld", "GetaWorldHistory", cacheGetError.Error())
-		return mworld.WorldTimeline{}, cacheGetError
-	}
+// Refresh requests the world history from the 3rd party API and caches them
+// whether they have expired or not
+func Refresh() error {
+	_, err := refresh()
+	return err
+}
 
-	if !exist {
-		applogger.Log("INFO", "cworld", "GetaWorldHistory", "Request data instead of getting cached data")
-		data, err := reqDataOB.requestHistoryData()
-		if err != nil {
-			applogger.Log("ERROR", "cworld", "GetaWorldHistory", err.Error())
-			return mworld.WorldTimeline{}, err
-		}
-		reqCacheOB.setCacheData(data)
-		return data, nil
-	}
-	return cachedData, nil
+//Stale returns since when the world history is served from its
+//last known good copy and false if it is not stale
+func Stale() (time.Time, bool) {
+	return cache.StaleSince(caching.WorldKey)
 }
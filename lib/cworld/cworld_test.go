@@ -1,11 +1,25 @@
 package cworld
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	forecast "github.com/junkd0g/covid/lib/forecast"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
 	mworld "github.com/junkd0g/covid/lib/model/world"
+	smoothing "github.com/junkd0g/covid/lib/smoothing"
 )
 
+func timelineMock(values ...float64) mcountry.Timeline {
+	timeline := make(mcountry.Timeline, 0)
+	for i, v := range values {
+		timeline = append(timeline, mcountry.TimelinePoint{Date: fmt.Sprintf("2020-01-%02d", i+1), Value: v})
+	}
+	return timeline
+}
+
 type requestDataMock struct{}
 
 var requestDataMockFunc func() (mworld.WorldTimeline, error)
@@ -22,6 +36,14 @@ func (u requestCacheDataMock) getCacheData() (mworld.WorldTimeline, bool, error)
 	return requestCacheDataMockFunc()
 }
 
+var requestStaleCacheDataMockFunc = func() (mworld.WorldTimeline, bool, error) {
+	return mworld.WorldTimeline{}, false, nil
+}
+
+func (u requestCacheDataMock) getStaleCacheData() (mworld.WorldTimeline, bool, error) {
+	return requestStaleCacheDataMockFunc()
+}
+
 var setCacheDataMockFunc func(ctn mworld.WorldTimeline) error
 
 func (u requestCacheDataMock) setCacheData(ctn mworld.WorldTimeline) error {
@@ -38,66 +60,12 @@ func TestRegisterUser(t *testing.T) {
 
 	requestDataMockFunc = func() (mworld.WorldTimeline, error) {
 		return mworld.WorldTimeline{
-			Cases: []float64{
-				1,
-				10,
-				100,
-				1000,
-				10000,
-				30000,
-				34054,
-				45432,
-			},
-			Deaths: []float64{
-				1,
-				10,
-				100,
-				1000,
-				10000,
-				30000,
-				34054,
-				45432,
-			},
-			Recovered: []float64{
-				1,
-				10,
-				100,
-				1000,
-				10000,
-				30000,
-				34054,
-				45432,
-			},
-			CasesDaily: []float64{
-				1,
-				9,
-				99,
-				999,
-				9999,
-				19999,
-				4054,
-				5432,
-			},
-			DeathsDaily: []float64{
-				1,
-				9,
-				99,
-				999,
-				9999,
-				19999,
-				4054,
-				5432,
-			},
-			RecoveredDaily: []float64{
-				1,
-				9,
-				99,
-				999,
-				9999,
-				19999,
-				4054,
-				5432,
-			},
+			Cases:          timelineMock(1, 10, 100, 1000, 10000, 30000, 34054, 45432),
+			Deaths:         timelineMock(1, 10, 100, 1000, 10000, 30000, 34054, 45432),
+			Recovered:      timelineMock(1, 10, 100, 1000, 10000, 30000, 34054, 45432),
+			CasesDaily:     timelineMock(1, 9, 99, 999, 9999, 19999, 4054, 5432),
+			DeathsDaily:    timelineMock(1, 9, 99, 999, 9999, 19999, 4054, 5432),
+			RecoveredDaily: timelineMock(1, 9, 99, 999, 9999, 19999, 4054, 5432),
 		}, nil
 	}
 
@@ -110,36 +78,18 @@ func TestRegisterUser(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(withNoCashedData.Cases.([]float64)) == 0 {
+	if len(withNoCashedData.Cases) == 0 {
 		t.Fatal("Getting cached data instead of requested data")
 	}
 
 	requestCacheDataMockFunc = func() (mworld.WorldTimeline, bool, error) {
 		return mworld.WorldTimeline{
-			Cases: []float64{
-				1,
-				10,
-			},
-			Deaths: []float64{
-				1,
-				10,
-			},
-			Recovered: []float64{
-				1,
-				10,
-			},
-			CasesDaily: []float64{
-				1,
-				9,
-			},
-			DeathsDaily: []float64{
-				1,
-				9,
-			},
-			RecoveredDaily: []float64{
-				1,
-				9,
-			},
+			Cases:          timelineMock(1, 10),
+			Deaths:         timelineMock(1, 10),
+			Recovered:      timelineMock(1, 10),
+			CasesDaily:     timelineMock(1, 9),
+			DeathsDaily:    timelineMock(1, 9),
+			RecoveredDaily: timelineMock(1, 9),
 		}, true, nil
 	}
 
@@ -147,7 +97,97 @@ func TestRegisterUser(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(withEmptryButTrueCashedData.Cases.([]float64)) != 2 {
+	if len(withEmptryButTrueCashedData.Cases) != 2 {
 		t.Fatal("Not getting cached data")
 	}
 }
+
+func TestGetaWorldHistoryStale(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	reqDataOB = requestDataMock{}
+	defer func() {
+		requestStaleCacheDataMockFunc = func() (mworld.WorldTimeline, bool, error) {
+			return mworld.WorldTimeline{}, false, nil
+		}
+	}()
+
+	refreshed := make(chan bool)
+	setCacheDataMockFunc = func(ctn mworld.WorldTimeline) error {
+		err := cache.SetWorldData(ctn)
+		refreshed <- true
+		return err
+	}
+
+	requestCacheDataMockFunc = func() (mworld.WorldTimeline, bool, error) {
+		return mworld.WorldTimeline{}, false, nil
+	}
+
+	requestStaleCacheDataMockFunc = func() (mworld.WorldTimeline, bool, error) {
+		return mworld.WorldTimeline{Cases: timelineMock(1, 10)}, true, nil
+	}
+
+	requestDataMockFunc = func() (mworld.WorldTimeline, error) {
+		return mworld.WorldTimeline{Cases: timelineMock(1, 10, 100)}, nil
+	}
+
+	staleData, err := GetaWorldHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(staleData.Cases) != 2 {
+		t.Fatal("Not getting last known good data")
+	}
+
+	if _, stale := Stale(); !stale {
+		t.Fatal("World history should be stale")
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("World history was not refreshed in the background")
+	}
+
+	if _, stale := Stale(); stale {
+		t.Fatal("World history should not be stale after refreshing it")
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	data := mworld.WorldTimeline{
+		Cases:      mcountry.Timeline{{Date: "2020-03-09", Value: 10}, {Date: "2020-03-10", Value: 20}},
+		CasesDaily: mcountry.Timeline{{Date: "2020-03-09", Value: 10}, {Date: "2020-03-10", Value: -4}},
+	}
+
+	smoothed := Smooth(data, smoothing.Options{Method: smoothing.Trailing, Window: 2, ClipNegative: true})
+	if smoothed.CasesDaily[1].Value != 5 {
+		t.Fatalf("Wrong smoothed daily cases %v", smoothed.CasesDaily)
+	}
+
+	if smoothed.Cases[1].Value != 20 {
+		t.Fatalf("The cumulative series should not be smoothed %v", smoothed.Cases)
+	}
+}
+
+func TestForecast(t *testing.T) {
+	reqCacheOB = requestCacheDataMock{}
+	requestCacheDataMockFunc = func() (mworld.WorldTimeline, bool, error) {
+		return mworld.WorldTimeline{
+			Cases:  timelineMock(10, 20, 30, 40, 50, 60, 70, 80, 90, 100),
+			Deaths: timelineMock(1, 2),
+		}, true, nil
+	}
+
+	projection, err := Forecast("", forecast.Options{Days: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.Country != Name || projection.Metric != forecast.DefaultMetric || len(projection.Forecast) != 5 {
+		t.Fatalf("Wrong forecast %+v", projection)
+	}
+
+	if _, err := Forecast("deaths", forecast.Options{}); !errors.Is(err, forecast.ErrNotEnoughData) {
+		t.Fatalf("Expected ErrNotEnoughData, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package forecast
+
+/*
+	Short term projections of a series with a prediction interval
+	and the error of the projection on the last days of the series
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mforecast "github.com/junkd0g/covid/lib/model/forecast"
+)
+
+// Forecasting methods
+const (
+	// LogLinear fits a straight line to the logarithm of the series,
+	// it extrapolates a constant growth rate
+	LogLinear = "loglinear"
+	// Holt is Holt's linear trend, exponential smoothing of the level
+	// and the trend of the series
+	Holt = "holt"
+)
+
+const (
+	// DefaultDays is the number of days projected when none is set
+	DefaultDays = 14
+	// MaxDays is the most days that can be projected
+	MaxDays = 60
+	// DefaultMethod is the method used when none is set
+	DefaultMethod = Holt
+	// Confidence is the probability of the prediction interval
+	Confidence = 0.95
+	// z is the standard normal quantile of the Confidence interval
+	z = 1.959964
+	// fitWindow is the number of the last days of the series a method fits
+	fitWindow = 28
+	// minPoints is the shortest series that can be projected
+	minPoints = 7
+)
+
+var (
+	// ErrUnknownMethod is returned when the method is not in Methods
+	ErrUnknownMethod = errors.New("unknown forecasting method")
+	// ErrInvalidDays is returned when the number of days to project is
+	// not between 1 and MaxDays
+	ErrInvalidDays = errors.New("invalid number of days")
+	// ErrNotEnoughData is returned when a series is shorter than a week
+	ErrNotEnoughData = errors.New("not enough data to forecast")
+)
+
+// method projects values for days, it returns the projected values
+// and the lower and upper bounds of their prediction interval
+type method func(values []float64, days int) ([]float64, []float64, []float64)
+
+var methods = map[string]method{
+	LogLinear: logLinear,
+	Holt:      holt,
+}
+
+// Methods returns the forecasting methods
+func Methods() []string {
+	return []string{LogLinear, Holt}
+}
+
+// Options of a projection, DefaultMethod and DefaultDays are used for
+// the ones that are not set
+type Options struct {
+	Method string
+	Days   int
+}
+
+// Validate returns the options with their defaults set
+// It returns an error wrapping ErrUnknownMethod or ErrInvalidDays
+func (o Options) Validate() (Options, error) {
+	if o.Method == "" {
+		o.Method = DefaultMethod
+	}
+	if o.Days == 0 {
+		o.Days = DefaultDays
+	}
+
+	if _, exist := methods[o.Method]; !exist {
+		return Options{}, fmt.Errorf("%w %q, expecting one of %s",
+			ErrUnknownMethod, o.Method, strings.Join(Methods(), ", "))
+	}
+	if o.Days < 1 || o.Days > MaxDays {
+		return Options{}, fmt.Errorf("%w %d, expecting 1 to %d", ErrInvalidDays, o.Days, MaxDays)
+	}
+
+	return o, nil
+}
+
+// Project returns the projection of a series for the days after its last
+// one and the error of the method on the last days of the series. The
+// projected values and their bounds are never negative
+// It returns an error wrapping ErrUnknownMethod, ErrInvalidDays or
+// ErrNotEnoughData
+func Project(series mcountry.Timeline, o Options) (mforecast.Forecast, error) {
+	o, err := o.Validate()
+	if err != nil {
+		return mforecast.Forecast{}, err
+	}
+	if len(series) < minPoints {
+		return mforecast.Forecast{}, fmt.Errorf("%w, %d days when at least %d are needed",
+			ErrNotEnoughData, len(series), minPoints)
+	}
+
+	last, err := time.Parse(mcountry.DateLayout, series[len(series)-1].Date)
+	if err != nil {
+		return mforecast.Forecast{}, err
+	}
+
+	project := methods[o.Method]
+	values, lower, upper := project(series.Values(), o.Days)
+
+	points := make([]mforecast.Point, 0, o.Days)
+	for i := range values {
+		points = append(points, mforecast.Point{
+			Date:  last.AddDate(0, 0, i+1).Format(mcountry.DateLayout),
+			Value: finite(values[i]),
+			Lower: finite(lower[i]),
+			Upper: finite(upper[i]),
+		})
+	}
+
+	return mforecast.Forecast{
+		Method:     o.Method,
+		Days:       o.Days,
+		Confidence: Confidence,
+		Forecast:   points,
+		Backtest:   backtest(series.Values(), project, o.Days),
+	}, nil
+}
+
+// backtest projects the last days of values, as many as the projection
+// has but leaving at least minPoints to fit, from the values before them
+func backtest(values []float64, project method, days int) mforecast.Backtest {
+	holdout := days
+	if len(values)-holdout < minPoints {
+		holdout = len(values) - minPoints
+	}
+	if holdout < 1 {
+		return mforecast.Backtest{}
+	}
+
+	fit, actual := values[:len(values)-holdout], values[len(values)-holdout:]
+	projected, _, _ := project(fit, holdout)
+
+	return mforecast.Backtest{Days: holdout, MAPE: MAPE(actual, projected)}
+}
+
+// MAPE returns the mean absolute percentage error of projected, the days
+// that are zero in actual are left out. It returns nil if all of them are
+func MAPE(actual []float64, projected []float64) *float64 {
+	sum, n := 0.0, 0
+	for i := range actual {
+		if actual[i] == 0 || i >= len(projected) {
+			continue
+		}
+		sum += math.Abs((actual[i] - finite(projected[i])) / actual[i])
+		n++
+	}
+
+	if n == 0 {
+		return nil
+	}
+	mape := finite(100 * sum / float64(n))
+	return &mape
+}
+
+// finite returns v as a value that can be projected, zero when it is
+// negative or not a number and the largest float when it overflows
+func finite(v float64) float64 {
+	if math.IsNaN(v) || v < 0 {
+		return 0
+	}
+	return math.Min(v, math.MaxFloat64)
+}
+
+// lastDays returns the last fitWindow values
+func lastDays(values []float64) []float64 {
+	if len(values) > fitWindow {
+		return values[len(values)-fitWindow:]
+	}
+	return values
+}
+
+// logLinear fits log(1+v) to the day with least squares and extrapolates
+// the line, the interval is the prediction interval of the regression
+func logLinear(values []float64, days int) ([]float64, []float64, []float64) {
+	fit := lastDays(values)
+	n := float64(len(fit))
+
+	var meanX, meanY float64
+	y := make([]float64, len(fit))
+	for i, v := range fit {
+		y[i] = math.Log1p(math.Max(v, 0))
+		meanX += float64(i)
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var sxx, sxy float64
+	for i := range fit {
+		sxx += (float64(i) - meanX) * (float64(i) - meanX)
+		sxy += (float64(i) - meanX) * (y[i] - meanY)
+	}
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i := range fit {
+		residual := y[i] - (intercept + slope*float64(i))
+		sse += residual * residual
+	}
+	s := math.Sqrt(sse / (n - 2))
+
+	projected, lower, upper := make([]float64, days), make([]float64, days), make([]float64, days)
+	for h := 1; h <= days; h++ {
+		x := n - 1 + float64(h)
+		estimate := intercept + slope*x
+		margin := z * s * math.Sqrt(1+1/n+(x-meanX)*(x-meanX)/sxx)
+		projected[h-1] = math.Expm1(estimate)
+		lower[h-1] = math.Expm1(estimate - margin)
+		upper[h-1] = math.Expm1(estimate + margin)
+	}
+
+	return projected, lower, upper
+}
+
+// holt smooths the level and the trend of the values with the weights
+// (alpha, beta) that have the least squared error one day ahead, the
+// interval comes from the variance of those errors
+func holt(values []float64, days int) ([]float64, []float64, []float64) {
+	fit := lastDays(values)
+
+	best := holtFit{sse: math.Inf(1)}
+	for a := 1; a <= 9; a++ {
+		for b := 1; b <= 9; b++ {
+			if f := fitHolt(fit, float64(a)/10, float64(b)/10); f.sse < best.sse {
+				best = f
+			}
+		}
+	}
+
+	sigma := 0.0
+	if residuals := len(fit) - 2; residuals > 0 {
+		sigma = math.Sqrt(best.sse / float64(residuals))
+	}
+
+	projected, lower, upper := make([]float64, days), make([]float64, days), make([]float64, days)
+	variance := 0.0
+	for h := 1; h <= days; h++ {
+		// the variance of h days ahead is sigma² (1 + Σ (alpha (1 + j beta))²), j < h
+		if h > 1 {
+			c := best.alpha * (1 + float64(h-1)*best.beta)
+			variance += c * c
+		}
+		estimate := best.level + float64(h)*best.trend
+		margin := z * sigma * math.Sqrt(1+variance)
+		projected[h-1] = estimate
+		lower[h-1] = estimate - margin
+		upper[h-1] = estimate + margin
+	}
+
+	return projected, lower, upper
+}
+
+type holtFit struct {
+	alpha, beta  float64
+	level, trend float64
+	sse          float64
+}
+
+// fitHolt smooths the values with alpha and beta, starting from the level
+// of the first value and the trend of the first two
+func fitHolt(values []float64, alpha float64, beta float64) holtFit {
+	f := holtFit{alpha: alpha, beta: beta, level: values[0]}
+	if len(values) > 1 {
+		f.trend = values[1] - values[0]
+	}
+
+	for _, v := range values[1:] {
+		residual := v - (f.level + f.trend)
+		f.sse += residual * residual
+
+		level := alpha*v + (1-alpha)*(f.level+f.trend)
+		f.trend = beta*(level-f.level) + (1-beta)*f.trend
+		f.level = level
+	}
+
+	return f
+}
@@ -0,0 +1,154 @@
+package forecast
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+func seriesMock(f func(i int) float64, days int) mcountry.Timeline {
+	start, _ := time.Parse(mcountry.DateLayout, "2020-03-01")
+	series := make(mcountry.Timeline, 0, days)
+	for i := 0; i < days; i++ {
+		series = append(series, mcountry.TimelinePoint{
+			Date:  start.AddDate(0, 0, i).Format(mcountry.DateLayout),
+			Value: f(i),
+		})
+	}
+	return series
+}
+
+func TestValidate(t *testing.T) {
+	o, err := Options{}.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Method != DefaultMethod || o.Days != DefaultDays {
+		t.Fatalf("Wrong default options %+v", o)
+	}
+
+	if _, err := (Options{Method: "arima"}).Validate(); !errors.Is(err, ErrUnknownMethod) {
+		t.Fatalf("Expected ErrUnknownMethod, got %v", err)
+	}
+	for _, days := range []int{-1, MaxDays + 1} {
+		if _, err := (Options{Days: days}).Validate(); !errors.Is(err, ErrInvalidDays) {
+			t.Fatalf("Expected ErrInvalidDays for %d days, got %v", days, err)
+		}
+	}
+}
+
+func TestProjectLogLinear(t *testing.T) {
+	growth := func(i int) float64 { return math.Expm1(0.1 * float64(i)) }
+	series := seriesMock(growth, 40)
+
+	forecast, err := Project(series, Options{Method: LogLinear, Days: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(forecast.Forecast) != 5 || forecast.Forecast[0].Date != "2020-04-10" || forecast.Forecast[4].Date != "2020-04-14" {
+		t.Fatalf("Wrong forecast dates %+v", forecast.Forecast)
+	}
+	for h, p := range forecast.Forecast {
+		if want := growth(40 + h); math.Abs(p.Value-want)/want > 1e-6 || p.Upper-p.Lower > 1e-3*want {
+			t.Fatalf("Wrong projection %+v, expecting %v", p, want)
+		}
+	}
+
+	if forecast.Backtest.Days != 5 || forecast.Backtest.MAPE == nil || *forecast.Backtest.MAPE > 1e-6 {
+		t.Fatalf("Wrong backtest %+v", forecast.Backtest)
+	}
+}
+
+func TestProjectHolt(t *testing.T) {
+	series := seriesMock(func(i int) float64 { return 10 + 5*float64(i) }, 20)
+
+	forecast, err := Project(series, Options{Method: Holt, Days: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for h, p := range forecast.Forecast {
+		if want := 10 + 5*float64(20+h); math.Abs(p.Value-want) > 1e-9 {
+			t.Fatalf("Wrong projection %+v, expecting %v", p, want)
+		}
+	}
+	if forecast.Method != Holt || forecast.Confidence != Confidence || *forecast.Backtest.MAPE > 1e-9 {
+		t.Fatalf("Wrong forecast %+v", forecast)
+	}
+}
+
+func TestProjectIntervals(t *testing.T) {
+	noisy := func(i int) float64 { return 100 + 3*float64(i) + 20*math.Sin(float64(i)) }
+
+	for _, method := range Methods() {
+		forecast, err := Project(seriesMock(noisy, 30), Options{Method: method, Days: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		width := 0.0
+		for _, p := range forecast.Forecast {
+			if p.Lower < 0 || p.Lower > p.Value || p.Upper < p.Value || p.Upper-p.Lower < width {
+				t.Fatalf("%s: the interval should hold the value and widen %+v", method, forecast.Forecast)
+			}
+			width = p.Upper - p.Lower
+		}
+	}
+
+	decline := seriesMock(func(i int) float64 { return 100 - 10*float64(i) }, 10)
+	forecast, _ := Project(decline, Options{Method: Holt, Days: 5})
+	if last := forecast.Forecast[4]; last.Value != 0 || last.Lower != 0 {
+		t.Fatalf("The projection should not be negative %+v", last)
+	}
+}
+
+func TestProjectShortSeries(t *testing.T) {
+	if _, err := Project(seriesMock(func(i int) float64 { return 1 }, 6), Options{}); !errors.Is(err, ErrNotEnoughData) {
+		t.Fatalf("Expected ErrNotEnoughData, got %v", err)
+	}
+
+	forecast, err := Project(seriesMock(func(i int) float64 { return 1 }, 9), Options{Days: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forecast.Backtest.Days != 2 {
+		t.Fatalf("The backtest should leave a week to fit %+v", forecast.Backtest)
+	}
+
+	forecast, _ = Project(seriesMock(func(i int) float64 { return 1 }, 7), Options{})
+	if forecast.Backtest.Days != 0 || forecast.Backtest.MAPE != nil {
+		t.Fatalf("A week is too short to backtest %+v", forecast.Backtest)
+	}
+}
+
+func TestMAPE(t *testing.T) {
+	if mape := MAPE([]float64{100, 0, 50}, []float64{110, 5, 40}); mape == nil || math.Abs(*mape-15) > 1e-9 {
+		t.Fatalf("Wrong MAPE %v", mape)
+	}
+	if mape := MAPE([]float64{0, 0}, []float64{1, 2}); mape != nil {
+		t.Fatalf("The MAPE of zeros should be nil, got %v", *mape)
+	}
+}
+
+func TestProjectOverflow(t *testing.T) {
+	explosive := seriesMock(func(i int) float64 { return math.Pow(10, float64(10*i)) }, 30)
+
+	for _, method := range Methods() {
+		forecast, err := Project(explosive, Options{Method: method, Days: MaxDays})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range forecast.Forecast {
+			if math.IsInf(p.Value, 0) || math.IsNaN(p.Value) || math.IsInf(p.Upper, 0) || math.IsNaN(p.Lower) {
+				t.Fatalf("%s: the projection should be finite %+v", method, p)
+			}
+		}
+		if mape := forecast.Backtest.MAPE; mape != nil && (math.IsInf(*mape, 0) || math.IsNaN(*mape)) {
+			t.Fatalf("%s: the MAPE should be finite %v", method, *mape)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package forecast
+
+import (
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	curve "github.com/junkd0g/covid/lib/curve"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	mforecast "github.com/junkd0g/covid/lib/model/forecast"
+)
+
+// DefaultMetric is the metric projected when none is set
+const DefaultMetric = curve.MetricCases
+
+var (
+	countryData getCountryData
+)
+
+func init() {
+	countryData = countryOB{}
+}
+
+type countryOB struct{}
+
+type getCountryData interface {
+	getAllCountries() ([]mcountry.CountryCurve, error)
+}
+
+func (r countryOB) getAllCountries() ([]mcountry.CountryCurve, error) {
+	return curve.GetAllCountries()
+}
+
+// ValidateMetric returns the metric and the options of a projection with
+// their defaults set
+// It returns an error wrapping curve.ErrUnknownMetric, ErrUnknownMethod
+// or ErrInvalidDays
+func ValidateMetric(metric string, o Options) (string, Options, error) {
+	if metric == "" {
+		metric = DefaultMetric
+	}
+	if _, err := curve.Series(mcountry.MainCurveData{}, metric); err != nil {
+		return "", Options{}, err
+	}
+
+	o, err := o.Validate()
+	return metric, o, err
+}
+
+// Metric returns the projection of a metric of the curves of a country,
+// or of the world, named name
+// It returns the errors of ValidateMetric and Project
+func Metric(name string, data mcountry.MainCurveData, metric string, o Options) (mforecast.Forecast, error) {
+	metric, o, err := ValidateMetric(metric, o)
+	if err != nil {
+		return mforecast.Forecast{}, err
+	}
+
+	series, err := curve.Series(data, metric)
+	if err != nil {
+		return mforecast.Forecast{}, err
+	}
+
+	projection, err := Project(series, o)
+	if err != nil {
+		applogger.Log("ERROR", "forecast", "Metric", err.Error())
+		return mforecast.Forecast{}, err
+	}
+
+	projection.Country = name
+	projection.Metric = metric
+	return projection, nil
+}
+
+// Country returns the projection of a metric of a country
+// It returns curve.ErrCountryNotFound if there are no curves for the
+// country and the errors of Metric
+func Country(name string, metric string, o Options) (mforecast.Forecast, error) {
+	metric, o, err := ValidateMetric(metric, o)
+	if err != nil {
+		return mforecast.Forecast{}, err
+	}
+
+	countries, err := countryData.getAllCountries()
+	if err != nil {
+		applogger.Log("ERROR", "forecast", "Country", err.Error())
+		return mforecast.Forecast{}, err
+	}
+
+	country, err := curve.GetCountryBP(name, countries)
+	if err != nil {
+		applogger.Log("ERROR", "forecast", "Country", err.Error())
+		return mforecast.Forecast{}, err
+	}
+
+	return Metric(country.Country, curve.CurveData(country), metric, o)
+}
@@ -0,0 +1,116 @@
+package forecast
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	curve "github.com/junkd0g/covid/lib/curve"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+type countryDataMock struct{}
+
+var countryDataMockFunc func() ([]mcountry.CountryCurve, error)
+
+func (u countryDataMock) getAllCountries() ([]mcountry.CountryCurve, error) {
+	return countryDataMockFunc()
+}
+
+func doublingWeeklyMock(days int) mcountry.Timeline {
+	return seriesMock(func(i int) float64 { return 100 * math.Pow(2, float64(i)/7) }, days)
+}
+
+func TestCountry(t *testing.T) {
+	countryData = countryDataMock{}
+	countryDataMockFunc = func() ([]mcountry.CountryCurve, error) {
+		return []mcountry.CountryCurve{{
+			Country:  "Greece",
+			Timeline: mcountry.TimelineStruct{Cases: doublingWeeklyMock(30), Deaths: doublingWeeklyMock(30)},
+		}}, nil
+	}
+
+	projection, err := Country("GRC", "", Options{Days: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.Country != "Greece" || projection.Metric != DefaultMetric ||
+		projection.Method != DefaultMethod || len(projection.Forecast) != 7 {
+		t.Fatalf("Wrong forecast %+v", projection)
+	}
+
+	if _, err := Country("Atlantis", curve.MetricDeaths, Options{}); !errors.Is(err, curve.ErrCountryNotFound) {
+		t.Fatalf("Expected ErrCountryNotFound, got %v", err)
+	}
+}
+
+func TestMetric(t *testing.T) {
+	cases := doublingWeeklyMock(30)
+	data := mcountry.MainCurveData{Cases: cases, CasesPerDay: cases.Daily()}
+
+	projection, err := Metric("World", data, curve.MetricCasesPerDay, Options{Method: LogLinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.Country != "World" || projection.Metric != curve.MetricCasesPerDay || len(projection.Forecast) != DefaultDays {
+		t.Fatalf("Wrong forecast %+v", projection)
+	}
+
+	if _, err := Metric("World", data, curve.MetricDeaths, Options{}); !errors.Is(err, ErrNotEnoughData) {
+		t.Fatalf("Expected ErrNotEnoughData, got %v", err)
+	}
+}
+
+func TestValidateMetric(t *testing.T) {
+	if _, _, err := ValidateMetric("hospitalized", Options{}); !errors.Is(err, curve.ErrUnknownMetric) {
+		t.Fatalf("Expected ErrUnknownMetric, got %v", err)
+	}
+	if _, _, err := ValidateMetric("", Options{Days: 100}); !errors.Is(err, ErrInvalidDays) {
+		t.Fatalf("Expected ErrInvalidDays, got %v", err)
+	}
+}
+
+// fuzzSeries returns a cumulative series whose per day values are the
+// bytes as signed numbers times scale
+func fuzzSeries(data []byte, scale float64) mcountry.Timeline {
+	first, _ := time.Parse(mcountry.DateLayout, "2020-01-22")
+	series := make(mcountry.Timeline, 0, len(data))
+	total := 0.0
+	for i, b := range data {
+		total += float64(int8(b)) * scale
+		series = append(series, mcountry.TimelinePoint{
+			Date:  first.AddDate(0, 0, i).Format(mcountry.DateLayout),
+			Value: total,
+		})
+	}
+	return series
+}
+
+func FuzzMetric(f *testing.F) {
+	f.Add([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 14, uint8(0), uint8(0))
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0}, 1, uint8(1), uint8(1))
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 127, 127}, MaxDays, uint8(0), uint8(2))
+	f.Add([]byte{1, 2}, 0, uint8(1), uint8(3))
+
+	methods := Methods()
+	metrics := []string{curve.MetricCases, curve.MetricCasesPerDay, curve.MetricDeaths, curve.MetricDeathsPerDay}
+	f.Fuzz(func(t *testing.T, data []byte, days int, method uint8, metric uint8) {
+		cases := fuzzSeries(data, 1000)
+		deaths := fuzzSeries(data[len(data)/2:], 1)
+		curves := mcountry.MainCurveData{Cases: cases, CasesPerDay: cases.Daily(), Deaths: deaths, DeathsPerDay: deaths.Daily()}
+
+		o := Options{Days: days, Method: methods[int(method)%len(methods)]}
+		projection, err := Metric("World", curves, metrics[int(metric)%len(metrics)], o)
+		if err != nil {
+			return
+		}
+		if len(projection.Forecast) != projection.Days {
+			t.Fatalf("%d days projected instead of %d", len(projection.Forecast), projection.Days)
+		}
+		if _, err := json.Marshal(projection); err != nil {
+			t.Fatalf("%v: %+v", err, projection)
+		}
+	})
+}
@@ -2,27 +2,38 @@ package mcountry
 
 // CompareData is being used in lib/curve/curve.go
 type CompareData struct {
-	Country string    `json:"country"`
-	Data    []float64 `json:"data"`
+	Country string   `json:"country"`
+	Data    Timeline `json:"data"`
 }
 
 // CompareAll is being used in lib/curve/curve.go
 type CompareAll struct {
 	CountryOne CompareAllData `json:"countryOne"`
 	CountryTwo CompareAllData `json:"countryTwo"`
+	Stale      bool           `json:"stale,omitempty"`
 }
 
 // CompareAllData is being used in lib/curve/curve.go
 type CompareAllData struct {
-	Country             string    `json:"country"`
-	DataDeaths          []float64 `json:"dataDeaths"`
-	DataDeathsFromFirst []float64 `json:"dataDeathsFromFirst"`
-	DataDeathsPerDay    []float64 `json:"dataDeathsPerDay"`
-	DataRecovered       []float64 `json:"dataRecoverd"`
-	DataCases           []float64 `json:"dataCases"`
-	DataCasesFromFist   []float64 `json:"dataCasesFromFirst"`
+	Country             string   `json:"country"`
+	DataDeaths          Timeline `json:"dataDeaths"`
+	DataDeathsFromFirst Timeline `json:"dataDeathsFromFirst"`
+	DataDeathsPerDay    Timeline `json:"dataDeathsPerDay"`
+	DataRecovered       Timeline `json:"dataRecoverd"`
+	DataCases           Timeline `json:"dataCases"`
+	DataCasesFromFist   Timeline `json:"dataCasesFromFirst"`
 }
 
+// CompareCountries is the response of /api/compare, the series of
+// every metric keyed by country
+type CompareCountries struct {
+	Data  map[string]CompareMetrics `json:"data"`
+	Stale bool                      `json:"stale,omitempty"`
+}
+
+// CompareMetrics is the series of a country keyed by metric
+type CompareMetrics map[string]Timeline
+
 // CountryStats is being used in lib/curve/stats.go
 type CountryStats struct {
 	Country                   string `json:"country"`
@@ -32,17 +43,19 @@ type CountryStats struct {
 
 // TotalStats is being used in lib/curve/stats.go
 type TotalStats struct {
-	TodayPerCentOfTotalCases  int `json:"todayPerCentOfTotalCases"`
-	TodayPerCentOfTotalDeaths int `json:"todayPerCentOfTotalDeaths"`
-	TotalCases                int `json:"totalCases"`
-	TotalDeaths               int `json:"totalDeaths"`
-	TodayTotalCases           int `json:"todayTotalCases"`
-	TodayTotalDeaths          int `json:"todayTotalDeaths"`
+	TodayPerCentOfTotalCases  int  `json:"todayPerCentOfTotalCases"`
+	TodayPerCentOfTotalDeaths int  `json:"todayPerCentOfTotalDeaths"`
+	TotalCases                int  `json:"totalCases"`
+	TotalDeaths               int  `json:"totalDeaths"`
+	TodayTotalCases           int  `json:"todayTotalCases"`
+	TodayTotalDeaths          int  `json:"todayTotalDeaths"`
+	Stale                     bool `json:"stale,omitempty"`
 }
 
 // AllCountriesName is being used in lib/curve/stats.go
 type AllCountriesName struct {
 	Countries []string `json:"countries"`
+	Stale     bool     `json:"stale,omitempty"`
 }
 
 // CountryCurve is being used in lib/curve/curve.go
@@ -52,17 +65,56 @@ type CountryCurve struct {
 	Province string         `json:"province"`
 }
 
+// ProvinceCurve is the response of /api/curve/{country}/{province}, the
+// curves (total and per day) of a province ordered by date
+type ProvinceCurve struct {
+	Country         string   `json:"country"`
+	Province        string   `json:"province"`
+	Cases           Timeline `json:"cases"`
+	CasesPerDay     Timeline `json:"casesPerDay"`
+	Deaths          Timeline `json:"deaths"`
+	DeathsPerDay    Timeline `json:"deathsPerDay"`
+	Recovered       Timeline `json:"recovered"`
+	RecoveredPerDay Timeline `json:"recoveredPerDay"`
+	Stale           bool     `json:"stale,omitempty"`
+}
+
+// CountryProvinces is a country and the provinces it has curves for
+type CountryProvinces struct {
+	Country   string   `json:"country"`
+	Provinces []string `json:"provinces"`
+}
+
+// Provinces is the response of /api/curve/provinces
+type Provinces struct {
+	Data  []CountryProvinces `json:"data"`
+	Stale bool               `json:"stale,omitempty"`
+}
+
 // TimelineStruct is being used in lib/curve/curve.go
 type TimelineStruct struct {
-	Cases     interface{} `json:"cases"`
-	Deaths    interface{} `json:"deaths"`
-	Recovered interface{} `json:"recovered"`
+	Cases     Timeline `json:"cases"`
+	Deaths    Timeline `json:"deaths"`
+	Recovered Timeline `json:"recovered"`
 }
 
 // Countries is being used in controller/sort/sort.go,
 // lib/caching/caching.go and lib/stats/stats.go
 type Countries struct {
-	Data []Country `json:"data"`
+	Data  []Country `json:"data"`
+	Stale bool      `json:"stale,omitempty"`
+}
+
+// CountriesPage is the response of /api/countries, Data is an array of
+// Country or, when the request projects fields, of objects with only
+// those fields. Total is the number of countries that matched the query
+// before the limit and offset were applied
+type CountriesPage struct {
+	Data   interface{} `json:"data"`
+	Total  int         `json:"total"`
+	Limit  int         `json:"limit,omitempty"`
+	Offset int         `json:"offset"`
+	Stale  bool        `json:"stale,omitempty"`
 }
 
 // Country is being use in lib/curve/curve.go and lib/stats/stats.go
@@ -78,6 +130,7 @@ type Country struct {
 	CasesPerOneMillion float64 `json:"casesPerOneMillion"`
 	Test               int     `json:"tests"`
 	TestPerOneMillion  int     `json:"testsPerOneMillion"`
+	Population         int     `json:"population"`
 }
 
 // Compare is being used in lib/curve/curve.go
@@ -86,12 +139,22 @@ type Compare struct {
 	CountryTwo CompareData `json:"countryTwo"`
 }
 
+// MainCurveData is being used in lib/curve/curve.go, every series
+// is ordered by date. The per 100k and per million series are empty
+// when the population of the country is unknown
 type MainCurveData struct {
-	Deaths                     []float64
-	DeathsPerDay               []float64
-	DeathsPerDayFromFirstDeath []float64
-	Cases                      []float64
-	CasesPerDay                []float64
-	Recovered                  []float64
-	RecoveredPerDay            []float64
+	Deaths                     Timeline
+	DeathsPerDay               Timeline
+	DeathsPerDayFromFirstDeath Timeline
+	Cases                      Timeline
+	CasesPerDay                Timeline
+	Recovered                  Timeline
+	RecoveredPerDay            Timeline
+	Population                 int
+	DeathsPer100k              Timeline
+	DeathsPerMillion           Timeline
+	CasesPer100k               Timeline
+	CasesPerMillion            Timeline
+	RecoveredPer100k           Timeline
+	RecoveredPerMillion        Timeline
 }
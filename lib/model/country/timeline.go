@@ -0,0 +1,188 @@
+package mcountry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// upstreamDateLayout is the layout of the date keys in the third party
+// API's timelines ("3/22/20")
+const upstreamDateLayout = "1/2/06"
+
+// DateLayout is the layout of the dates we return in a Timeline
+const DateLayout = "2006-01-02"
+
+// TimelineError is returned when a timeline can not be parsed, either because
+// the JSON is neither a date keyed map nor an array of points or because
+// one of its dates is malformed
+type TimelineError struct {
+	Data string
+	Err  error
+}
+
+func (e *TimelineError) Error() string {
+	data := e.Data
+	if len(data) > 64 {
+		data = data[:64] + "..."
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("malformed timeline %q: %s", data, e.Err.Error())
+	}
+	return fmt.Sprintf("malformed timeline %q", data)
+}
+
+func (e *TimelineError) Unwrap() error {
+	return e.Err
+}
+
+// TimelinePoint is the value of a timeline for one day
+type TimelinePoint struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+// Timeline is an array of TimelinePoint ordered by date
+type Timeline []TimelinePoint
+
+// NewTimeline converts a date keyed map as we get it from the third party
+// API ( {"3/22/20": 5, "3/23/20": 8} ) to a Timeline ordered by date.
+// It returns an error if a key is not a date
+func NewTimeline(data map[string]float64) (Timeline, error) {
+	type datedValue struct {
+		date  time.Time
+		value float64
+	}
+
+	dated := make([]datedValue, 0, len(data))
+	for k, v := range data {
+		date, err := time.Parse(upstreamDateLayout, k)
+		if err != nil {
+			return Timeline{}, &TimelineError{Data: k, Err: err}
+		}
+		dated = append(dated, datedValue{date: date, value: v})
+	}
+
+	sort.Slice(dated, func(i, j int) bool {
+		return dated[i].date.Before(dated[j].date)
+	})
+
+	timeline := make(Timeline, 0, len(dated))
+	for _, v := range dated {
+		timeline = append(timeline, TimelinePoint{Date: v.date.Format(DateLayout), Value: v.value})
+	}
+
+	return timeline, nil
+}
+
+// UnmarshalJSON accepts a timeline in both the shapes we come across:
+// the date keyed map of the third party API ( {"3/22/20": 5} ) and the
+// array of points we return and cache ( [{"date": "2020-03-22", "value": 5}] ).
+// A null or empty timeline becomes an empty Timeline, anything else returns
+// a *TimelineError
+func (t *Timeline) UnmarshalJSON(b []byte) error {
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		*t = Timeline{}
+		return nil
+	}
+
+	switch trimmed[0] {
+	case '{':
+		var data map[string]float64
+		if err := json.Unmarshal(trimmed, &data); err != nil {
+			return &TimelineError{Data: string(trimmed), Err: err}
+		}
+		timeline, err := NewTimeline(data)
+		if err != nil {
+			return err
+		}
+		*t = timeline
+		return nil
+	case '[':
+		var points []TimelinePoint
+		if err := json.Unmarshal(trimmed, &points); err != nil {
+			return &TimelineError{Data: string(trimmed), Err: err}
+		}
+		for _, v := range points {
+			if _, err := time.Parse(DateLayout, v.Date); err != nil {
+				return &TimelineError{Data: v.Date, Err: err}
+			}
+		}
+		sort.SliceStable(points, func(i, j int) bool {
+			return points[i].Date < points[j].Date
+		})
+		*t = Timeline(points)
+		return nil
+	}
+
+	return &TimelineError{Data: string(trimmed)}
+}
+
+// Values returns the values of the timeline without their dates
+func (t Timeline) Values() []float64 {
+	values := make([]float64, 0, len(t))
+	for _, v := range t {
+		values = append(values, v.Value)
+	}
+	return values
+}
+
+// Daily converts a cumulative timeline to the per day difference of it.
+// The first day is dropped as there is no previous day to compare with.
+// Downward corrections of the cumulative value come back as negative values
+func (t Timeline) Daily() Timeline {
+	daily := make(Timeline, 0, len(t))
+	for i := 1; i < len(t); i++ {
+		daily = append(daily, TimelinePoint{Date: t[i].Date, Value: t[i].Value - t[i-1].Value})
+	}
+	return daily
+}
+
+// FromFirstNonZero returns the timeline starting from the first day
+// with a value other than zero
+func (t Timeline) FromFirstNonZero() Timeline {
+	for i, v := range t {
+		if v.Value != 0 {
+			return t[i:]
+		}
+	}
+	return Timeline{}
+}
+
+// PerCapita returns the timeline as values for every per people of a
+// population, an empty timeline if the population is not positive
+func (t Timeline) PerCapita(population int, per float64) Timeline {
+	if population <= 0 {
+		return Timeline{}
+	}
+
+	scaled := make(Timeline, 0, len(t))
+	for _, v := range t {
+		scaled = append(scaled, TimelinePoint{Date: v.Date, Value: v.Value * per / float64(population)})
+	}
+	return scaled
+}
+
+// Add returns the sum of the values of two timelines on every date of
+// either of them, ordered by date
+func (t Timeline) Add(o Timeline) Timeline {
+	values := make(map[string]float64, len(t)+len(o))
+	for _, v := range t {
+		values[v.Date] += v.Value
+	}
+	for _, v := range o {
+		values[v.Date] += v.Value
+	}
+
+	sum := make(Timeline, 0, len(values))
+	for date, value := range values {
+		sum = append(sum, TimelinePoint{Date: date, Value: value})
+	}
+	sort.Slice(sum, func(i, j int) bool {
+		return sum[i].Date < sum[j].Date
+	})
+	return sum
+}
@@ -0,0 +1,122 @@
+package mcountry
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewTimeline(t *testing.T) {
+	timeline, err := NewTimeline(map[string]float64{
+		"3/10/20": 5.0,
+		"1/22/20": 0.0,
+		"3/9/20":  7.0,
+		"2/26/20": 1.0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDates := []string{"2020-01-22", "2020-02-26", "2020-03-09", "2020-03-10"}
+	expectedValues := []float64{0, 1, 7, 5}
+	for i, v := range timeline {
+		if v.Date != expectedDates[i] || v.Value != expectedValues[i] {
+			t.Fatalf("Wrong point %v in position %d", v, i)
+		}
+	}
+
+	daily := timeline.Daily()
+	if len(daily) != 3 {
+		t.Fatalf("Wrong length of daily timeline %d", len(daily))
+	}
+
+	if daily[2].Date != "2020-03-10" || daily[2].Value != -2 {
+		t.Fatalf("Downward correction is missing %v", daily[2])
+	}
+
+	fromFirst := timeline.FromFirstNonZero()
+	if len(fromFirst) != 3 || fromFirst[0].Date != "2020-02-26" {
+		t.Fatalf("Wrong timeline from first non zero value %v", fromFirst)
+	}
+
+	_, errBadDate := NewTimeline(map[string]float64{"yesterday": 1.0})
+	if errBadDate == nil {
+		t.Fatal("Expecting an error for a key that is not a date")
+	}
+}
+
+func TestTimelineUnmarshalJSON(t *testing.T) {
+	var fromMap TimelineStruct
+	errMap := json.Unmarshal([]byte(`{"cases": {"3/23/20": 8, "3/22/20": 5}, "deaths": null, "recovered": {}}`), &fromMap)
+	if errMap != nil {
+		t.Fatal(errMap)
+	}
+
+	if len(fromMap.Cases) != 2 || fromMap.Cases[0].Date != "2020-03-22" || fromMap.Cases[1].Value != 8 {
+		t.Fatalf("Wrong cases from map shape %v", fromMap.Cases)
+	}
+
+	if len(fromMap.Deaths) != 0 || len(fromMap.Recovered) != 0 {
+		t.Fatalf("Null and empty timelines should be empty %v %v", fromMap.Deaths, fromMap.Recovered)
+	}
+
+	marshaled, errMarshal := json.Marshal(fromMap)
+	if errMarshal != nil {
+		t.Fatal(errMarshal)
+	}
+
+	var fromArray TimelineStruct
+	if errArray := json.Unmarshal(marshaled, &fromArray); errArray != nil {
+		t.Fatal(errArray)
+	}
+
+	if !reflect.DeepEqual(fromMap.Cases, fromArray.Cases) {
+		t.Fatalf("Array shape %v is not equal to map shape %v", fromArray.Cases, fromMap.Cases)
+	}
+
+	malformed := []string{
+		`{"cases": 44}`,
+		`{"cases": "3/22/20"}`,
+		`{"cases": {"3/22/20": "five"}}`,
+		`{"cases": {"today": 5}}`,
+		`{"cases": [{"date": "22/03/2020", "value": 5}]}`,
+	}
+
+	for _, v := range malformed {
+		var timeline TimelineStruct
+		err := json.Unmarshal([]byte(v), &timeline)
+		var timelineErr *TimelineError
+		if !errors.As(err, &timelineErr) {
+			t.Fatalf("Expecting a TimelineError for %s but having %v", v, err)
+		}
+	}
+}
+
+func TestTimelinePerCapita(t *testing.T) {
+	timeline := Timeline{{Date: "2020-03-09", Value: 50}, {Date: "2020-03-10", Value: 200}}
+
+	perMillion := timeline.PerCapita(2000000, 1000000)
+	if !reflect.DeepEqual(perMillion.Values(), []float64{25, 100}) || perMillion[1].Date != "2020-03-10" {
+		t.Fatalf("Wrong timeline per million %v", perMillion)
+	}
+
+	if unknown := timeline.PerCapita(0, 100000); len(unknown) != 0 {
+		t.Fatalf("Expecting an empty timeline for an unknown population having %v", unknown)
+	}
+}
+
+func TestTimelineAdd(t *testing.T) {
+	one := Timeline{{Date: "2020-03-09", Value: 50}, {Date: "2020-03-10", Value: 200}}
+	two := Timeline{{Date: "2020-03-10", Value: 5}, {Date: "2020-03-11", Value: 7}}
+
+	sum := one.Add(two)
+	expected := Timeline{{Date: "2020-03-09", Value: 50}, {Date: "2020-03-10", Value: 205}, {Date: "2020-03-11", Value: 7}}
+	if !reflect.DeepEqual(sum, expected) {
+		t.Fatalf("Wrong sum of timelines %v", sum)
+	}
+
+	if empty := (Timeline{}).Add(nil); len(empty) != 0 {
+		t.Fatalf("Expecting an empty sum having %v", empty)
+	}
+}
@@ -4,14 +4,21 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"sort"
+	"time"
 
 	"github.com/junkd0g/covid/lib/applogger"
 )
 
+// UpdatedAtLayout is the layout of the update time of the third party API
+const UpdatedAtLayout = "2006-01-02 15:04:05"
+
+// ResponseCountry is a row of the third party API, a county or a
+// province of a country. County is nil for the rows that are not counties
 type ResponseCountry struct {
-	Country   string      `json:"country"`
-	County    interface{} `json:"county"`
-	UpdatedAt string      `json:"updatedAt"`
+	Country   string  `json:"country"`
+	County    *string `json:"county"`
+	UpdatedAt string  `json:"updatedAt"`
 	Stats     struct {
 		Confirmed int `json:"confirmed"`
 		Deaths    int `json:"deaths"`
@@ -29,18 +36,143 @@ type CSSEResponse struct {
 	Data []CSEECountryResponse `json:"data"`
 }
 
-//CSEECountryResponse response that we will return on /api/csse/{country}
+//CSEECountryResponse response that we will return on /api/csse/{country},
+//Totals are the totals of the country and Provinces of each of its provinces
 type CSEECountryResponse struct {
-	Country string          `json:"country"`
-	Data    []CSEEProvision `json:"data"`
+	Country   string            `json:"country"`
+	Totals    Totals            `json:"totals"`
+	Provinces map[string]Totals `json:"provinces"`
+	Data      []CSEEProvision   `json:"data"`
+	Stale     bool              `json:"stale,omitempty"`
+}
+
+// Totals are the cases, deaths and recovered of a county, a province,
+// a country or of every country
+type Totals struct {
+	Cases     int `json:"cases"`
+	Deaths    int `json:"deaths"`
+	Recovered int `json:"recovered"`
+}
+
+// Add adds the stats of a county or a province to the totals
+func (t *Totals) Add(p CSEEProvision) {
+	t.Cases += p.Cases
+	t.Deaths += p.Deaths
+	t.Recovered += p.Recovered
+}
+
+// Dataset is the csse data indexed by country, province and county with
+// the totals of each of them. It is built once every time the data are
+// requested from the third party API and cached as it is, UpdatedAt is
+// when it was built. The names are not cached, Sort orders them once the
+// dataset is built or decoded
+type Dataset struct {
+	Totals    Totals                   `json:"totals"`
+	Countries map[string]*CountryIndex `json:"countries"`
+	UpdatedAt time.Time                `json:"updatedAt"`
+	Names     []string                 `json:"-"`
+}
+
+// CountryIndex is a country of a Dataset with its provinces keyed by name
+type CountryIndex struct {
+	Country       string                    `json:"country"`
+	Totals        Totals                    `json:"totals"`
+	Provinces     map[string]*ProvinceIndex `json:"provinces"`
+	ProvinceNames []string                  `json:"-"`
+}
+
+// ProvinceIndex is a province of a country with its counties keyed by name,
+// the province itself is keyed by an empty name
+type ProvinceIndex struct {
+	Province    string                   `json:"province"`
+	Totals      Totals                   `json:"totals"`
+	Counties    map[string]CSEEProvision `json:"counties"`
+	CountyNames []string                 `json:"-"`
+}
+
+// Sort sets the names of the countries of the dataset, of the provinces
+// of every country and of the counties of every province in ascending order
+func (d *Dataset) Sort() {
+	d.Names = make([]string, 0, len(d.Countries))
+	for name, country := range d.Countries {
+		d.Names = append(d.Names, name)
+
+		country.ProvinceNames = make([]string, 0, len(country.Provinces))
+		for provinceName, province := range country.Provinces {
+			country.ProvinceNames = append(country.ProvinceNames, provinceName)
+
+			province.CountyNames = make([]string, 0, len(province.Counties))
+			for county := range province.Counties {
+				province.CountyNames = append(province.CountyNames, county)
+			}
+			sort.Strings(province.CountyNames)
+		}
+		sort.Strings(country.ProvinceNames)
+	}
+	sort.Strings(d.Names)
+}
+
+// Summary is the response of /api/csse, the totals of every country
+type Summary struct {
+	Totals    Totals          `json:"totals"`
+	Countries []CountryTotals `json:"countries"`
+	Stale     bool            `json:"stale,omitempty"`
+}
+
+// CountryTotals are the totals of a country and how many provinces it has
+type CountryTotals struct {
+	Country   string `json:"country"`
+	Provinces int    `json:"provinces"`
+	Totals    Totals `json:"totals"`
 }
 
+// CSEEProvision is a county or a province of a country, County is null
+// for a province and Coordinates and UpdatedAt when they are unknown
 type CSEEProvision struct {
-	County    string `json:"county"`
-	Province  string `json:"province"`
-	Cases     int    `json:"cases"`
-	Deaths    int    `json:"deaths"`
-	Recovered int    `json:"recovered"`
+	County      *string      `json:"county"`
+	Province    string       `json:"province"`
+	Cases       int          `json:"cases"`
+	Deaths      int          `json:"deaths"`
+	Recovered   int          `json:"recovered"`
+	Coordinates *Coordinates `json:"coordinates"`
+	UpdatedAt   *time.Time   `json:"updatedAt"`
+}
+
+// Coordinates is the latitude and longitude of a county or a province
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// FeatureCollection is the GeoJSON response of /api/csse/{country}/geojson,
+// a Point Feature for every county or province that has coordinates
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature of a county or a province
+type Feature struct {
+	Type       string            `json:"type"`
+	Geometry   Geometry          `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// Geometry is a GeoJSON Point, its Coordinates are longitude and latitude
+type Geometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// FeatureProperties are the stats of the county or the province of a Feature
+type FeatureProperties struct {
+	Country   string     `json:"country"`
+	Province  string     `json:"province"`
+	County    *string    `json:"county"`
+	Cases     int        `json:"cases"`
+	Deaths    int        `json:"deaths"`
+	Recovered int        `json:"recovered"`
+	UpdatedAt *time.Time `json:"updatedAt"`
 }
 
 type CSSEOB struct{}
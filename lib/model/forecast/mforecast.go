@@ -0,0 +1,31 @@
+package mforecast
+
+// Forecast is the response of /api/forecast/{country}, the projection
+// of a metric of a country or the world for the next Days days
+type Forecast struct {
+	Country    string   `json:"country"`
+	Metric     string   `json:"metric"`
+	Method     string   `json:"method"`
+	Days       int      `json:"days"`
+	Confidence float64  `json:"confidence"`
+	Forecast   []Point  `json:"forecast"`
+	Backtest   Backtest `json:"backtest"`
+	Stale      bool     `json:"stale,omitempty"`
+}
+
+// Point is the projected value of a day and its prediction interval
+type Point struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Backtest is the error of the method when it projects the last Days
+// days of the series from the days before them. MAPE is the mean absolute
+// percentage error, null when the series is too short or the last days
+// are all zero
+type Backtest struct {
+	Days int      `json:"days"`
+	MAPE *float64 `json:"mape"`
+}
@@ -1,8 +1,12 @@
 package mhotspot
 
+import (
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
 type CompareHotspotData struct {
-	Country string    `json:"country"`
-	Data    []float64 `json:"data"`
+	Country string            `json:"country"`
+	Data    mcountry.Timeline `json:"data"`
 }
 
 type Hotspot struct {
@@ -12,4 +16,28 @@ type Hotspot struct {
 	MostDeaths   CompareHotspotData `json:"mostDeaths"`
 	SecondDeaths CompareHotspotData `json:"secondDeaths"`
 	ThirdDeaths  CompareHotspotData `json:"thirdDeaths"`
+	Stale        bool               `json:"stale,omitempty"`
+}
+
+// Ranking is the response of /api/hotspot/{days} when it is asked for a
+// ranking, the top countries of a metric over the last Days days
+type Ranking struct {
+	Metric    string          `json:"metric"`
+	Days      int             `json:"days"`
+	By        string          `json:"by"`
+	Continent string          `json:"continent,omitempty"`
+	Countries []RankedCountry `json:"countries"`
+	Stale     bool            `json:"stale,omitempty"`
+}
+
+// RankedCountry is a country of a Ranking. Value is what the country is
+// ranked by, Total the sum of the metric over the last days and Previous
+// the sum over the days before them when ranking by acceleration
+type RankedCountry struct {
+	Rank     int               `json:"rank"`
+	Country  string            `json:"country"`
+	Value    float64           `json:"value"`
+	Total    float64           `json:"total"`
+	Previous *float64          `json:"previous,omitempty"`
+	Data     mcountry.Timeline `json:"data"`
 }
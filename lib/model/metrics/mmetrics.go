@@ -0,0 +1,35 @@
+package mmetrics
+
+import (
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// GrowthMetrics is the response of /api/metrics/{country}, the growth
+// metrics of the cases and the deaths of a country or the world
+type GrowthMetrics struct {
+	Country string        `json:"country"`
+	Cases   SeriesMetrics `json:"cases"`
+	Deaths  SeriesMetrics `json:"deaths"`
+	Stale   bool          `json:"stale,omitempty"`
+}
+
+// SeriesMetrics are the growth metrics of a cumulative series, every
+// series is ordered by date and has no point for the days a metric can
+// not be computed. Rt is only estimated for the cases
+type SeriesMetrics struct {
+	GrowthRate   mcountry.Timeline `json:"growthRate"`
+	DoublingTime mcountry.Timeline `json:"doublingTime"`
+	WeekOverWeek mcountry.Timeline `json:"weekOverWeek"`
+	Rt           mcountry.Timeline `json:"rt,omitempty"`
+	Latest       Latest            `json:"latest"`
+}
+
+// Latest are the metrics of the last day of a series, a metric is null
+// when it can not be computed for that day
+type Latest struct {
+	Date         string   `json:"date"`
+	GrowthRate   *float64 `json:"growthRate"`
+	DoublingTime *float64 `json:"doublingTime"`
+	WeekOverWeek *float64 `json:"weekOverWeek"`
+	Rt           *float64 `json:"rt,omitempty"`
+}
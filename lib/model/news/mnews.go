@@ -43,11 +43,24 @@ type AllArticlesData struct {
 	VaccineArticles   ArticlesData `json:"vaccine"`
 	TreatmentArticles ArticlesData `json:"treament"`
 	NewsArticles      ArticlesData `json:"news"`
+	Stale             bool         `json:"stale,omitempty"`
 }
 
 // ArticlesData is being used in lib/news/news.go
 type ArticlesData struct {
 	Articles []Article `json:"data"`
+	Stale    bool      `json:"stale,omitempty"`
+}
+
+// ArticlesPage is the response of /api/news, /api/news/vaccine and
+// /api/news/treatment, Total is the number of articles before the
+// limit and offset were applied
+type ArticlesPage struct {
+	Articles []Article `json:"data"`
+	Total    int       `json:"total"`
+	Limit    int       `json:"limit,omitempty"`
+	Offset   int       `json:"offset"`
+	Stale    bool      `json:"stale,omitempty"`
 }
 
 // Article is being used in lib/news/news.go
@@ -0,0 +1,10 @@
+package mregistry
+
+// NotFound is the body of the 404 responses for a country that matches
+// none, Message and Status are the fields of every error response
+type NotFound struct {
+	Message     string   `json:"message"`
+	Status      int      `json:"status"`
+	Country     string   `json:"country"`
+	Suggestions []string `json:"suggestions"`
+}
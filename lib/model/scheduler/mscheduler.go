@@ -0,0 +1,21 @@
+package mscheduler
+
+import "time"
+
+// Status is the response of /api/status
+type Status struct {
+	Running bool        `json:"running"`
+	Jobs    []JobStatus `json:"jobs"`
+}
+
+// JobStatus is the state of a dataset's background refresh
+type JobStatus struct {
+	Name                string     `json:"name"`
+	Interval            float64    `json:"interval"`
+	Runs                int        `json:"runs"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty"`
+	LastFailure         *time.Time `json:"lastFailure,omitempty"`
+	LastError           string     `json:"lastError,omitempty"`
+	NextRun             *time.Time `json:"nextRun,omitempty"`
+}
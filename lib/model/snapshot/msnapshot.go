@@ -0,0 +1,52 @@
+package msnapshot
+
+import (
+	"time"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// Snapshot is the stats of all countries as they were fetched at TakenAt,
+// it is stored as a line of a daily ndjson file
+type Snapshot struct {
+	TakenAt   time.Time          `json:"takenAt"`
+	Countries []mcountry.Country `json:"countries"`
+}
+
+// CountrySnapshot is the response of /api/snapshot/{country}/{date},
+// the last stats of a country that were fetched on Date
+type CountrySnapshot struct {
+	Date    string           `json:"date"`
+	TakenAt time.Time        `json:"takenAt"`
+	Country mcountry.Country `json:"data"`
+}
+
+// CountryDiff is the response of /api/snapshot/{country}/{date}/diff,
+// the change of a country's stats from the last snapshot of PreviousDate
+// to the last snapshot of Date
+type CountryDiff struct {
+	Country      string        `json:"country"`
+	Date         string        `json:"date"`
+	PreviousDate string        `json:"previousDate"`
+	Diff         CountryValues `json:"diff"`
+}
+
+// CountryValues is the difference of every value of mcountry.Country
+type CountryValues struct {
+	Cases              int     `json:"cases"`
+	TodayCases         int     `json:"todayCases"`
+	Deaths             int     `json:"deaths"`
+	TodayDeaths        int     `json:"todayDeaths"`
+	Recovered          int     `json:"recovered"`
+	Active             int     `json:"active"`
+	Critical           int     `json:"critical"`
+	CasesPerOneMillion float64 `json:"casesPerOneMillion"`
+	Test               int     `json:"tests"`
+	TestPerOneMillion  int     `json:"testsPerOneMillion"`
+	Population         int     `json:"population"`
+}
+
+// Dates is the response of /api/snapshot/dates
+type Dates struct {
+	Dates []string `json:"dates"`
+}
@@ -1,10 +1,17 @@
 package mworld
 
+import (
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// WorldTimeline is the world's history for covid-19, every series
+// is ordered by date
 type WorldTimeline struct {
-	Cases          interface{} `json:"cases"`
-	Deaths         interface{} `json:"deaths"`
-	Recovered      interface{} `json:"recovered"`
-	CasesDaily     interface{} `json:"casesDaily"`
-	DeathsDaily    interface{} `json:"deathsDaily"`
-	RecoveredDaily interface{} `json:"recoveredDaily"`
+	Cases          mcountry.Timeline `json:"cases"`
+	Deaths         mcountry.Timeline `json:"deaths"`
+	Recovered      mcountry.Timeline `json:"recovered"`
+	CasesDaily     mcountry.Timeline `json:"casesDaily"`
+	DeathsDaily    mcountry.Timeline `json:"deathsDaily"`
+	RecoveredDaily mcountry.Timeline `json:"recoveredDaily"`
+	Stale          bool              `json:"stale,omitempty"`
 }
@@ -2,21 +2,31 @@ package news
 
 import (
 	"encoding/xml"
-	"io/ioutil"
-	"net/http"
+	"fmt"
+	"time"
 
 	caching "github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
 	mnews "github.com/junkd0g/covid/lib/model/news"
+	singleflight "github.com/junkd0g/covid/lib/singleflight"
+	upstream "github.com/junkd0g/covid/lib/upstream"
+)
+
+// Cache keys of the news types
+const (
+	GeneralNews   = "general"
+	VaccineNews   = "vaccine"
+	TreatmentNews = "treatment"
 )
 
 var (
 	serverConf pconf.AppConf
 	reqDataOB  requestAPI
 	reqCacheOB requestCache
-	redis      caching.RedisST
+	cache      = caching.NewMemoryStore()
+	fetches    singleflight.Group
 )
 
 func init() {
@@ -25,6 +35,12 @@ func init() {
 	reqCacheOB = requestCacheData{}
 }
 
+// SetCache sets the Store the news are cached in, an in-process
+// store is used until it is set
+func SetCache(store caching.Store) {
+	cache = store
+}
+
 type requestData struct{}
 type requestAPI interface {
 	requestNewsData(url string) (mnews.ArticlesData, error)
@@ -33,16 +49,22 @@ type requestAPI interface {
 type requestCacheData struct{}
 type requestCache interface {
 	getCacheData(newsType string) (mnews.ArticlesData, bool, error)
+	getStaleCacheData(newsType string) (mnews.ArticlesData, bool, error)
 	setCacheData(newsType string, ctn mnews.ArticlesData) error
 }
 
 func (r requestCacheData) getCacheData(newsType string) (mnews.ArticlesData, bool, error) {
-	cachedData, exist, cacheGetError := redis.GetNewsData(newsType)
+	cachedData, exist, cacheGetError := cache.GetNewsData(newsType)
+	return cachedData, exist, cacheGetError
+}
+
+func (r requestCacheData) getStaleCacheData(newsType string) (mnews.ArticlesData, bool, error) {
+	cachedData, exist, cacheGetError := cache.LastKnownGood().GetNewsData(newsType)
 	return cachedData, exist, cacheGetError
 }
 
 func (r requestCacheData) setCacheData(newsType string, ctn mnews.ArticlesData) error {
-	err := redis.SetNewsData(newsType, ctn)
+	err := cache.SetNewsData(newsType, ctn)
 	return err
 }
 
@@ -50,28 +72,10 @@ func (r requestCacheData) setCacheData(newsType string, ctn mnews.ArticlesData)
 // contains covid-9 news article
 // It returns structs.ArticlesData and any write error encountered.
 func (r requestData) requestNewsData(url string) (mnews.ArticlesData, error) {
-
-	client := &http.Client{}
-	req, reqError := http.NewRequest("GET", url, nil)
-
-	if reqError != nil {
-		applogger.Log("ERROR", "news", "requestNewsData", reqError.Error())
-		return mnews.ArticlesData{}, reqError
-	}
-
-	res, resError := client.Do(req)
-	if resError != nil {
-		applogger.Log("ERROR", "news", "requestNewsData", resError.Error())
-		return mnews.ArticlesData{}, resError
-
-	}
-
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := upstream.Default.Get(url)
 	if err != nil {
 		applogger.Log("ERROR", "news", "requestNewsData", err.Error())
 		return mnews.ArticlesData{}, err
-
 	}
 
 	var reponseNews mnews.ReponseNews
@@ -79,6 +83,7 @@ func (r requestData) requestNewsData(url string) (mnews.ArticlesData, error) {
 	unmarshallError := xml.Unmarshal(body, &reponseNews)
 	if unmarshallError != nil {
 		applogger.Log("ERROR", "news", "requestNewsData", unmarshallError.Error())
+		return mnews.ArticlesData{}, unmarshallError
 	}
 
 	keys := make([]mnews.Article, 0)
@@ -98,88 +103,144 @@ func (r requestData) requestNewsData(url string) (mnews.ArticlesData, error) {
 
 }
 
-// GetNews returns an array of articles for covid-19
-// It returns structs.ArticlesData and any write error encountered.
-func GetNews() (mnews.ArticlesData, error) {
-
-	cachedData, exist, cacheGetError := reqCacheOB.getCacheData("general")
-	if cacheGetError != nil {
-		applogger.Log("ERROR", "curve", "GetNews", cacheGetError.Error())
-		return mnews.ArticlesData{}, cacheGetError
-	}
-
-	if !exist {
-		applogger.Log("INFO", "stats", "GetNews", "Request data instead of getting cached data")
-		data, err := reqDataOB.requestNewsData(serverConf.API.News)
+// refresh requests the articles of a news type from the 3rd party API
+// and caches them. Concurrent refreshes of a news type share one request
+// and one cache write
+func refresh(newsType string, url string) (mnews.ArticlesData, error) {
+	data, err, _ := fetches.Do(newsType, func() (interface{}, error) {
+		data, err := reqDataOB.requestNewsData(url)
 		if err != nil {
-			applogger.Log("ERROR", "curve", "GetNews", err.Error())
-			return mnews.ArticlesData{}, err
+			return nil, err
 		}
-		errReqCacheOB := reqCacheOB.setCacheData("general", data)
-		if errReqCacheOB != nil {
-			applogger.Log("ERROR", "curve", "GetNews", errReqCacheOB.Error())
-			return mnews.ArticlesData{}, errReqCacheOB
+
+		if errReqCacheOB := reqCacheOB.setCacheData(newsType, data); errReqCacheOB != nil {
+			return nil, errReqCacheOB
 		}
 		return data, nil
+	})
+	if err != nil {
+		applogger.Log("ERROR", "news", "refresh", err.Error())
+		return mnews.ArticlesData{}, err
 	}
 
-	return cachedData, nil
+	refreshed, ok := data.(mnews.ArticlesData)
+	if !ok {
+		errResult := fmt.Errorf("unexpected refresh result %T", data)
+		applogger.Log("ERROR", "news", "refresh", errResult.Error())
+		return mnews.ArticlesData{}, errResult
+	}
+	return refreshed, nil
 }
 
-// GetVaccineNews returns an array of articles for covid-19
-// It returns structs.ArticlesData and any write error encountered.
-func GetVaccineNews() (mnews.ArticlesData, error) {
-
-	cachedData, exist, cacheGetError := reqCacheOB.getCacheData("vaccine")
+// getNewsType returns the cached articles of a news type. When they have
+// expired it serves their last known good copy while refreshing them in
+// the background, or requests them from url if there is no copy
+func getNewsType(newsType string, url string) (mnews.ArticlesData, error) {
+	cachedData, exist, cacheGetError := reqCacheOB.getCacheData(newsType)
 	if cacheGetError != nil {
-		applogger.Log("ERROR", "curve", "GetVaccineNews", cacheGetError.Error())
-		return mnews.ArticlesData{}, cacheGetError
+		applogger.Log("ERROR", "news", "getNewsType", cacheGetError.Error())
+	} else if exist {
+		return cachedData, nil
 	}
 
-	if !exist {
-		applogger.Log("INFO", "stats", "GetVaccineNews", "Request data instead of getting cached data")
-		data, err := reqDataOB.requestNewsData(serverConf.API.VaccineNews)
-		if err != nil {
-			applogger.Log("ERROR", "curve", "GetVaccineNews", err.Error())
-			return mnews.ArticlesData{}, err
-		}
-
-		errReqCacheOB := reqCacheOB.setCacheData("vaccine", data)
-		if errReqCacheOB != nil {
-			applogger.Log("ERROR", "curve", "GetVaccineNews", errReqCacheOB.Error())
-			return mnews.ArticlesData{}, errReqCacheOB
-		}
-		return data, nil
+	staleData, staleExist, staleGetError := reqCacheOB.getStaleCacheData(newsType)
+	if staleGetError == nil && staleExist {
+		applogger.Log("WARN", "news", "getNewsType", "Serving last known good "+newsType+" news while refreshing them")
+		cache.MarkStale(newsType)
+		cache.Revalidate(newsType, func() error {
+			_, err := refresh(newsType, url)
+			return err
+		})
+		return staleData, nil
 	}
 
-	return cachedData, nil
+	applogger.Log("INFO", "news", "getNewsType", "Request "+newsType+" news instead of getting cached data")
+	return refresh(newsType, url)
+}
+
+// GetNews returns an array of articles for covid-19
+// It returns structs.ArticlesData and any write error encountered.
+func GetNews() (mnews.ArticlesData, error) {
+	return getNewsType(GeneralNews, serverConf.API.News)
+}
+
+// GetVaccineNews returns an array of articles for covid-19
+// It returns structs.ArticlesData and any write error encountered.
+func GetVaccineNews() (mnews.ArticlesData, error) {
+	return getNewsType(VaccineNews, serverConf.API.VaccineNews)
 }
 
 // GetTreatmentNews returns an array of articles for covid-19
 // It returns structs.ArticlesData and any write error encountered.
 func GetTreatmentNews() (mnews.ArticlesData, error) {
+	return getNewsType(TreatmentNews, serverConf.API.TreatmentNews)
+}
 
-	cachedData, exist, cacheGetError := reqCacheOB.getCacheData("treatment")
-	if cacheGetError != nil {
-		applogger.Log("ERROR", "curve", "GetTreatmentNews", cacheGetError.Error())
-		return mnews.ArticlesData{}, cacheGetError
-	}
+// RefreshNews requests the articles for covid-19 and caches them
+func RefreshNews() error {
+	_, err := refresh(GeneralNews, serverConf.API.News)
+	return err
+}
 
-	if !exist {
-		applogger.Log("INFO", "stats", "GetTreatmentNews", "Request data instead of getting cached data")
-		data, err := reqDataOB.requestNewsData(serverConf.API.TreatmentNews)
-		if err != nil {
-			applogger.Log("ERROR", "curve", "GetTreatmentNews", err.Error())
-			return mnews.ArticlesData{}, err
+// RefreshVaccineNews requests the articles for covid-19 vaccines and caches them
+func RefreshVaccineNews() error {
+	_, err := refresh(VaccineNews, serverConf.API.VaccineNews)
+	return err
+}
+
+// RefreshTreatmentNews requests the articles for covid-19 treatments and caches them
+func RefreshTreatmentNews() error {
+	_, err := refresh(TreatmentNews, serverConf.API.TreatmentNews)
+	return err
+}
+
+// Stale returns since when any of the news types is served from its
+// last known good copy and false if none is stale
+func Stale() (time.Time, bool) {
+	var since time.Time
+	stale := false
+	for _, newsType := range []string{GeneralNews, VaccineNews, TreatmentNews} {
+		if t, exist := StaleType(newsType); exist && (!stale || t.Before(since)) {
+			since = t
+			stale = true
 		}
+	}
+	return since, stale
+}
 
-		errReqCacheOB := reqCacheOB.setCacheData("treatment", data)
-		if errReqCacheOB != nil {
-			applogger.Log("ERROR", "curve", "GetTreatmentNews", errReqCacheOB.Error())
-			return mnews.ArticlesData{}, errReqCacheOB
+// StaleType returns since when the articles of a news type are served
+// from their last known good copy and false if they are not stale
+func StaleType(newsType string) (time.Time, bool) {
+	return cache.StaleSince(newsType)
+}
+
+// Page returns the articles published at or after since (all of them when
+// since is zero) skipping the first offset and keeping up to limit
+// articles, no limit when it is zero. Articles whose publishedAt can not
+// be parsed are kept only when there is no since
+func Page(data mnews.ArticlesData, since time.Time, limit int, offset int) mnews.ArticlesPage {
+	articles := make([]mnews.Article, 0, len(data.Articles))
+	for _, v := range data.Articles {
+		if !since.IsZero() {
+			publishedAt, err := time.Parse(time.RFC1123, v.PublishedAt)
+			if err != nil || publishedAt.Before(since) {
+				continue
+			}
 		}
-		return data, nil
+		articles = append(articles, v)
+	}
+
+	page := mnews.ArticlesPage{Total: len(articles), Limit: limit, Offset: offset, Stale: data.Stale}
+
+	if offset > len(articles) {
+		offset = len(articles)
+	}
+	articles = articles[offset:]
+
+	if limit > 0 && limit < len(articles) {
+		articles = articles[:limit]
 	}
+	page.Articles = articles
 
-	return cachedData, nil
+	return page
 }
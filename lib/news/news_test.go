@@ -2,6 +2,7 @@ package news
 
 import (
 	"testing"
+	"time"
 
 	mnews "github.com/junkd0g/covid/lib/model/news"
 )
@@ -22,6 +23,14 @@ func (u requestCacheDataMock) getCacheData(newsType string) (mnews.ArticlesData,
 	return requestCacheDataMockFunc(newsType)
 }
 
+var requestStaleCacheDataMockFunc = func(newsType string) (mnews.ArticlesData, bool, error) {
+	return mnews.ArticlesData{}, false, nil
+}
+
+func (u requestCacheDataMock) getStaleCacheData(newsType string) (mnews.ArticlesData, bool, error) {
+	return requestStaleCacheDataMockFunc(newsType)
+}
+
 var setCacheDataMockFunc func(newsType string, ctn mnews.ArticlesData) error
 
 func (u requestCacheDataMock) setCacheData(newsType string, ctn mnews.ArticlesData) error {
@@ -196,3 +205,32 @@ func TestNews(t *testing.T) {
 		t.Fatal("Using cached data instead of requesting data %", len(withVaccineCacheDataFalse.Articles))
 	}
 }
+
+func TestPage(t *testing.T) {
+	data := mnews.ArticlesData{Articles: []mnews.Article{
+		{Title: "one", PublishedAt: "Mon, 08 Jun 2020 12:12:50 GMT"},
+		{Title: "two", PublishedAt: "Sun, 07 Jun 2020 16:02:58 GMT"},
+		{Title: "three", PublishedAt: "Mon, 08 Jun 2020 02:39:00 GMT"},
+		{Title: "four", PublishedAt: "not a date"},
+	}}
+
+	all := Page(data, time.Time{}, 0, 0)
+	if all.Total != 4 || len(all.Articles) != 4 {
+		t.Fatalf("Expecting all articles having %v", all)
+	}
+
+	paged := Page(data, time.Time{}, 2, 1)
+	if paged.Total != 4 || len(paged.Articles) != 2 || paged.Articles[0].Title != "two" || paged.Articles[1].Title != "three" {
+		t.Fatalf("Wrong page %v", paged)
+	}
+
+	since := Page(data, time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC), 0, 0)
+	if since.Total != 2 || since.Articles[0].Title != "one" || since.Articles[1].Title != "three" {
+		t.Fatalf("Wrong articles since 2020-06-08 %v", since)
+	}
+
+	beyond := Page(data, time.Time{}, 2, 10)
+	if beyond.Total != 4 || len(beyond.Articles) != 0 {
+		t.Fatalf("Expecting no articles after the last one having %v", beyond)
+	}
+}
@@ -0,0 +1,231 @@
+package registry
+
+// countries is every country and territory the third party APIs have
+// data for. The stats spelling is the one of the worldometers based APIs
+// (stats, curve and continent), the csse spelling the one of Johns Hopkins
+// CSSE, both are empty when they are the name
+var countries = []Country{
+	country("Afghanistan", "AF", "AFG", 4, "", ""),
+	country("Albania", "AL", "ALB", 8, "", ""),
+	country("Algeria", "DZ", "DZA", 12, "", ""),
+	country("Andorra", "AD", "AND", 20, "", ""),
+	country("Angola", "AO", "AGO", 24, "", ""),
+	country("Anguilla", "AI", "AIA", 660, "", ""),
+	country("Antigua and Barbuda", "AG", "ATG", 28, "", "", "Antigua"),
+	country("Argentina", "AR", "ARG", 32, "", ""),
+	country("Armenia", "AM", "ARM", 51, "", ""),
+	country("Aruba", "AW", "ABW", 533, "", ""),
+	country("Australia", "AU", "AUS", 36, "", ""),
+	country("Austria", "AT", "AUT", 40, "", ""),
+	country("Azerbaijan", "AZ", "AZE", 31, "", ""),
+	country("Bahamas", "BS", "BHS", 44, "", "", "The Bahamas", "Bahamas, The"),
+	country("Bahrain", "BH", "BHR", 48, "", ""),
+	country("Bangladesh", "BD", "BGD", 50, "", ""),
+	country("Barbados", "BB", "BRB", 52, "", ""),
+	country("Belarus", "BY", "BLR", 112, "", ""),
+	country("Belgium", "BE", "BEL", 56, "", ""),
+	country("Belize", "BZ", "BLZ", 84, "", ""),
+	country("Benin", "BJ", "BEN", 204, "", ""),
+	country("Bermuda", "BM", "BMU", 60, "", ""),
+	country("Bhutan", "BT", "BTN", 64, "", ""),
+	country("Bolivia", "BO", "BOL", 68, "", "", "Plurinational State of Bolivia"),
+	country("Bosnia and Herzegovina", "BA", "BIH", 70, "Bosnia", "", "Bosnia-Herzegovina"),
+	country("Botswana", "BW", "BWA", 72, "", ""),
+	country("Brazil", "BR", "BRA", 76, "", "", "Brasil"),
+	country("British Virgin Islands", "VG", "VGB", 92, "", "", "Virgin Islands, British"),
+	country("Brunei", "BN", "BRN", 96, "", "", "Brunei Darussalam"),
+	country("Bulgaria", "BG", "BGR", 100, "", ""),
+	country("Burkina Faso", "BF", "BFA", 854, "", ""),
+	country("Burundi", "BI", "BDI", 108, "", ""),
+	country("Cabo Verde", "CV", "CPV", 132, "", "", "Cape Verde"),
+	country("Cambodia", "KH", "KHM", 116, "", ""),
+	country("Cameroon", "CM", "CMR", 120, "", ""),
+	country("Canada", "CA", "CAN", 124, "", ""),
+	country("Caribbean Netherlands", "BQ", "BES", 535, "", "", "Bonaire, Sint Eustatius and Saba", "Bonaire"),
+	country("Cayman Islands", "KY", "CYM", 136, "", ""),
+	country("Central African Republic", "CF", "CAF", 140, "CAR", ""),
+	country("Chad", "TD", "TCD", 148, "", ""),
+	country("Channel Islands", "", "", 0, "", "", "Jersey", "Guernsey"),
+	country("Chile", "CL", "CHL", 152, "", ""),
+	country("China", "CN", "CHN", 156, "", "", "Mainland China", "People's Republic of China", "PRC"),
+	country("Colombia", "CO", "COL", 170, "", ""),
+	country("Comoros", "KM", "COM", 174, "", ""),
+	country("Congo", "CG", "COG", 178, "", "Congo (Brazzaville)", "Republic of the Congo", "Congo-Brazzaville"),
+	country("Costa Rica", "CR", "CRI", 188, "", ""),
+	country("Cote d'Ivoire", "CI", "CIV", 384, "Côte d'Ivoire", "", "Ivory Coast"),
+	country("Croatia", "HR", "HRV", 191, "", ""),
+	country("Cuba", "CU", "CUB", 192, "", ""),
+	country("Curacao", "CW", "CUW", 531, "Curaçao", ""),
+	country("Cyprus", "CY", "CYP", 196, "", ""),
+	country("Czechia", "CZ", "CZE", 203, "", "", "Czech Republic"),
+	country("Democratic Republic of the Congo", "CD", "COD", 180, "DRC", "Congo (Kinshasa)", "DR Congo", "Congo-Kinshasa"),
+	country("Denmark", "DK", "DNK", 208, "", ""),
+	country("Diamond Princess", "", "", 0, "", "", "Diamond Princess Cruise Ship"),
+	country("Djibouti", "DJ", "DJI", 262, "", ""),
+	country("Dominica", "DM", "DMA", 212, "", ""),
+	country("Dominican Republic", "DO", "DOM", 214, "", ""),
+	country("Ecuador", "EC", "ECU", 218, "", ""),
+	country("Egypt", "EG", "EGY", 818, "", ""),
+	country("El Salvador", "SV", "SLV", 222, "", ""),
+	country("Equatorial Guinea", "GQ", "GNQ", 226, "", ""),
+	country("Eritrea", "ER", "ERI", 232, "", ""),
+	country("Estonia", "EE", "EST", 233, "", ""),
+	country("Eswatini", "SZ", "SWZ", 748, "", "", "Swaziland"),
+	country("Ethiopia", "ET", "ETH", 231, "", ""),
+	country("Falkland Islands", "FK", "FLK", 238, "Falkland Islands (Malvinas)", "", "Malvinas"),
+	country("Faroe Islands", "FO", "FRO", 234, "", "", "Faeroe Islands"),
+	country("Fiji", "FJ", "FJI", 242, "", ""),
+	country("Finland", "FI", "FIN", 246, "", ""),
+	country("France", "FR", "FRA", 250, "", ""),
+	country("French Guiana", "GF", "GUF", 254, "", ""),
+	country("French Polynesia", "PF", "PYF", 258, "", ""),
+	country("Gabon", "GA", "GAB", 266, "", ""),
+	country("Gambia", "GM", "GMB", 270, "", "", "The Gambia", "Gambia, The"),
+	country("Georgia", "GE", "GEO", 268, "", ""),
+	country("Germany", "DE", "DEU", 276, "", "", "Deutschland"),
+	country("Ghana", "GH", "GHA", 288, "", ""),
+	country("Gibraltar", "GI", "GIB", 292, "", ""),
+	country("Greece", "GR", "GRC", 300, "", "", "Hellas", "Hellenic Republic"),
+	country("Greenland", "GL", "GRL", 304, "", ""),
+	country("Grenada", "GD", "GRD", 308, "", ""),
+	country("Guadeloupe", "GP", "GLP", 312, "", ""),
+	country("Guatemala", "GT", "GTM", 320, "", ""),
+	country("Guinea", "GN", "GIN", 324, "", ""),
+	country("Guinea-Bissau", "GW", "GNB", 624, "", ""),
+	country("Guyana", "GY", "GUY", 328, "", ""),
+	country("Haiti", "HT", "HTI", 332, "", ""),
+	country("Holy See", "VA", "VAT", 336, "Holy See (Vatican City State)", "", "Vatican", "Vatican City"),
+	country("Honduras", "HN", "HND", 340, "", ""),
+	country("Hong Kong", "HK", "HKG", 344, "", "", "Hong Kong SAR"),
+	country("Hungary", "HU", "HUN", 348, "", ""),
+	country("Iceland", "IS", "ISL", 352, "", ""),
+	country("India", "IN", "IND", 356, "", ""),
+	country("Indonesia", "ID", "IDN", 360, "", ""),
+	country("Iran", "IR", "IRN", 364, "", "", "Islamic Republic of Iran", "Iran, Islamic Republic of"),
+	country("Iraq", "IQ", "IRQ", 368, "", ""),
+	country("Ireland", "IE", "IRL", 372, "", "", "Republic of Ireland", "Eire"),
+	country("Isle of Man", "IM", "IMN", 833, "", ""),
+	country("Israel", "IL", "ISR", 376, "", ""),
+	country("Italy", "IT", "ITA", 380, "", "", "Italia"),
+	country("Jamaica", "JM", "JAM", 388, "", ""),
+	country("Japan", "JP", "JPN", 392, "", ""),
+	country("Jordan", "JO", "JOR", 400, "", ""),
+	country("Kazakhstan", "KZ", "KAZ", 398, "", ""),
+	country("Kenya", "KE", "KEN", 404, "", ""),
+	country("Kosovo", "XK", "XKX", 0, "", ""),
+	country("Kuwait", "KW", "KWT", 414, "", ""),
+	country("Kyrgyzstan", "KG", "KGZ", 417, "", "", "Kyrgyz Republic"),
+	country("Laos", "LA", "LAO", 418, "Lao People's Democratic Republic", "", "Lao PDR", "Lao"),
+	country("Latvia", "LV", "LVA", 428, "", ""),
+	country("Lebanon", "LB", "LBN", 422, "", ""),
+	country("Lesotho", "LS", "LSO", 426, "", ""),
+	country("Liberia", "LR", "LBR", 430, "", ""),
+	country("Libya", "LY", "LBY", 434, "Libyan Arab Jamahiriya", ""),
+	country("Liechtenstein", "LI", "LIE", 438, "", ""),
+	country("Lithuania", "LT", "LTU", 440, "", ""),
+	country("Luxembourg", "LU", "LUX", 442, "", ""),
+	country("Macao", "MO", "MAC", 446, "", "", "Macau", "Macao SAR"),
+	country("Madagascar", "MG", "MDG", 450, "", ""),
+	country("Malawi", "MW", "MWI", 454, "", ""),
+	country("Malaysia", "MY", "MYS", 458, "", ""),
+	country("Maldives", "MV", "MDV", 462, "", ""),
+	country("Mali", "ML", "MLI", 466, "", ""),
+	country("Malta", "MT", "MLT", 470, "", ""),
+	country("Marshall Islands", "MH", "MHL", 584, "", ""),
+	country("Martinique", "MQ", "MTQ", 474, "", ""),
+	country("Mauritania", "MR", "MRT", 478, "", ""),
+	country("Mauritius", "MU", "MUS", 480, "", ""),
+	country("Mayotte", "YT", "MYT", 175, "", ""),
+	country("Mexico", "MX", "MEX", 484, "", "", "México"),
+	country("Micronesia", "FM", "FSM", 583, "", "", "Federated States of Micronesia"),
+	country("Moldova", "MD", "MDA", 498, "", "", "Republic of Moldova"),
+	country("Monaco", "MC", "MCO", 492, "", ""),
+	country("Mongolia", "MN", "MNG", 496, "", ""),
+	country("Montenegro", "ME", "MNE", 499, "", ""),
+	country("Montserrat", "MS", "MSR", 500, "", ""),
+	country("Morocco", "MA", "MAR", 504, "", ""),
+	country("Mozambique", "MZ", "MOZ", 508, "", ""),
+	country("MS Zaandam", "", "", 0, "", "", "Zaandam"),
+	country("Myanmar", "MM", "MMR", 104, "", "Burma"),
+	country("Namibia", "NA", "NAM", 516, "", ""),
+	country("Nepal", "NP", "NPL", 524, "", ""),
+	country("Netherlands", "NL", "NLD", 528, "", "", "Holland", "The Netherlands"),
+	country("New Caledonia", "NC", "NCL", 540, "", ""),
+	country("New Zealand", "NZ", "NZL", 554, "", ""),
+	country("Nicaragua", "NI", "NIC", 558, "", ""),
+	country("Niger", "NE", "NER", 562, "", ""),
+	country("Nigeria", "NG", "NGA", 566, "", ""),
+	country("North Korea", "KP", "PRK", 408, "", "Korea, North", "DPRK", "Democratic People's Republic of Korea"),
+	country("North Macedonia", "MK", "MKD", 807, "Macedonia", "", "FYROM"),
+	country("Norway", "NO", "NOR", 578, "", ""),
+	country("Oman", "OM", "OMN", 512, "", ""),
+	country("Pakistan", "PK", "PAK", 586, "", ""),
+	country("Palestine", "PS", "PSE", 275, "", "West Bank and Gaza", "State of Palestine"),
+	country("Panama", "PA", "PAN", 591, "", ""),
+	country("Papua New Guinea", "PG", "PNG", 598, "", ""),
+	country("Paraguay", "PY", "PRY", 600, "", ""),
+	country("Peru", "PE", "PER", 604, "", ""),
+	country("Philippines", "PH", "PHL", 608, "", ""),
+	country("Poland", "PL", "POL", 616, "", ""),
+	country("Portugal", "PT", "PRT", 620, "", ""),
+	country("Qatar", "QA", "QAT", 634, "", ""),
+	country("Reunion", "RE", "REU", 638, "Réunion", ""),
+	country("Romania", "RO", "ROU", 642, "", ""),
+	country("Russia", "RU", "RUS", 643, "", "", "Russian Federation"),
+	country("Rwanda", "RW", "RWA", 646, "", ""),
+	country("Saint Barthelemy", "BL", "BLM", 652, "St. Barth", "", "Saint Barthélemy", "St Barths"),
+	country("Saint Kitts and Nevis", "KN", "KNA", 659, "", "", "St Kitts and Nevis"),
+	country("Saint Lucia", "LC", "LCA", 662, "", "", "St Lucia"),
+	country("Saint Martin", "MF", "MAF", 663, "", "", "St Martin"),
+	country("Saint Pierre and Miquelon", "PM", "SPM", 666, "Saint Pierre Miquelon", ""),
+	country("Saint Vincent and the Grenadines", "VC", "VCT", 670, "", "", "St Vincent and the Grenadines", "Saint Vincent"),
+	country("Samoa", "WS", "WSM", 882, "", ""),
+	country("San Marino", "SM", "SMR", 674, "", ""),
+	country("Sao Tome and Principe", "ST", "STP", 678, "", "", "São Tomé and Príncipe"),
+	country("Saudi Arabia", "SA", "SAU", 682, "", "", "KSA"),
+	country("Senegal", "SN", "SEN", 686, "", ""),
+	country("Serbia", "RS", "SRB", 688, "", ""),
+	country("Seychelles", "SC", "SYC", 690, "", ""),
+	country("Sierra Leone", "SL", "SLE", 694, "", ""),
+	country("Singapore", "SG", "SGP", 702, "", ""),
+	country("Sint Maarten", "SX", "SXM", 534, "", ""),
+	country("Slovakia", "SK", "SVK", 703, "", "", "Slovak Republic"),
+	country("Slovenia", "SI", "SVN", 705, "", ""),
+	country("Solomon Islands", "SB", "SLB", 90, "", ""),
+	country("Somalia", "SO", "SOM", 706, "", ""),
+	country("South Africa", "ZA", "ZAF", 710, "", "", "RSA"),
+	country("South Korea", "KR", "KOR", 410, "S. Korea", "Korea, South", "Republic of Korea", "Korea"),
+	country("South Sudan", "SS", "SSD", 728, "", ""),
+	country("Spain", "ES", "ESP", 724, "", "", "España"),
+	country("Sri Lanka", "LK", "LKA", 144, "", ""),
+	country("Sudan", "SD", "SDN", 729, "", ""),
+	country("Suriname", "SR", "SUR", 740, "", ""),
+	country("Sweden", "SE", "SWE", 752, "", ""),
+	country("Switzerland", "CH", "CHE", 756, "", "", "Swiss Confederation"),
+	country("Syria", "SY", "SYR", 760, "Syrian Arab Republic", ""),
+	country("Taiwan", "TW", "TWN", 158, "", "Taiwan*", "Republic of China"),
+	country("Tajikistan", "TJ", "TJK", 762, "", ""),
+	country("Tanzania", "TZ", "TZA", 834, "", "", "United Republic of Tanzania"),
+	country("Thailand", "TH", "THA", 764, "", ""),
+	country("Timor-Leste", "TL", "TLS", 626, "", "", "East Timor"),
+	country("Togo", "TG", "TGO", 768, "", ""),
+	country("Trinidad and Tobago", "TT", "TTO", 780, "", "", "Trinidad"),
+	country("Tunisia", "TN", "TUN", 788, "", ""),
+	country("Turkey", "TR", "TUR", 792, "", "", "Türkiye", "Turkiye"),
+	country("Turks and Caicos Islands", "TC", "TCA", 796, "", "", "Turks and Caicos"),
+	country("Uganda", "UG", "UGA", 800, "", ""),
+	country("Ukraine", "UA", "UKR", 804, "", ""),
+	country("United Arab Emirates", "AE", "ARE", 784, "UAE", "", "Emirates"),
+	country("United Kingdom", "GB", "GBR", 826, "UK", "", "Great Britain", "Britain", "U.K."),
+	country("United States", "US", "USA", 840, "USA", "US", "United States of America", "America", "U.S.", "U.S.A."),
+	country("Uruguay", "UY", "URY", 858, "", ""),
+	country("Uzbekistan", "UZ", "UZB", 860, "", ""),
+	country("Vanuatu", "VU", "VUT", 548, "", ""),
+	country("Venezuela", "VE", "VEN", 862, "", "", "Bolivarian Republic of Venezuela"),
+	country("Vietnam", "VN", "VNM", 704, "", "", "Viet Nam"),
+	country("Wallis and Futuna", "WF", "WLF", 876, "", ""),
+	country("Western Sahara", "EH", "ESH", 732, "", ""),
+	country("Yemen", "YE", "YEM", 887, "", ""),
+	country("Zambia", "ZM", "ZMB", 894, "", ""),
+	country("Zimbabwe", "ZW", "ZWE", 716, "", ""),
+}
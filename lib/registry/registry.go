@@ -0,0 +1,377 @@
+package registry
+
+/*
+	The countries of the API with their ISO codes, aliases and the
+	spellings of the third party APIs, so a country can be asked for
+	by any of them
+*/
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Spellings of the third party APIs
+const (
+	// SourceStats is the worldometers based APIs of stats, curve and continent
+	SourceStats = "stats"
+	// SourceCSSE is the Johns Hopkins CSSE API
+	SourceCSSE = "csse"
+)
+
+// maxSuggestions is the most suggestions of a NotFoundError
+const maxSuggestions = 3
+
+// ErrNotFound is wrapped by the errors returned when a name matches no country
+var ErrNotFound = errors.New("country not found")
+
+// NotFoundError is returned when a name matches no country, with the
+// names of the closest countries
+type NotFoundError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("country %q not found", e.Name)
+	}
+	return fmt.Sprintf("country %q not found, did you mean %s?", e.Name, strings.Join(e.Suggestions, ", "))
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// Country is a country or territory, ISO2, ISO3 and Numeric are its
+// ISO 3166-1 codes and are empty for the ones that have none like
+// the cruise ships
+type Country struct {
+	Name    string
+	ISO2    string
+	ISO3    string
+	Numeric int
+	Aliases []string
+	Stats   string
+	CSSE    string
+}
+
+// Spelling returns the name of the country in a third party API
+func (c Country) Spelling(source string) string {
+	switch {
+	case source == SourceStats && c.Stats != "":
+		return c.Stats
+	case source == SourceCSSE && c.CSSE != "":
+		return c.CSSE
+	}
+	return c.Name
+}
+
+// keys returns every form of the country that it can be found by
+func (c Country) keys() []string {
+	keys := append([]string{c.Name, c.ISO2, c.ISO3, c.Stats, c.CSSE}, c.Aliases...)
+	if c.Numeric > 0 {
+		keys = append(keys, strconv.Itoa(c.Numeric), fmt.Sprintf("%03d", c.Numeric))
+	}
+	return keys
+}
+
+func country(name string, iso2 string, iso3 string, numeric int, stats string, csse string, aliases ...string) Country {
+	return Country{Name: name, ISO2: iso2, ISO3: iso3, Numeric: numeric, Stats: stats, CSSE: csse, Aliases: aliases}
+}
+
+// index is every normalized key of the countries
+var index map[string]int
+
+func init() {
+	var err error
+	if index, err = newIndex(countries); err != nil {
+		panic(err)
+	}
+}
+
+// newIndex indexes the countries by their normalized keys
+// It returns an error if a key is of two countries
+func newIndex(countries []Country) (map[string]int, error) {
+	index := make(map[string]int)
+	for i, c := range countries {
+		for _, key := range c.keys() {
+			key = normalize(key)
+			if key == "" {
+				continue
+			}
+			if j, exist := index[key]; exist && j != i {
+				return nil, fmt.Errorf("registry: %q is both %s and %s", key, countries[j].Name, c.Name)
+			}
+			index[key] = i
+		}
+	}
+	return index, nil
+}
+
+// All returns every country of the registry
+func All() []Country {
+	all := make([]Country, len(countries))
+	copy(all, countries)
+	return all
+}
+
+// Lookup returns the country of a name, an ISO code, an alias or a
+// spelling of a third party API, ignoring case, accents and punctuation
+func Lookup(name string) (Country, bool) {
+	i, exist := index[normalize(name)]
+	if !exist {
+		return Country{}, false
+	}
+	return countries[i], true
+}
+
+// Find returns the country of name like Lookup or, when there is none,
+// the country whose name or alias is a few typos away from it
+// It returns a *NotFoundError if no country matches
+func Find(name string) (Country, error) {
+	if c, exist := Lookup(name); exist {
+		return c, nil
+	}
+
+	if i, ok := closest(normalize(name)); ok {
+		return countries[i], nil
+	}
+
+	return Country{}, &NotFoundError{Name: name, Suggestions: suggest(name, nil)}
+}
+
+// Resolve returns the name in known, the country names of a third party
+// API, that is the same country as name. name is matched exactly first,
+// then as any form of a country of the registry and last with a few typos
+// It returns a *NotFoundError with the closest names of known if none is
+func Resolve(name string, known []string) (string, error) {
+	norm := normalize(name)
+	for _, k := range known {
+		if normalize(k) == norm {
+			return k, nil
+		}
+	}
+
+	i, exist := index[norm]
+	if !exist {
+		i, exist = closest(norm)
+	}
+	if exist {
+		for _, k := range known {
+			if j, ok := index[normalize(k)]; ok && i == j {
+				return k, nil
+			}
+		}
+	}
+
+	// the names of known the registry does not have
+	best, bestDistance, tie := "", -1, false
+	for _, k := range known {
+		if _, ok := index[normalize(k)]; ok {
+			continue
+		}
+		d := distance(norm, normalize(k))
+		switch {
+		case d > maxDistance(norm):
+		case bestDistance < 0 || d < bestDistance:
+			best, bestDistance, tie = k, d, false
+		case d == bestDistance && k != best:
+			tie = true
+		}
+	}
+	if bestDistance >= 0 && !tie {
+		return best, nil
+	}
+
+	return "", &NotFoundError{Name: name, Suggestions: suggest(name, known)}
+}
+
+// Suggest returns up to three names of known, or of the registry when known
+// is nil, that are the closest to name
+func Suggest(name string, known []string) []string {
+	return suggest(name, known)
+}
+
+func suggest(name string, known []string) []string {
+	norm := normalize(name)
+	if len(norm) < 2 {
+		return []string{}
+	}
+
+	if known == nil {
+		known = make([]string, 0, len(countries))
+		for _, c := range countries {
+			known = append(known, c.Name)
+		}
+	}
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	seen := make(map[string]bool, len(known))
+	suggestions := []suggestion{}
+	for _, k := range known {
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		// a known name is as close as the closest form of its country
+		d := suggestionDistance(norm, normalize(k))
+		if i, ok := index[normalize(k)]; ok {
+			for _, key := range countries[i].keys() {
+				if key = normalize(key); len(key) > 3 {
+					if dk := suggestionDistance(norm, key); dk < d {
+						d = dk
+					}
+				}
+			}
+		}
+
+		if d <= len(norm)/3+1 {
+			suggestions = append(suggestions, suggestion{name: k, distance: d})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	names := []string{}
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		names = append(names, suggestions[i].name)
+	}
+	return names
+}
+
+// suggestionDistance is the distance of the normalized name from key,
+// one when key starts with the name so a name can be suggested for the
+// start of it
+func suggestionDistance(norm string, key string) int {
+	if len(norm) >= 3 && strings.HasPrefix(key, norm) && key != norm {
+		return 1
+	}
+	return distance(norm, key)
+}
+
+// closest returns the country that has a name or an alias a few typos
+// away from the normalized name. The codes are not matched with typos
+// and neither is a name that is as close to two countries
+func closest(norm string) (int, bool) {
+	limit := maxDistance(norm)
+	if limit == 0 {
+		return 0, false
+	}
+
+	best, bestDistance, tie := -1, limit+1, false
+	for key, i := range index {
+		if len(key) <= 3 {
+			continue
+		}
+		d := distance(norm, key)
+		switch {
+		case d < bestDistance:
+			best, bestDistance, tie = i, d, false
+		case d == bestDistance && i != best:
+			tie = true
+		}
+	}
+
+	if best < 0 || tie {
+		return 0, false
+	}
+	return best, true
+}
+
+// maxDistance returns how many typos a normalized name can have
+func maxDistance(norm string) int {
+	switch n := len([]rune(norm)); {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	case n < 12:
+		return 2
+	}
+	return 3
+}
+
+// accents are the letters with accents of the country names
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ý", "y",
+)
+
+// normalize returns name in lower case without accents, dots and
+// apostrophes, with & as and and every other punctuation as a space
+func normalize(name string) string {
+	name = accents.Replace(strings.ToLower(name))
+
+	var b strings.Builder
+	space := true
+	for _, r := range name {
+		switch {
+		case r == '.' || r == '\'' || r == '’':
+			continue
+		case r == '&':
+			if !space {
+				b.WriteRune(' ')
+			}
+			b.WriteString("and ")
+			space = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		case !space:
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), " ")
+}
+
+// distance returns the Levenshtein distance of a and b
+func distance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
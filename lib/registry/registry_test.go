@@ -0,0 +1,211 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	mregistry "github.com/junkd0g/covid/lib/model/registry"
+)
+
+func TestLookup(t *testing.T) {
+	tests := map[string]string{
+		"Greece":                   "Greece",
+		"greece":                   "Greece",
+		"GR":                       "Greece",
+		"grc":                      "Greece",
+		"300":                      "Greece",
+		"United States":            "United States",
+		"usa":                      "United States",
+		"US":                       "United States",
+		"u.s.a.":                   "United States",
+		"840":                      "United States",
+		"United Kingdom":           "United Kingdom",
+		"UK":                       "United Kingdom",
+		"gbr":                      "United Kingdom",
+		"Korea, South":             "South Korea",
+		"S. Korea":                 "South Korea",
+		"Côte d'Ivoire":            "Cote d'Ivoire",
+		"cote divoire":             "Cote d'Ivoire",
+		"Congo (Kinshasa)":         "Democratic Republic of the Congo",
+		"Taiwan*":                  "Taiwan",
+		"Trinidad & Tobago":        "Trinidad and Tobago",
+		"8":                        "Albania",
+		"008":                      "Albania",
+		"  saint   kitts & nevis ": "Saint Kitts and Nevis",
+	}
+
+	for name, expected := range tests {
+		c, ok := Lookup(name)
+		if !ok || c.Name != expected {
+			t.Fatalf("Lookup(%q) returned %q, %v, expected %q", name, c.Name, ok, expected)
+		}
+	}
+
+	for _, name := range []string{"", "Atlantis", "Grece", "XX"} {
+		if c, ok := Lookup(name); ok {
+			t.Fatalf("Lookup(%q) should not match, got %q", name, c.Name)
+		}
+	}
+}
+
+func TestSpelling(t *testing.T) {
+	us, _ := Lookup("United States of America")
+	if us.Spelling(SourceStats) != "USA" || us.Spelling(SourceCSSE) != "US" || us.Spelling("other") != "United States" {
+		t.Fatalf("Wrong spellings of %+v", us)
+	}
+
+	greece, _ := Lookup("Greece")
+	if greece.Spelling(SourceStats) != "Greece" || greece.Spelling(SourceCSSE) != "Greece" {
+		t.Fatalf("Wrong spellings of %+v", greece)
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := map[string]string{
+		"Grece":          "Greece",
+		"Untied Kingdom": "United Kingdom",
+		"Australa":       "Australia",
+		"switzerlnd":     "Switzerland",
+		"US":             "United States",
+	}
+
+	for name, expected := range tests {
+		c, err := Find(name)
+		if err != nil || c.Name != expected {
+			t.Fatalf("Find(%q) returned %q, %v, expected %q", name, c.Name, err, expected)
+		}
+	}
+
+	_, err := Find("Gree")
+	var notFound *NotFoundError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &notFound) {
+		t.Fatalf("Expected a NotFoundError, got %v", err)
+	}
+	if len(notFound.Suggestions) == 0 || notFound.Suggestions[0] != "Greece" {
+		t.Fatalf("Wrong suggestions %v", notFound.Suggestions)
+	}
+	if err.Error() != `country "Gree" not found, did you mean Greece, Greenland?` {
+		t.Fatalf("Wrong error message %q", err.Error())
+	}
+
+	// a name as close to two countries is not matched
+	if _, err := Find("Austrlia"); !errors.As(err, &notFound) || len(notFound.Suggestions) < 2 ||
+		notFound.Suggestions[0] != "Australia" || notFound.Suggestions[1] != "Austria" {
+		t.Fatalf("Expected the suggestions Australia and Austria, got %v", err)
+	}
+
+	// codes are not matched with typos
+	if _, err := Find("GX"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	stats := []string{"USA", "UK", "S. Korea", "Greece", "Diamond Princess", "Narnia"}
+	csse := []string{"US", "United Kingdom", "Korea, South", "Greece"}
+
+	tests := []struct {
+		name     string
+		known    []string
+		expected string
+	}{
+		{"United States", stats, "USA"},
+		{"us", stats, "USA"},
+		{"USA", csse, "US"},
+		{"gb", csse, "United Kingdom"},
+		{"South Korea", stats, "S. Korea"},
+		{"S. Korea", csse, "Korea, South"},
+		{"greece", csse, "Greece"},
+		{"Grece", stats, "Greece"},
+		{"diamond princes", stats, "Diamond Princess"},
+		{"narnia", stats, "Narnia"},
+		{"Narnja", stats, "Narnia"},
+	}
+
+	for _, test := range tests {
+		resolved, err := Resolve(test.name, test.known)
+		if err != nil || resolved != test.expected {
+			t.Fatalf("Resolve(%q) returned %q, %v, expected %q", test.name, resolved, err, test.expected)
+		}
+	}
+
+	_, err := Resolve("Gre", stats)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a NotFoundError, got %v", err)
+	}
+	if len(notFound.Suggestions) != 2 || notFound.Suggestions[0] != "UK" || notFound.Suggestions[1] != "Greece" {
+		t.Fatalf("Wrong suggestions %v", notFound.Suggestions)
+	}
+
+	if _, err := Resolve("Grenada", stats); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("A country that is not known should not be found, got %v", err)
+	}
+
+	if _, err := Resolve("Atlantis", csse); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestNewIndex(t *testing.T) {
+	_, err := newIndex([]Country{
+		country("Georgia", "GE", "GEO", 268, "", ""),
+		country("United States", "US", "USA", 840, "", "", "Georgia"),
+	})
+	if err == nil {
+		t.Fatal("Expected an error for a key of two countries")
+	}
+
+	for _, c := range All() {
+		if c.ISO2 != "" && (len(c.ISO2) != 2 || len(c.ISO3) != 3) {
+			t.Fatalf("Wrong ISO codes of %+v", c)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"greece", "greece", 0},
+		{"grece", "greece", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"côte", "cote", 1},
+	}
+
+	for _, test := range tests {
+		if d := distance(test.a, test.b); d != test.expected {
+			t.Fatalf("distance(%q, %q) = %d, expected %d", test.a, test.b, d, test.expected)
+		}
+	}
+}
+
+func TestNotFoundResponse(t *testing.T) {
+	_, err := Resolve("Austrlia", []string{"Australia", "Austria", "Greece"})
+	body, notFound := NotFoundResponse(fmt.Errorf("curves: %w", err))
+	if !notFound {
+		t.Fatalf("Expecting a not found response for %v", err)
+	}
+
+	var response mregistry.NotFound
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != 404 || response.Country != "Austrlia" ||
+		len(response.Suggestions) != 2 || response.Suggestions[0] != "Australia" {
+		t.Fatalf("Wrong not found response %s", body)
+	}
+
+	if body, _ := NotFoundResponse(ErrNotFound); string(body) != `{"message":"country not found","status":404,"country":"","suggestions":[]}` {
+		t.Fatalf("Wrong not found response %s", body)
+	}
+
+	if _, notFound := NotFoundResponse(errors.New("upstream failed")); notFound {
+		t.Fatal("Expecting no not found response for any other error")
+	}
+}
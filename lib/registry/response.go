@@ -0,0 +1,30 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	mregistry "github.com/junkd0g/covid/lib/model/registry"
+)
+
+// NotFoundResponse returns the json body of the 404 response of err, with
+// the country that was asked for and the suggestions of a *NotFoundError
+// It returns false if err is not a country that was not found
+func NotFoundResponse(err error) ([]byte, bool) {
+	if !errors.Is(err, ErrNotFound) {
+		return nil, false
+	}
+
+	body := mregistry.NotFound{Message: err.Error(), Status: http.StatusNotFound, Suggestions: []string{}}
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		body.Country = notFound.Name
+		if notFound.Suggestions != nil {
+			body.Suggestions = notFound.Suggestions
+		}
+	}
+
+	jsonBody, _ := json.Marshal(body)
+	return jsonBody, true
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"time"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+	continent "github.com/junkd0g/covid/lib/continent"
+	csse "github.com/junkd0g/covid/lib/csse"
+	curve "github.com/junkd0g/covid/lib/curve"
+	cworld "github.com/junkd0g/covid/lib/cworld"
+	news "github.com/junkd0g/covid/lib/news"
+	stats "github.com/junkd0g/covid/lib/stats"
+)
+
+var (
+	serverConf = pconf.GetAppConfig()
+	// Default refreshes every dataset on the intervals of the config,
+	// it is started by the app when the scheduler is enabled
+	Default = NewFromConfig(serverConf.Scheduler)
+)
+
+// NewFromConfig creates a Scheduler with a job for every dataset
+func NewFromConfig(conf pconf.SchedulerConfig) *Scheduler {
+	seconds := func(s int) time.Duration {
+		return time.Duration(s) * time.Second
+	}
+
+	return New(seconds(conf.Retry), seconds(conf.MaxBackoff), conf.Jitter,
+		Job{Name: "countries", Interval: seconds(conf.Intervals.Countries), Refresh: stats.Refresh},
+		Job{Name: "curve", Interval: seconds(conf.Intervals.Curve), Refresh: curve.Refresh},
+		Job{Name: "world", Interval: seconds(conf.Intervals.World), Refresh: cworld.Refresh},
+		Job{Name: "continent", Interval: seconds(conf.Intervals.Continent), Refresh: continent.Refresh},
+		Job{Name: "csse", Interval: seconds(conf.Intervals.CSSE), Refresh: csse.Refresh},
+		Job{Name: "news", Interval: seconds(conf.Intervals.News), Refresh: news.RefreshNews},
+		Job{Name: "vaccine_news", Interval: seconds(conf.Intervals.VaccineNews), Refresh: news.RefreshVaccineNews},
+		Job{Name: "treatment_news", Interval: seconds(conf.Intervals.TreatmentNews), Refresh: news.RefreshTreatmentNews},
+	)
+}
@@ -0,0 +1,190 @@
+package scheduler
+
+/*
+	Refreshing the cached datasets in the background so requests
+	find them in the cache instead of waiting for the third party APIs
+*/
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	mscheduler "github.com/junkd0g/covid/lib/model/scheduler"
+)
+
+// Job refreshes a dataset every Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Refresh  func() error
+}
+
+// Scheduler runs every job on its own interval. A job that fails is
+// retried after a backoff that doubles with every failure, starting from
+// Retry up to MaxBackoff. Every wait is moved randomly by Jitter, the
+// fraction of the wait, so the jobs do not hit the third party APIs at once
+type Scheduler struct {
+	Retry      time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+
+	jobs []*jobState
+
+	mu      sync.Mutex
+	running bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	rnd     *rand.Rand
+}
+
+type jobState struct {
+	job    Job
+	status mscheduler.JobStatus
+}
+
+// New creates a Scheduler for jobs, jobs with no interval are ignored
+func New(retry time.Duration, maxBackoff time.Duration, jitter float64, jobs ...Job) *Scheduler {
+	s := &Scheduler{
+		Retry:      retry,
+		MaxBackoff: maxBackoff,
+		Jitter:     jitter,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, job := range jobs {
+		if job.Interval <= 0 {
+			continue
+		}
+		s.jobs = append(s.jobs, &jobState{
+			job:    job,
+			status: mscheduler.JobStatus{Name: job.Name, Interval: job.Interval.Seconds()},
+		})
+	}
+
+	return s
+}
+
+// Start runs every job right away and then on its interval,
+// it does nothing if the scheduler is already running
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+
+	for _, js := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(js, s.stop)
+	}
+}
+
+// Stop stops the jobs and waits for the running refreshes to finish
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Status returns the state of every job
+func (s *Scheduler) Status() mscheduler.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := mscheduler.Status{Running: s.running, Jobs: make([]mscheduler.JobStatus, 0, len(s.jobs))}
+	for _, js := range s.jobs {
+		status.Jobs = append(status.Jobs, js.status)
+	}
+
+	return status
+}
+
+func (s *Scheduler) loop(js *jobState, stop chan struct{}) {
+	defer s.wg.Done()
+
+	for {
+		wait := s.run(js)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// run refreshes the dataset of a job, records the outcome
+// and returns how long to wait before the next run
+func (s *Scheduler) run(js *jobState) time.Duration {
+	err := js.job.Refresh()
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	js.status.Runs++
+	if err != nil {
+		applogger.Log("WARN", "scheduler", "run", js.job.Name+": "+err.Error())
+		js.status.ConsecutiveFailures++
+		js.status.LastFailure = &now
+		js.status.LastError = err.Error()
+	} else {
+		js.status.ConsecutiveFailures = 0
+		js.status.LastSuccess = &now
+	}
+
+	wait := s.jitter(s.wait(js.job.Interval, js.status.ConsecutiveFailures))
+	next := now.Add(wait)
+	js.status.NextRun = &next
+
+	return wait
+}
+
+// wait returns the interval of a job after a success or the backoff
+// after failures, the backoff is never longer than the interval
+func (s *Scheduler) wait(interval time.Duration, failures int) time.Duration {
+	if failures == 0 || s.Retry <= 0 {
+		return interval
+	}
+
+	backoff := s.Retry
+	for i := 1; i < failures && backoff < interval; i++ {
+		backoff *= 2
+	}
+
+	if s.MaxBackoff > 0 && backoff > s.MaxBackoff {
+		backoff = s.MaxBackoff
+	}
+	if backoff > interval {
+		backoff = interval
+	}
+
+	return backoff
+}
+
+// jitter moves d randomly by up to Jitter of it, the caller must hold the lock
+func (s *Scheduler) jitter(d time.Duration) time.Duration {
+	if s.Jitter <= 0 {
+		return d
+	}
+
+	delta := (s.rnd.Float64()*2 - 1) * s.Jitter * float64(d)
+	if jittered := d + time.Duration(delta); jittered > 0 {
+		return jittered
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	s := New(30*time.Second, 5*time.Minute, 0)
+	interval := 30 * time.Minute
+
+	expected := map[int]time.Duration{
+		0: interval,
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+		4: 4 * time.Minute,
+		5: 5 * time.Minute,
+		9: 5 * time.Minute,
+	}
+
+	for failures, wait := range expected {
+		if w := s.wait(interval, failures); w != wait {
+			t.Fatalf("Wrong wait %v after %d failures, expecting %v", w, failures, wait)
+		}
+	}
+
+	if w := s.wait(time.Minute, 4); w != time.Minute {
+		t.Fatalf("Backoff should not be longer than the interval having %v", w)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	s := New(0, 0, 0.1)
+	for i := 0; i < 100; i++ {
+		w := s.jitter(time.Minute)
+		if w < 54*time.Second || w > 66*time.Second {
+			t.Fatalf("Jittered wait %v is out of range", w)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	var countries, curve int32
+	s := New(time.Millisecond, 0, 0,
+		Job{Name: "countries", Interval: 10 * time.Millisecond, Refresh: func() error {
+			atomic.AddInt32(&countries, 1)
+			return nil
+		}},
+		Job{Name: "curve", Interval: time.Hour, Refresh: func() error {
+			atomic.AddInt32(&curve, 1)
+			return errors.New("upstream is down")
+		}},
+		Job{Name: "world", Refresh: func() error {
+			t.Fatal("Job with no interval should not run")
+			return nil
+		}},
+	)
+
+	s.Start()
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	status := s.Status()
+	if status.Running || len(status.Jobs) != 2 {
+		t.Fatalf("Wrong status %v", status)
+	}
+
+	countriesStatus := status.Jobs[0]
+	if countriesStatus.Runs < 2 || countriesStatus.LastSuccess == nil || countriesStatus.LastFailure != nil {
+		t.Fatalf("Wrong countries status %+v", countriesStatus)
+	}
+
+	curveStatus := status.Jobs[1]
+	if curveStatus.ConsecutiveFailures < 2 || curveStatus.LastError != "upstream is down" || curveStatus.LastSuccess != nil {
+		t.Fatalf("Failed job should be retried with backoff %+v", curveStatus)
+	}
+
+	if int(atomic.LoadInt32(&curve)) != curveStatus.Runs {
+		t.Fatalf("Runs %d do not match refreshes %d", curveStatus.Runs, curve)
+	}
+}
@@ -0,0 +1,76 @@
+package singleflight
+
+/*
+	Coalescing concurrent calls that fetch the same data, so any number of
+	callers share one request to the third party API
+*/
+
+import (
+	"fmt"
+	"sync"
+)
+
+// call is a Do call that is running or has finished
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+	// dups is the number of callers that shared the call
+	dups int
+}
+
+// Group runs one call per key at a time. The zero value is ready to use
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do runs fn and returns its results. If a call for the same key is
+// already running Do waits for it and returns its results instead of
+// running fn again. shared is true when the results were given to
+// more than one caller
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	if c, running := g.calls[key]; running {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	var panicked interface{}
+	func() {
+		// a panicking fn must not leave the other callers waiting forever,
+		// they get the panic as an error and the caller of fn panics again
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = r
+				c.val, c.err = nil, fmt.Errorf("singleflight: %v", r)
+			}
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			c.wg.Done()
+		}()
+		c.val, c.err = fn()
+	}()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+
+	g.mu.Lock()
+	shared = c.dups > 0
+	g.mu.Unlock()
+
+	return c.val, c.err, shared
+}
@@ -0,0 +1,124 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var g Group
+	v, err, shared := g.Do("curve", func() (interface{}, error) {
+		return "data", nil
+	})
+
+	if v.(string) != "data" || err != nil || shared {
+		t.Fatalf("Wrong results %v %v %v", v, err, shared)
+	}
+
+	_, err, _ = g.Do("curve", func() (interface{}, error) {
+		return nil, errors.New("upstream is down")
+	})
+
+	if err == nil {
+		t.Fatal("Expecting the error of fn")
+	}
+}
+
+func TestDoCoalesces(t *testing.T) {
+	var g Group
+	var calls int32
+	release := make(chan bool)
+
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "data", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan interface{}, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _, _ := g.Do("curve", fn)
+			results <- v
+		}()
+	}
+
+	// give the callers time to reach Do before the call finishes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if c := atomic.LoadInt32(&calls); c != 1 {
+		t.Fatalf("Expecting one call having %d", c)
+	}
+
+	for v := range results {
+		if v.(string) != "data" {
+			t.Fatalf("Wrong shared result %v", v)
+		}
+	}
+}
+
+func TestDoPanic(t *testing.T) {
+	var g Group
+	func() {
+		defer func() { recover() }()
+		g.Do("curve", func() (interface{}, error) {
+			panic("boom")
+		})
+	}()
+
+	v, _, _ := g.Do("curve", func() (interface{}, error) {
+		return "data", nil
+	})
+
+	if v.(string) != "data" {
+		t.Fatal("Key should be released after a panic")
+	}
+}
+
+func TestDoPanicWaiters(t *testing.T) {
+	var g Group
+	started := make(chan bool)
+	release := make(chan bool)
+
+	go func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expecting the caller of fn to panic")
+			}
+		}()
+		g.Do("curve", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+
+	<-started
+	result := make(chan error)
+	go func() {
+		v, err, shared := g.Do("curve", func() (interface{}, error) {
+			return "data", nil
+		})
+		if v != nil || !shared {
+			t.Errorf("Expecting the shared results of the panicking call having %v %v", v, shared)
+		}
+		result <- err
+	}()
+
+	// give the waiter time to reach Do before the call panics
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-result; err == nil || err.Error() != "singleflight: boom" {
+		t.Fatalf("Expecting the panic as an error having %v", err)
+	}
+}
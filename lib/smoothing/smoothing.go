@@ -0,0 +1,203 @@
+package smoothing
+
+/*
+	Smoothing the daily series, which are dominated by the
+	weekly reporting pattern of the countries
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// Smoothing methods
+const (
+	// Trailing is the average of a day and the Window-1 days before it
+	Trailing = "trailing"
+	// Centered is the average of the Window days around a day
+	Centered = "centered"
+	// Exponential is the exponentially weighted average of a day and
+	// the days before it, with Alpha the weight of the day
+	Exponential = "exponential"
+	// Clip is not a method, it sets the negative values to zero
+	Clip = "clip"
+)
+
+const (
+	defaultWindow = 7
+	defaultAlpha  = 0.3
+	// maxWindow is the longest window a moving average can have
+	maxWindow = 365
+)
+
+// ErrInvalidSmoothing is returned when a smoothing parameter can not be parsed
+var ErrInvalidSmoothing = errors.New("invalid smoothing")
+
+// Options of smoothing a series, no Method keeps the series as is
+// ClipNegative sets the negative values, the downward corrections of
+// the cumulative series, to zero before smoothing
+type Options struct {
+	Method       string
+	Window       int
+	Alpha        float64
+	ClipNegative bool
+}
+
+// Enabled reports whether the options change a series
+func (o Options) Enabled() bool {
+	return o.Method != "" || o.ClipNegative
+}
+
+// Parse parses a smoothing parameter, a comma separated list of a method
+// and clip. A method is trailing:N, centered:N (N days, 7 if missing) or
+// exponential:A (A the weight of a day between 0 and 1, 0.3 if missing),
+// for example "trailing:7,clip". An empty parameter is no smoothing
+func Parse(s string) (Options, error) {
+	var o Options
+	if strings.TrimSpace(s) == "" {
+		return o, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		name, value := strings.TrimSpace(part), ""
+		if i := strings.Index(name, ":"); i >= 0 {
+			name, value = name[:i], name[i+1:]
+		}
+
+		if name == Clip && value == "" {
+			o.ClipNegative = true
+			continue
+		}
+
+		if o.Method != "" {
+			return Options{}, fmt.Errorf("%w %q: more than one method", ErrInvalidSmoothing, s)
+		}
+
+		switch name {
+		case Trailing, Centered:
+			o.Method, o.Window = name, defaultWindow
+			if value != "" {
+				window, err := strconv.Atoi(value)
+				if err != nil || window < 1 || window > maxWindow {
+					return Options{}, fmt.Errorf("%w %q: the window must be between 1 and %d days", ErrInvalidSmoothing, s, maxWindow)
+				}
+				o.Window = window
+			}
+		case Exponential:
+			o.Method, o.Alpha = name, defaultAlpha
+			if value != "" {
+				alpha, err := strconv.ParseFloat(value, 64)
+				if err != nil || alpha <= 0 || alpha > 1 {
+					return Options{}, fmt.Errorf("%w %q: alpha must be greater than 0 and up to 1", ErrInvalidSmoothing, s)
+				}
+				o.Alpha = alpha
+			}
+		default:
+			return Options{}, fmt.Errorf("%w %q: expecting %s:N, %s:N, %s:A or %s", ErrInvalidSmoothing, s,
+				Trailing, Centered, Exponential, Clip)
+		}
+	}
+
+	return o, nil
+}
+
+// Apply returns the series smoothed with the options, a new series
+// with the same dates. The averages near the ends of the series are
+// over the days the series has
+func Apply(t mcountry.Timeline, o Options) mcountry.Timeline {
+	if !o.Enabled() {
+		return t
+	}
+
+	values := t.Values()
+	if o.ClipNegative {
+		values = ClipNegative(values)
+	}
+
+	switch o.Method {
+	case Trailing:
+		values = TrailingAverage(values, o.Window)
+	case Centered:
+		values = CenteredAverage(values, o.Window)
+	case Exponential:
+		values = ExponentialSmoothing(values, o.Alpha)
+	}
+
+	smoothed := make(mcountry.Timeline, 0, len(t))
+	for i, v := range t {
+		smoothed = append(smoothed, mcountry.TimelinePoint{Date: v.Date, Value: values[i]})
+	}
+	return smoothed
+}
+
+// ClipNegative returns the values with the negative ones set to zero
+func ClipNegative(values []float64) []float64 {
+	clipped := make([]float64, len(values))
+	for i, v := range values {
+		if v > 0 {
+			clipped[i] = v
+		}
+	}
+	return clipped
+}
+
+// TrailingAverage returns the average of every value and the window-1
+// values before it
+func TrailingAverage(values []float64, window int) []float64 {
+	return movingAverage(values, window-1, 0)
+}
+
+// CenteredAverage returns the average of the window values around every
+// value, for an even window there is one more value before than after it
+func CenteredAverage(values []float64, window int) []float64 {
+	return movingAverage(values, window/2, (window-1)/2)
+}
+
+// movingAverage returns the average of every value with up to before
+// values before it and after values after it
+func movingAverage(values []float64, before int, after int) []float64 {
+	averages := make([]float64, len(values))
+	if before < 0 {
+		before = 0
+	}
+	if after < 0 {
+		after = 0
+	}
+
+	// prefix[i] is the sum of the first i values
+	prefix := make([]float64, len(values)+1)
+	for i, v := range values {
+		prefix[i+1] = prefix[i] + v
+	}
+
+	for i := range values {
+		from, to := i-before, i+after+1
+		if from < 0 {
+			from = 0
+		}
+		if to > len(values) {
+			to = len(values)
+		}
+		averages[i] = (prefix[to] - prefix[from]) / float64(to-from)
+	}
+
+	return averages
+}
+
+// ExponentialSmoothing returns the exponentially weighted average of every
+// value and the values before it, alpha is the weight of the value
+func ExponentialSmoothing(values []float64, alpha float64) []float64 {
+	smoothed := make([]float64, len(values))
+	for i, v := range values {
+		if i == 0 {
+			smoothed[i] = v
+			continue
+		}
+		smoothed[i] = alpha*v + (1-alpha)*smoothed[i-1]
+	}
+	return smoothed
+}
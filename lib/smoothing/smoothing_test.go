@@ -0,0 +1,100 @@
+package smoothing
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		param    string
+		expected Options
+	}{
+		{"", Options{}},
+		{"trailing", Options{Method: Trailing, Window: 7}},
+		{"trailing:3", Options{Method: Trailing, Window: 3}},
+		{"centered:5,clip", Options{Method: Centered, Window: 5, ClipNegative: true}},
+		{"exponential:0.5", Options{Method: Exponential, Alpha: 0.5}},
+		{"clip", Options{ClipNegative: true}},
+	}
+
+	for _, test := range tests {
+		o, err := Parse(test.param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o != test.expected {
+			t.Errorf("Parsing %q expecting %v having %v", test.param, test.expected, o)
+		}
+	}
+
+	for _, param := range []string{"median:7", "trailing:0", "trailing:week", "exponential:1.5", "trailing:7,centered:7", "clip:1"} {
+		if _, err := Parse(param); !errors.Is(err, ErrInvalidSmoothing) {
+			t.Errorf("Expecting invalid smoothing for %q having %v", param, err)
+		}
+	}
+}
+
+func TestAverages(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+
+	if trailing := TrailingAverage(values, 3); !equal(trailing, []float64{1, 1.5, 2, 3, 4}) {
+		t.Errorf("Wrong trailing average %v", trailing)
+	}
+
+	if centered := CenteredAverage(values, 3); !equal(centered, []float64{1.5, 2, 3, 4, 4.5}) {
+		t.Errorf("Wrong centered average %v", centered)
+	}
+
+	if centered := CenteredAverage(values, 4); !equal(centered, []float64{1.5, 2, 2.5, 3.5, 4}) {
+		t.Errorf("Wrong centered average of an even window %v", centered)
+	}
+
+	if same := TrailingAverage(values, 1); !equal(same, values) {
+		t.Errorf("A window of one day should not change the values %v", same)
+	}
+
+	if exponential := ExponentialSmoothing([]float64{10, 20, 20}, 0.5); !equal(exponential, []float64{10, 15, 17.5}) {
+		t.Errorf("Wrong exponential smoothing %v", exponential)
+	}
+
+	if clipped := ClipNegative([]float64{3, -2, 0, 4}); !equal(clipped, []float64{3, 0, 0, 4}) {
+		t.Errorf("Wrong clipped values %v", clipped)
+	}
+}
+
+func TestApply(t *testing.T) {
+	timeline := mcountry.Timeline{
+		{Date: "2020-03-09", Value: 6},
+		{Date: "2020-03-10", Value: -3},
+		{Date: "2020-03-11", Value: 9},
+	}
+
+	smoothed := Apply(timeline, Options{Method: Trailing, Window: 2, ClipNegative: true})
+	if !equal(smoothed.Values(), []float64{6, 3, 4.5}) || smoothed[2].Date != "2020-03-11" {
+		t.Fatalf("Wrong smoothed timeline %v", smoothed)
+	}
+
+	if timeline[1].Value != -3 {
+		t.Fatal("Apply should not change the timeline")
+	}
+
+	if none := Apply(timeline, Options{}); !equal(none.Values(), timeline.Values()) {
+		t.Fatalf("No options should not change the timeline %v", none)
+	}
+}
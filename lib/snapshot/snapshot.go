@@ -0,0 +1,347 @@
+package snapshot
+
+/*
+	Keeping the history of the countries' stats, every fetched
+	mcountry.Countries is appended to a daily ndjson file and the
+	files older than the retention of the config are removed
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	pconf "github.com/junkd0g/covid/lib/config"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+	msnapshot "github.com/junkd0g/covid/lib/model/snapshot"
+	registry "github.com/junkd0g/covid/lib/registry"
+)
+
+const (
+	fileExtension = ".ndjson"
+	// maxLineSize is the size of the biggest snapshot that can be read
+	maxLineSize = 16 * 1024 * 1024
+	// chunkSize is the size of the chunks a daily file is read backwards in
+	chunkSize = 64 * 1024
+)
+
+var (
+	serverConf = pconf.GetAppConfig()
+	// Default stores the snapshots in the directory of the config
+	Default = NewFromConfig(serverConf.Snapshot)
+
+	// ErrNotFound is returned when there is no snapshot for a date
+	ErrNotFound = errors.New("snapshot not found")
+	// ErrCountryNotFound is returned when the country is not in the snapshot,
+	// it is registry.ErrNotFound so the error has the suggestions of the registry
+	ErrCountryNotFound = registry.ErrNotFound
+	// ErrInvalidDate is returned when a date is not in the YYYY-MM-DD format
+	ErrInvalidDate = errors.New("invalid date, expecting YYYY-MM-DD")
+)
+
+// Store keeps the snapshots in a file per day (YYYY-MM-DD.ndjson) in dir,
+// a Store with no dir keeps nothing. The files older than retention days
+// are removed, all of them are kept when retention is zero.
+// It is safe for concurrent use
+type Store struct {
+	dir       string
+	retention int
+	mu        sync.Mutex
+	last      []byte
+	prunedOn  string
+	now       func() time.Time
+}
+
+// New creates a Store that keeps the snapshots in dir, the stats of the
+// newest snapshot in dir are not appended again
+func New(dir string) *Store {
+	s := &Store{dir: dir, now: time.Now}
+	s.seed()
+	return s
+}
+
+// NewFromConfig creates a Store with the dir and the retention of the config
+func NewFromConfig(conf pconf.SnapshotConfig) *Store {
+	s := New(conf.Dir)
+	s.retention = conf.Retention
+	return s
+}
+
+// seed sets the last appended stats to the ones of the newest snapshot in
+// dir, so the same stats are not appended again after a restart
+func (s *Store) seed() {
+	dates, err := s.Dates()
+	if err != nil {
+		applogger.Log("WARN", "snapshot", "seed", err.Error())
+		return
+	}
+	if len(dates) == 0 {
+		return
+	}
+
+	snapshot, err := s.Last(dates[len(dates)-1])
+	if err != nil {
+		applogger.Log("WARN", "snapshot", "seed", err.Error())
+		return
+	}
+
+	if s.last, err = json.Marshal(snapshot.Countries); err != nil {
+		applogger.Log("WARN", "snapshot", "seed", err.Error())
+	}
+}
+
+// Enabled reports whether the Store keeps snapshots
+func (s *Store) Enabled() bool {
+	return s.dir != ""
+}
+
+// Append stores the countries as a snapshot taken now, unless they are
+// the same as the last snapshot that was appended
+func (s *Store) Append(countries mcountry.Countries) error {
+	if !s.Enabled() || len(countries.Data) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(countries.Data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	takenAt := s.now().UTC()
+	s.prune(takenAt)
+
+	if bytes.Equal(data, s.last) {
+		return nil
+	}
+
+	line, err := json.Marshal(msnapshot.Snapshot{TakenAt: takenAt, Countries: countries.Data})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path(takenAt.Format(mcountry.DateLayout)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	s.last = data
+	return nil
+}
+
+// prune removes the files older than the retention of the Store once a day
+func (s *Store) prune(now time.Time) {
+	today := now.Format(mcountry.DateLayout)
+	if s.retention <= 0 || s.prunedOn == today {
+		return
+	}
+
+	dates, err := s.Dates()
+	if err != nil {
+		applogger.Log("WARN", "snapshot", "prune", err.Error())
+		return
+	}
+
+	oldest := now.AddDate(0, 0, -s.retention).Format(mcountry.DateLayout)
+	for _, date := range dates {
+		if date >= oldest {
+			break
+		}
+		if err := os.Remove(s.path(date)); err != nil {
+			applogger.Log("WARN", "snapshot", "prune", err.Error())
+			return
+		}
+	}
+
+	s.prunedOn = today
+}
+
+// Dates returns the dates that have snapshots in ascending order
+func (s *Store) Dates() ([]string, error) {
+	dates := []string{}
+	if !s.Enabled() {
+		return dates, nil
+	}
+
+	files, err := ioutil.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return dates, nil
+	}
+	if err != nil {
+		return dates, err
+	}
+
+	for _, f := range files {
+		date := strings.TrimSuffix(f.Name(), fileExtension)
+		if f.IsDir() || date == f.Name() {
+			continue
+		}
+		if _, err := time.Parse(mcountry.DateLayout, date); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	return dates, nil
+}
+
+// Last returns the last snapshot that was taken on date
+func (s *Store) Last(date string) (msnapshot.Snapshot, error) {
+	if _, err := time.Parse(mcountry.DateLayout, date); err != nil {
+		return msnapshot.Snapshot{}, fmt.Errorf("%w: %s", ErrInvalidDate, date)
+	}
+
+	if !s.Enabled() {
+		return msnapshot.Snapshot{}, ErrNotFound
+	}
+
+	f, err := os.Open(s.path(date))
+	if os.IsNotExist(err) {
+		return msnapshot.Snapshot{}, ErrNotFound
+	}
+	if err != nil {
+		return msnapshot.Snapshot{}, err
+	}
+	defer f.Close()
+
+	last, err := lastLine(f)
+	if err != nil {
+		return msnapshot.Snapshot{}, err
+	}
+
+	if len(last) == 0 {
+		return msnapshot.Snapshot{}, ErrNotFound
+	}
+
+	var snapshot msnapshot.Snapshot
+	if err := json.Unmarshal(last, &snapshot); err != nil {
+		applogger.Log("ERROR", "snapshot", "Last", err.Error())
+		return msnapshot.Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// Country returns the stats of a country from the last snapshot of date
+// It returns ErrNotFound if there is no snapshot for date and a
+// *registry.NotFoundError if the country is not in the snapshot
+func (s *Store) Country(name string, date string) (msnapshot.CountrySnapshot, error) {
+	snapshot, err := s.Last(date)
+	if err != nil {
+		return msnapshot.CountrySnapshot{}, err
+	}
+
+	names := make([]string, 0, len(snapshot.Countries))
+	for _, v := range snapshot.Countries {
+		names = append(names, v.Country)
+	}
+
+	resolved, err := registry.Resolve(name, names)
+	if err != nil {
+		return msnapshot.CountrySnapshot{}, fmt.Errorf("%w on %s", err, date)
+	}
+
+	for _, v := range snapshot.Countries {
+		if v.Country == resolved {
+			return msnapshot.CountrySnapshot{Date: date, TakenAt: snapshot.TakenAt, Country: v}, nil
+		}
+	}
+
+	return msnapshot.CountrySnapshot{}, fmt.Errorf("%w on %s", &registry.NotFoundError{Name: name}, date)
+}
+
+// Diff returns how the stats of a country changed from the last snapshot
+// of the day before date to the last snapshot of date
+func (s *Store) Diff(name string, date string) (msnapshot.CountryDiff, error) {
+	current, err := s.Country(name, date)
+	if err != nil {
+		return msnapshot.CountryDiff{}, err
+	}
+
+	day, _ := time.Parse(mcountry.DateLayout, date)
+	previousDate := day.AddDate(0, 0, -1).Format(mcountry.DateLayout)
+	previous, err := s.Country(name, previousDate)
+	if err != nil {
+		return msnapshot.CountryDiff{}, err
+	}
+
+	c, p := current.Country, previous.Country
+	return msnapshot.CountryDiff{
+		Country:      c.Country,
+		Date:         date,
+		PreviousDate: previousDate,
+		Diff: msnapshot.CountryValues{
+			Cases:              c.Cases - p.Cases,
+			TodayCases:         c.TodayCases - p.TodayCases,
+			Deaths:             c.Deaths - p.Deaths,
+			TodayDeaths:        c.TodayDeaths - p.TodayDeaths,
+			Recovered:          c.Recovered - p.Recovered,
+			Active:             c.Active - p.Active,
+			Critical:           c.Critical - p.Critical,
+			CasesPerOneMillion: c.CasesPerOneMillion - p.CasesPerOneMillion,
+			Test:               c.Test - p.Test,
+			TestPerOneMillion:  c.TestPerOneMillion - p.TestPerOneMillion,
+			Population:         c.Population - p.Population,
+		},
+	}, nil
+}
+
+// lastLine returns the last line of f that is not blank, f is read
+// backwards so the earlier snapshots of the day are not read
+func lastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var tail []byte
+	chunk := make([]byte, chunkSize)
+	for end := info.Size(); end > 0; {
+		n := int64(chunkSize)
+		if end < n {
+			n = end
+		}
+		end -= n
+
+		if _, err := f.ReadAt(chunk[:n], end); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(append(make([]byte, 0, int(n)+len(tail)), chunk[:n]...), tail...)
+
+		trimmed := bytes.TrimRight(tail, " \t\r\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+		if len(trimmed) > maxLineSize {
+			return nil, bufio.ErrTooLong
+		}
+	}
+
+	return bytes.TrimSpace(tail), nil
+}
+
+func (s *Store) path(date string) string {
+	return filepath.Join(s.dir, date+fileExtension)
+}
@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+func countriesMock(greeceCases int) mcountry.Countries {
+	return mcountry.Countries{Data: []mcountry.Country{
+		{Country: "Greece", Cases: greeceCases, Deaths: 10, Population: 10000000},
+		{Country: "Italy", Cases: 1000, Deaths: 100},
+	}}
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 6, 6, 10, 0, 0, 0, time.UTC)
+	store := New(dir)
+	store.now = func() time.Time { return now }
+
+	store.Append(countriesMock(100))
+	now = now.Add(5 * time.Hour)
+	store.Append(countriesMock(120))
+	now = now.Add(24 * time.Hour)
+	store.Append(countriesMock(150))
+	store.Append(countriesMock(150))
+
+	dates, _ := store.Dates()
+	if len(dates) != 2 || dates[0] != "2020-06-06" || dates[1] != "2020-06-07" {
+		t.Fatalf("Wrong dates %v", dates)
+	}
+
+	greece, err := store.Country("greece", "2020-06-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if greece.Country.Cases != 120 || !greece.TakenAt.Equal(time.Date(2020, 6, 6, 15, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expecting the last snapshot of the day having %v", greece)
+	}
+
+	if gr, err := store.Country("GR", "2020-06-06"); err != nil || gr.Country.Country != "Greece" {
+		t.Fatalf("Expecting the ISO code to find Greece having %v %v", gr, err)
+	}
+
+	diff, err := store.Diff("Greece", "2020-06-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff.Diff.Cases != 30 || diff.Diff.Deaths != 0 || diff.PreviousDate != "2020-06-06" {
+		t.Fatalf("Wrong diff %v", diff)
+	}
+
+	if _, err := store.Diff("Greece", "2020-06-06"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expecting not found for a missing previous day having %v", err)
+	}
+
+	if _, err := store.Country("Narnia", "2020-06-06"); !errors.Is(err, ErrCountryNotFound) {
+		t.Fatalf("Expecting not found for a missing country having %v", err)
+	}
+
+	if _, err := store.Country("Greece", "06/06/2020"); !errors.Is(err, ErrInvalidDate) {
+		t.Fatalf("Expecting invalid date having %v", err)
+	}
+
+	lines, _ := ioutil.ReadFile(dir + "/2020-06-07.ndjson")
+	if countLines(lines) != 1 {
+		t.Fatalf("Identical snapshots should be appended once, having %d lines", countLines(lines))
+	}
+}
+
+func TestDisabledStore(t *testing.T) {
+	store := New("")
+	if err := store.Append(countriesMock(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Country("Greece", "2020-06-06"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expecting not found having %v", err)
+	}
+}
+
+func TestStoreRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 6, 6, 10, 0, 0, 0, time.UTC)
+	store := New(dir)
+	store.now = func() time.Time { return now }
+	store.Append(countriesMock(100))
+
+	restarted := New(dir)
+	restarted.now = func() time.Time { return now.Add(time.Hour) }
+	restarted.Append(countriesMock(100))
+
+	lines, _ := ioutil.ReadFile(dir + "/2020-06-06.ndjson")
+	if countLines(lines) != 1 {
+		t.Fatalf("The stats of the newest snapshot should not be appended after a restart, having %d lines", countLines(lines))
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	store := NewFromConfig(pconf.SnapshotConfig{Dir: dir, Retention: 2})
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		store.Append(countriesMock(100 + i))
+		now = now.AddDate(0, 0, 1)
+	}
+
+	dates, _ := store.Dates()
+	if len(dates) != 3 || dates[0] != "2020-06-02" || dates[2] != "2020-06-04" {
+		t.Fatalf("Expecting the snapshots of the last 2 days and today having %v", dates)
+	}
+}
+
+func TestStoreDiffPopulation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 6, 6, 10, 0, 0, 0, time.UTC)
+	store := New(dir)
+	store.now = func() time.Time { return now }
+	store.Append(countriesMock(100))
+
+	countries := countriesMock(120)
+	countries.Data[0].Population = 10000500
+	now = now.AddDate(0, 0, 1)
+	store.Append(countries)
+
+	diff, err := store.Diff("Greece", "2020-06-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff.Diff.Population != 500 {
+		t.Fatalf("Wrong population diff %v", diff)
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	f, err := ioutil.TempFile("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	long := strings.Repeat("x", 3*chunkSize)
+	f.WriteString("first\n" + long + "\n\n  \n")
+
+	last, err := lastLine(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(last) != long {
+		t.Fatalf("Expecting the last line that is not blank having %d bytes", len(last))
+	}
+}
+
+func countLines(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c == '\n' {
+			n++
+		}
+	}
+	return n
+}
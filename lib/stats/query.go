@@ -0,0 +1,227 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	continent "github.com/junkd0g/covid/lib/continent"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// Comparison operators of a Condition
+const (
+	OpEqual          = "eq"
+	OpNotEqual       = "ne"
+	OpGreater        = "gt"
+	OpGreaterOrEqual = "gte"
+	OpLess           = "lt"
+	OpLessOrEqual    = "lte"
+)
+
+var (
+	// ErrUnknownOperator is returned when a Condition has an operator
+	// other than the Op constants
+	ErrUnknownOperator = errors.New("unknown operator")
+	// ErrUnknownContinent is returned when a Query has a continent that
+	// is not in the continents' data
+	ErrUnknownContinent = errors.New("unknown continent")
+)
+
+// Condition keeps the countries whose Field compares to Value with Op
+type Condition struct {
+	Field string
+	Op    string
+	Value float64
+}
+
+// Query selects the countries that match every Condition, are in any of
+// Continents (when there are any) and whose name starts with Prefix and
+// contains Contains, ignoring case. The countries are sorted by Sort,
+// the first Offset of them are skipped and up to Limit are kept, all of
+// them when Limit is zero. Fields, when not empty, are the only fields
+// of every country in the response
+type Query struct {
+	Conditions []Condition
+	Continents []string
+	Prefix     string
+	Contains   string
+	Sort       []SortKey
+	Limit      int
+	Offset     int
+	Fields     []string
+}
+
+// match reports whether the value of a country compares to the
+// value of a Condition with its operator
+func (c Condition) match(country mcountry.Country) bool {
+	value := countryFields[c.Field](country)
+	switch c.Op {
+	case OpEqual:
+		return value == c.Value
+	case OpNotEqual:
+		return value != c.Value
+	case OpGreater:
+		return value > c.Value
+	case OpGreaterOrEqual:
+		return value >= c.Value
+	case OpLess:
+		return value < c.Value
+	case OpLessOrEqual:
+		return value <= c.Value
+	}
+	return false
+}
+
+// validate returns an error wrapping ErrUnknownField or ErrUnknownOperator
+// if a field or an operator of the query is unknown
+func (q Query) validate() error {
+	for _, c := range q.Conditions {
+		if _, exist := countryFields[c.Field]; !exist {
+			return fmt.Errorf("%w %q, expecting one of %s", ErrUnknownField, c.Field, strings.Join(SortFields()[1:], ", "))
+		}
+		switch c.Op {
+		case OpEqual, OpNotEqual, OpGreater, OpGreaterOrEqual, OpLess, OpLessOrEqual:
+		default:
+			return fmt.Errorf("%w %q of field %q, expecting one of eq, ne, gt, gte, lt, lte", ErrUnknownOperator, c.Op, c.Field)
+		}
+	}
+
+	for _, field := range q.Fields {
+		if _, known := compareCountries(mcountry.Country{}, mcountry.Country{}, field); !known {
+			return fmt.Errorf("%w %q, expecting one of %s", ErrUnknownField, field, strings.Join(SortFields(), ", "))
+		}
+	}
+
+	return validateSortKeys(q.Sort)
+}
+
+// ContinentCountries returns the names of the countries of continents
+// in lower case
+// It returns an error wrapping ErrUnknownContinent if a continent is unknown
+func ContinentCountries(continents []string) (map[string]bool, error) {
+	continentData, err := continent.GetContinentData()
+	if err != nil {
+		applogger.Log("ERROR", "stats", "ContinentCountries", err.Error())
+		return nil, err
+	}
+
+	countries := make(map[string]bool)
+	for _, name := range continents {
+		found := false
+		for _, v := range continentData {
+			if strings.EqualFold(v.Continent, name) {
+				found = true
+				for _, country := range v.Countries {
+					countries[strings.ToLower(country)] = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w %q", ErrUnknownContinent, name)
+		}
+	}
+
+	return countries, nil
+}
+
+// Filter returns the countries that match the conditions, the name prefix
+// and contains of q, in the countries' order. inContinents when not nil
+// are the lower case names of the countries allowed
+func Filter(countries []mcountry.Country, q Query, inContinents map[string]bool) []mcountry.Country {
+	prefix, contains := strings.ToLower(q.Prefix), strings.ToLower(q.Contains)
+
+	filtered := make([]mcountry.Country, 0, len(countries))
+	for _, v := range countries {
+		name := strings.ToLower(v.Country)
+		if !strings.HasPrefix(name, prefix) || !strings.Contains(name, contains) {
+			continue
+		}
+		if inContinents != nil && !inContinents[name] {
+			continue
+		}
+
+		matched := true
+		for _, c := range q.Conditions {
+			if !c.match(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+// Project returns the fields of every country, a field can be "country"
+// or any of SortFields
+func Project(countries []mcountry.Country, fields []string) []map[string]interface{} {
+	projected := make([]map[string]interface{}, 0, len(countries))
+	for _, v := range countries {
+		p := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if field == "country" {
+				p[field] = v.Country
+				continue
+			}
+			if value, exist := countryFields[field]; exist {
+				p[field] = value(v)
+			}
+		}
+		projected = append(projected, p)
+	}
+
+	return projected
+}
+
+// QueryCountries returns a page of the countries that match q
+// It returns mcountry.CountriesPage and any write error encountered, an error
+// wrapping ErrUnknownField, ErrUnknownOperator or ErrUnknownContinent if q
+// has a field, an operator or a continent that is unknown.
+func QueryCountries(q Query) (mcountry.CountriesPage, error) {
+	if errQuery := q.validate(); errQuery != nil {
+		return mcountry.CountriesPage{}, errQuery
+	}
+
+	var inContinents map[string]bool
+	if len(q.Continents) != 0 {
+		var errContinents error
+		inContinents, errContinents = ContinentCountries(q.Continents)
+		if errContinents != nil {
+			return mcountry.CountriesPage{}, errContinents
+		}
+	}
+
+	allCountries, allCountriesError := GetAllCountries()
+	if allCountriesError != nil {
+		applogger.Log("ERROR", "stats", "QueryCountries", allCountriesError.Error())
+		return mcountry.CountriesPage{}, allCountriesError
+	}
+
+	countries := Filter(allCountries.Data, q, inContinents)
+	Sort(countries, q.Sort)
+
+	page := mcountry.CountriesPage{Total: len(countries), Limit: q.Limit, Offset: q.Offset}
+
+	offset := q.Offset
+	if offset > len(countries) {
+		offset = len(countries)
+	}
+	countries = countries[offset:]
+
+	if q.Limit > 0 && q.Limit < len(countries) {
+		countries = countries[:q.Limit]
+	}
+
+	if len(q.Fields) != 0 {
+		page.Data = Project(countries, q.Fields)
+	} else {
+		page.Data = countries
+	}
+
+	return page, nil
+}
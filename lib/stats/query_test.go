@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		q            Query
+		inContinents map[string]bool
+		expected     []string
+	}{
+		{
+			Query{Conditions: []Condition{{Field: "cases", Op: OpGreater, Value: 10000}, {Field: "deaths", Op: OpLess, Value: 30000}}},
+			nil,
+			[]string{"Spain"},
+		},
+		{
+			Query{Conditions: []Condition{{Field: "cases", Op: OpEqual, Value: 3000}}},
+			nil,
+			[]string{"Greece", "Gondor"},
+		},
+		{
+			Query{Conditions: []Condition{{Field: "deathRate", Op: OpGreaterOrEqual, Value: 0.1}}},
+			nil,
+			[]string{"Italy", "Spain"},
+		},
+		{
+			Query{Prefix: "g"},
+			nil,
+			[]string{"Greece", "Gondor"},
+		},
+		{
+			Query{Contains: "AI"},
+			nil,
+			[]string{"Spain"},
+		},
+		{
+			Query{Conditions: []Condition{{Field: "cases", Op: OpNotEqual, Value: 0}}},
+			map[string]bool{"greece": true, "narnia": true},
+			[]string{"Greece"},
+		},
+	}
+
+	for _, test := range tests {
+		got := names(Filter(sortMock(), test.q, test.inContinents))
+		if len(got) != len(test.expected) {
+			t.Errorf("Filtering with %v expecting %v having %v", test.q, test.expected, got)
+			continue
+		}
+		for i := range test.expected {
+			if got[i] != test.expected[i] {
+				t.Errorf("Filtering with %v expecting %v having %v", test.q, test.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	projected := Project(sortMock()[:1], []string{"country", "deaths", "deathRate"})
+	if len(projected) != 1 || len(projected[0]) != 3 {
+		t.Fatalf("Expecting one country with 3 fields having %v", projected)
+	}
+
+	if projected[0]["country"] != "Greece" || projected[0]["deaths"] != float64(180) || projected[0]["deathRate"] != 0.06 {
+		t.Fatalf("Wrong projected fields %v", projected[0])
+	}
+}
+
+func TestQueryCountriesInvalid(t *testing.T) {
+	tests := []struct {
+		q   Query
+		err error
+	}{
+		{Query{Conditions: []Condition{{Field: "vaccinated", Op: OpGreater}}}, ErrUnknownField},
+		{Query{Conditions: []Condition{{Field: "cases", Op: ">"}}}, ErrUnknownOperator},
+		{Query{Fields: []string{"country", "vaccinated"}}, ErrUnknownField},
+		{Query{Sort: []SortKey{{Field: "vaccinated"}}}, ErrUnknownField},
+	}
+
+	for _, test := range tests {
+		if _, err := QueryCountries(test.q); !errors.Is(err, test.err) {
+			t.Errorf("Querying with %v expecting %v having %v", test.q, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+// ErrUnknownField is returned when countries are sorted, filtered or
+// projected by a field that is not in SortFields
+var ErrUnknownField = errors.New("unknown field")
+
+// SortKey is a field to sort the countries by, in descending order
+// unless Ascending is set
+type SortKey struct {
+	Field     string
+	Ascending bool
+}
+
+// countryField returns the value of a mcountry.Country field as a float64
+type countryField func(c mcountry.Country) float64
+
+// countryFields are the numeric fields of a country that it can be sorted,
+// filtered and projected by, the json names of mcountry.Country and ratios
+// derived from them
+var countryFields = map[string]countryField{
+	"cases":              func(c mcountry.Country) float64 { return float64(c.Cases) },
+	"todayCases":         func(c mcountry.Country) float64 { return float64(c.TodayCases) },
+	"deaths":             func(c mcountry.Country) float64 { return float64(c.Deaths) },
+	"todayDeaths":        func(c mcountry.Country) float64 { return float64(c.TodayDeaths) },
+	"recovered":          func(c mcountry.Country) float64 { return float64(c.Recovered) },
+	"active":             func(c mcountry.Country) float64 { return float64(c.Active) },
+	"critical":           func(c mcountry.Country) float64 { return float64(c.Critical) },
+	"casesPerOneMillion": func(c mcountry.Country) float64 { return c.CasesPerOneMillion },
+	"tests":              func(c mcountry.Country) float64 { return float64(c.Test) },
+	"testsPerOneMillion": func(c mcountry.Country) float64 { return float64(c.TestPerOneMillion) },
+	"population":         func(c mcountry.Country) float64 { return float64(c.Population) },
+	// deaths per case
+	"deathRate": func(c mcountry.Country) float64 { return ratio(c.Deaths, c.Cases) },
+	// recovered per case
+	"recoveryRate": func(c mcountry.Country) float64 { return ratio(c.Recovered, c.Cases) },
+	// critical per active case
+	"criticalRate": func(c mcountry.Country) float64 { return ratio(c.Critical, c.Active) },
+	// cases per test
+	"positivityRate": func(c mcountry.Country) float64 { return ratio(c.Cases, c.Test) },
+	// today's cases per case
+	"todayCasesRate": func(c mcountry.Country) float64 { return ratio(c.TodayCases, c.Cases) },
+}
+
+// SortFields returns the fields countries can be sorted by, "country"
+// sorts them by name
+func SortFields() []string {
+	fields := []string{"country"}
+	for field := range countryFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields[1:])
+	return fields
+}
+
+// ratio returns part / total and 0 if total is 0
+func ratio(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// compareCountries returns how a compares to b on field, negative when a
+// comes first in ascending order, and false if field is unknown
+func compareCountries(a mcountry.Country, b mcountry.Country, field string) (int, bool) {
+	if field == "country" {
+		return strings.Compare(a.Country, b.Country), true
+	}
+
+	value, exist := countryFields[field]
+	if !exist {
+		return 0, false
+	}
+
+	va, vb := value(a), value(b)
+	switch {
+	case va < vb:
+		return -1, true
+	case va > vb:
+		return 1, true
+	}
+	return 0, true
+}
+
+// validateSortKeys returns an error wrapping ErrUnknownField if the field
+// of a key is unknown
+func validateSortKeys(keys []SortKey) error {
+	for _, key := range keys {
+		if _, known := compareCountries(mcountry.Country{}, mcountry.Country{}, key.Field); !known {
+			return fmt.Errorf("%w %q, expecting one of %s", ErrUnknownField, key.Field, strings.Join(SortFields(), ", "))
+		}
+	}
+	return nil
+}
+
+// Sort sorts countries by keys, a key breaks the ties of the keys before it
+// and the countries that are equal on every key keep their order
+// It returns an error wrapping ErrUnknownField if a key's field is unknown
+func Sort(countries []mcountry.Country, keys []SortKey) error {
+	if errKeys := validateSortKeys(keys); errKeys != nil {
+		return errKeys
+	}
+
+	sort.SliceStable(countries, func(i, j int) bool {
+		for _, key := range keys {
+			c, _ := compareCountries(countries[i], countries[j], key.Field)
+			if c == 0 {
+				continue
+			}
+			if key.Ascending {
+				return c < 0
+			}
+			return c > 0
+		}
+		return false
+	})
+
+	return nil
+}
+
+// SortCountries gets all countries sorted by keys and keeps the first limit
+// of them, all of them when limit is zero
+// It returns mcountry.Countries ([] Country) and any write error encountered,
+// an error wrapping ErrUnknownField if a key's field is unknown.
+func SortCountries(keys []SortKey, limit int) (mcountry.Countries, error) {
+	if errKeys := validateSortKeys(keys); errKeys != nil {
+		return mcountry.Countries{}, errKeys
+	}
+
+	allCountriesArr, allCountriesError := GetAllCountries()
+	if allCountriesError != nil {
+		applogger.Log("ERROR", "stats", "SortCountries", allCountriesError.Error())
+		return mcountry.Countries{}, allCountriesError
+	}
+
+	allCountries := make([]mcountry.Country, len(allCountriesArr.Data))
+	copy(allCountries, allCountriesArr.Data)
+
+	if errSort := Sort(allCountries, keys); errSort != nil {
+		return mcountry.Countries{}, errSort
+	}
+
+	if limit > 0 && limit < len(allCountries) {
+		allCountries = allCountries[:limit]
+	}
+
+	return mcountry.Countries{Data: allCountries}, nil
+}
+
+// SortByCases sorts an array of Country structs by Country.Cases
+// It returns structs.Countries ([] Country) and any write error encountered.
+func SortByCases() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "cases"}, {Field: "deaths"}}, 0)
+}
+
+// SortByDeaths sorts an array of Country structs by Country.Deaths
+// It returns structs.Countries ([] Country) and any write error encountered.
+func SortByDeaths() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "deaths"}, {Field: "cases"}}, 0)
+}
+
+// SortByTodayCases sorts an array of Country mcountry by Country.TodayCases
+// It returns mcountry.Countries ([] Country) and any write error encountered.
+func SortByTodayCases() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "todayCases"}}, 0)
+}
+
+// SortByTodayDeaths sorts an array of Country structs by Country.TodayDeaths
+// It returns structs.Countries ([] Country) and any write error encountered.
+func SortByTodayDeaths() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "todayDeaths"}}, 0)
+}
+
+// SortByRecovered sorts an array of Country structs by Country.Recovered
+// It returns mcountry.Countries ([] Country) and any write error encountered.
+func SortByRecovered() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "recovered"}}, 0)
+}
+
+// SortByActive sorts an array of Country structs by Country.Active
+// It returns mcountry.Countries ([] Country) and any write error encountered.
+func SortByActive() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "active"}}, 0)
+}
+
+// SortByCritical sorts an array of Country structs by Country.Critical
+// It returns mcountry.Countries ([] Country) and any write error encountered.
+func SortByCritical() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "critical"}}, 0)
+}
+
+// SortByCasesPerOneMillion sorts an array of Country structs by Country.CasesPerOneMillion
+// It returns mcountry.Countries ([] Country) and any write error encountered.
+func SortByCasesPerOneMillion() (mcountry.Countries, error) {
+	return SortCountries([]SortKey{{Field: "casesPerOneMillion"}}, 0)
+}
@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+
+	mcountry "github.com/junkd0g/covid/lib/model/country"
+)
+
+func sortMock() []mcountry.Country {
+	return []mcountry.Country{
+		{Country: "Greece", Cases: 3000, Deaths: 180, Recovered: 1300, Test: 240000},
+		{Country: "Italy", Cases: 235000, Deaths: 34000, Recovered: 165000, Test: 4500000},
+		{Country: "Spain", Cases: 241000, Deaths: 27000, Recovered: 150000, Test: 4400000},
+		{Country: "Narnia", Cases: 0, Deaths: 0},
+		{Country: "Gondor", Cases: 3000, Deaths: 100, Recovered: 1300, Test: 0},
+	}
+}
+
+func names(countries []mcountry.Country) []string {
+	n := make([]string, 0, len(countries))
+	for _, v := range countries {
+		n = append(n, v.Country)
+	}
+	return n
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		keys     []SortKey
+		expected []string
+	}{
+		{
+			[]SortKey{{Field: "cases"}},
+			[]string{"Spain", "Italy", "Greece", "Gondor", "Narnia"},
+		},
+		{
+			[]SortKey{{Field: "cases", Ascending: true}, {Field: "deaths"}},
+			[]string{"Narnia", "Greece", "Gondor", "Italy", "Spain"},
+		},
+		{
+			[]SortKey{{Field: "recovered"}, {Field: "country", Ascending: true}},
+			[]string{"Italy", "Spain", "Gondor", "Greece", "Narnia"},
+		},
+		{
+			[]SortKey{{Field: "deathRate"}},
+			[]string{"Italy", "Spain", "Greece", "Gondor", "Narnia"},
+		},
+		{
+			[]SortKey{{Field: "tests", Ascending: true}},
+			[]string{"Narnia", "Gondor", "Greece", "Spain", "Italy"},
+		},
+		{
+			nil,
+			[]string{"Greece", "Italy", "Spain", "Narnia", "Gondor"},
+		},
+	}
+
+	for _, test := range tests {
+		countries := sortMock()
+		if err := Sort(countries, test.keys); err != nil {
+			t.Fatal(err)
+		}
+
+		got := names(countries)
+		for i := range test.expected {
+			if got[i] != test.expected[i] {
+				t.Errorf("Sorting by %v expecting %v having %v", test.keys, test.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestSortUnknownField(t *testing.T) {
+	err := Sort(sortMock(), []SortKey{{Field: "cases"}, {Field: "vaccinated"}})
+	if !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("Expecting an unknown field error having %v", err)
+	}
+
+	if _, err := SortCountries([]SortKey{{Field: "vaccinated"}}, 0); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("Expecting an unknown field error before getting the countries having %v", err)
+	}
+}
@@ -2,49 +2,47 @@ package stats
 
 import (
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
-	"sort"
+	"fmt"
+	"time"
 
 	applogger "github.com/junkd0g/covid/lib/applogger"
 	caching "github.com/junkd0g/covid/lib/caching"
 	pconf "github.com/junkd0g/covid/lib/config"
 	mcountry "github.com/junkd0g/covid/lib/model/country"
+	registry "github.com/junkd0g/covid/lib/registry"
+	singleflight "github.com/junkd0g/covid/lib/singleflight"
+	snapshot "github.com/junkd0g/covid/lib/snapshot"
+	upstream "github.com/junkd0g/covid/lib/upstream"
 )
 
 var (
 	serverConf = pconf.GetAppConfig()
-	redis      caching.RedisST
+	cache      = caching.NewMemoryStore()
+	fetches    singleflight.Group
+
+	// ErrCountryNotFound is returned when there are no stats for a country,
+	// it is registry.ErrNotFound so the error has the suggestions of the registry
+	ErrCountryNotFound = registry.ErrNotFound
 )
 
+// SetCache sets the Store the stats of the countries are cached in,
+// an in-process store is used until it is set
+func SetCache(store caching.Store) {
+	cache = store
+}
+
 // requestData does an HTTP GET request to the third party API that
 // contains covid-9 stats
 // It returns []mcountry.Country and any write error encountered.
 func requestData() ([]mcountry.Country, error) {
-
-	client := &http.Client{}
-	requestURL := serverConf.API.URL
-	req, err := http.NewRequest("GET", requestURL, nil)
+	b, err := upstream.Default.Get(serverConf.API.URL)
 	if err != nil {
 		applogger.Log("ERROR", "stats", "requestData", err.Error())
 		return []mcountry.Country{}, err
 	}
 
-	res, resError := client.Do(req)
-	if resError != nil {
-		applogger.Log("ERROR", "stats", "requestData", resError.Error())
-		return []mcountry.Country{}, resError
-	}
-	defer res.Body.Close()
-
-	b, readBoyError := ioutil.ReadAll(res.Body)
-	if readBoyError != nil {
-		applogger.Log("ERROR", "stats", "requestData", readBoyError.Error())
-		return []mcountry.Country{}, readBoyError
-	}
-
 	keys := make([]mcountry.Country, 0)
-	if errUnmarshal := json.Unmarshal(b, &keys); err != nil {
+	if errUnmarshal := json.Unmarshal(b, &keys); errUnmarshal != nil {
 		applogger.Log("ERROR", "stats", "requestData", errUnmarshal.Error())
 		return []mcountry.Country{}, errUnmarshal
 	}
@@ -52,241 +50,145 @@ func requestData() ([]mcountry.Country, error) {
 	return keys, nil
 }
 
-// GetAllCountries get an array of all countries that have
-// Covid-19 stats (data starts from date 22/01/2020)
-// Check if there are cached data if not does a HTTP
-// request to the 3rd party API (check requestData())
-// It returns mcountry.Countries ([] Country) and any write error encountered.
-func GetAllCountries() (mcountry.Countries, error) {
-
-	cachedData, cacheGetError := redis.GetCountriesData()
-	if cacheGetError != nil {
-		applogger.Log("ERROR", "stats", "GetAllCountries", cacheGetError.Error())
-		return mcountry.Countries{}, cacheGetError
-	}
-	var s mcountry.Countries
-
-	if len(cachedData.Data) == 0 {
-		applogger.Log("INFO", "stats", "GetAllCountries", "Request data instead of getting cached data")
+// refresh requests the stats of all countries from the 3rd party API,
+// caches them and keeps them as a snapshot of their history.
+// Concurrent refreshes share one request and one cache write
+func refresh() (mcountry.Countries, error) {
+	data, err, _ := fetches.Do(caching.CountriesKey, func() (interface{}, error) {
 		response, responseError := requestData()
 		if responseError != nil {
-			applogger.Log("ERROR", "stats", "GetAllCountries", responseError.Error())
-			return mcountry.Countries{}, responseError
+			return nil, responseError
 		}
 
-		s = mcountry.Countries{Data: response}
-
-		redis.SetCountriesData(s)
-
-	} else {
-		applogger.Log("INFO", "stats", "GetAllCountries", "Getting cache data %v instead of requesting it")
-		return cachedData, nil
-	}
-
-	return s, nil
-}
-
-// GetCountry seach through an array of mcountry.Country and
-// gets COVID-19 stats for that specific country
-// It returns mcountry.Country and any write error encountered.
-func GetCountry(name string) (mcountry.Country, error) {
-	allCountries, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "GetCountry", allCountriesError.Error())
-		return mcountry.Country{}, allCountriesError
-	}
-
-	for _, v := range allCountries.Data {
-		if v.Country == name {
-			return v, nil
+		s := mcountry.Countries{Data: response}
+		if errSnapshot := snapshot.Default.Append(s); errSnapshot != nil {
+			applogger.Log("WARN", "stats", "refresh", errSnapshot.Error())
+		}
+		// the data are returned with the error of a failed cache write,
+		// they are still served but Refresh reports the error
+		return s, cache.SetCountriesData(s)
+	})
+	if err != nil {
+		applogger.Log("ERROR", "stats", "refresh", err.Error())
+		if data == nil {
+			return mcountry.Countries{}, err
 		}
 	}
 
-	applogger.Log("WARN", "stats", "GetCountry", "Returning empty country")
-	return mcountry.Country{}, nil
+	refreshed, ok := data.(mcountry.Countries)
+	if !ok {
+		errResult := fmt.Errorf("unexpected refresh result %T", data)
+		applogger.Log("ERROR", "stats", "refresh", errResult.Error())
+		return mcountry.Countries{}, errResult
+	}
+	return refreshed, err
 }
 
-// SortByCases sorts an array of Country structs by Country.Cases
-// It returns structs.Countries ([] Country) and any write error encountered.
-func SortByCases() (mcountry.Countries, error) {
+// GetAllCountries get an array of all countries that have
+// Covid-19 stats (data starts from date 22/01/2020)
+// Check if there are cached data if not it serves the last known good
+// copy while refreshing it in the background, or does a HTTP request
+// to the 3rd party API (check requestData()) if there is no copy
+// It returns mcountry.Countries ([] Country) and any write error encountered.
+func GetAllCountries() (mcountry.Countries, error) {
 
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByCases", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
+	cachedData, cacheGetError := cache.GetCountriesData()
+	if cacheGetError != nil {
+		// a cache backend that is down is a cache miss, the last known
+		// good copy or the third party API are used instead
+		applogger.Log("ERROR", "stats", "GetAllCountries", cacheGetError.Error())
+	} else if len(cachedData.Data) != 0 {
+		return cachedData, nil
 	}
 
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		if allCountries[i].Cases != allCountries[j].Cases {
-			return allCountries[i].Cases > allCountries[j].Cases
-		}
-		return allCountries[i].Deaths > allCountries[j].Deaths
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-
-	return s, nil
-}
-
-// SortByDeaths sorts an array of Country structs by Country.Deaths
-// It returns structs.Countries ([] Country) and any write error encountered.
-func SortByDeaths() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByDeaths", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
+	staleData, staleGetError := cache.LastKnownGood().GetCountriesData()
+	if staleGetError == nil && len(staleData.Data) != 0 {
+		applogger.Log("WARN", "stats", "GetAllCountries", "Serving last known good data while refreshing it")
+		cache.MarkStale(caching.CountriesKey)
+		cache.Revalidate(caching.CountriesKey, func() error {
+			_, err := refresh()
+			return err
+		})
+		return staleData, nil
 	}
 
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		if allCountries[i].Deaths != allCountries[j].Deaths {
-			return allCountries[i].Deaths > allCountries[j].Deaths
-		}
-		return allCountries[i].Cases > allCountries[j].Cases
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-
-	return s, nil
-}
-
-// SortByTodayCases sorts an array of Country mcountry by Country.TodayCases
-// It returns mcountry.Countries ([] Country) and any write error encountered.
-func SortByTodayCases() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByTodayCases", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
+	applogger.Log("INFO", "stats", "GetAllCountries", "Request data instead of getting cached data")
+	data, err := refresh()
+	if err != nil && len(data.Data) == 0 {
+		return mcountry.Countries{}, err
 	}
-
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		return allCountries[i].TodayCases > allCountries[j].TodayCases
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-	return s, nil
+	return data, nil
 }
 
-// SortByTodayDeaths sorts an array of Country structs by Country.TodayDeaths
-// It returns structs.Countries ([] Country) and any write error encountered.
-func SortByTodayDeaths() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByTodayDeaths", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
-	}
-
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		return allCountries[i].TodayDeaths > allCountries[j].TodayDeaths
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-	return s, nil
+// Refresh requests the stats of all countries from the 3rd party API and caches them
+// whether they have expired or not
+func Refresh() error {
+	_, err := refresh()
+	return err
 }
 
-// SortByRecovered sorts an array of Country structs by Country.Recovered
-// It returns mcountry.Countries ([] Country) and any write error encountered.
-func SortByRecovered() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByRecovered", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
-	}
-
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		return allCountries[i].Recovered > allCountries[j].Recovered
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-	return s, nil
+// Stale returns since when the countries' stats are served from their
+// last known good copy and false if they are not stale
+func Stale() (time.Time, bool) {
+	return cache.StaleSince(caching.CountriesKey)
 }
 
-// SortByActive sorts an array of Country structs by Country.Active
-// It returns mcountry.Countries ([] Country) and any write error encountered.
-func SortByActive() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
+// GetCountry gets COVID-19 stats of a country, the name can be any name,
+// ISO code or alias of the country in the registry
+// It returns mcountry.Country and any write error encountered, a
+// *registry.NotFoundError if there are no stats for the country.
+func GetCountry(name string) (mcountry.Country, error) {
+	allCountries, allCountriesError := GetAllCountries()
 	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByActive", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
+		applogger.Log("ERROR", "stats", "GetCountry", allCountriesError.Error())
+		return mcountry.Country{}, allCountriesError
 	}
 
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		return allCountries[i].Active > allCountries[j].Active
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-	return s, nil
-}
-
-// SortByCritical sorts an array of Country structs by Country.Critical
-// It returns mcountry.Countries ([] Country) and any write error encountered.
-func SortByCritical() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByCritical", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
+	names := make([]string, 0, len(allCountries.Data))
+	for _, v := range allCountries.Data {
+		names = append(names, v.Country)
 	}
 
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		return allCountries[i].Critical > allCountries[j].Critical
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-	return s, nil
-}
-
-// SortByCasesPerOneMillion sorts an array of Country structs by Country.CasesPerOneMillion
-// It returns mcountry.Countries ([] Country) and any write error encountered.
-func SortByCasesPerOneMillion() (mcountry.Countries, error) {
-	allCountriesArr, allCountriesError := GetAllCountries()
-	if allCountriesError != nil {
-		applogger.Log("ERROR", "stats", "SortByCasesPerOneMillion", allCountriesError.Error())
-		return mcountry.Countries{}, allCountriesError
+	resolved, err := registry.Resolve(name, names)
+	if err != nil {
+		return mcountry.Country{}, err
 	}
 
-	allCountries := allCountriesArr.Data
-
-	sort.Slice(allCountries, func(i, j int) bool {
-		return allCountries[i].CasesPerOneMillion > allCountries[j].CasesPerOneMillion
-	})
-
-	s := mcountry.Countries{Data: allCountries}
-	return s, nil
+	for _, v := range allCountries.Data {
+		if v.Country == resolved {
+			return v, nil
+		}
+	}
+	return mcountry.Country{}, &registry.NotFoundError{Name: name}
 }
 
 // PercentancePerCountry gets a country's COVID-19 stats (getting the from GetCountry)
-// and calculate today's total cases percentance and today's death percentance
-// It returns mcountry.CountryStats and any write error encountered.
+// and calculate today's total cases percentance and today's death percentance,
+// a percentance is 0 when the country has no cases or deaths
+// It returns mcountry.CountryStats and any write error encountered,
+// ErrCountryNotFound if there are no stats for the country.
 func PercentancePerCountry(name string) (mcountry.CountryStats, error) {
 	country, countryError := GetCountry(name)
 	if countryError != nil {
 		applogger.Log("ERROR", "stats", "PercentancePerCountry", countryError.Error())
-		return mcountry.CountryStats{}, nil
+		return mcountry.CountryStats{}, countryError
 	}
 
-	var todayPerCentOfTotalCases = country.TodayCases * 100 / country.Cases
-	var todayPerCentOfTotalDeaths = country.TodayDeaths * 100 / country.Deaths
-
 	countryStats := mcountry.CountryStats{Country: country.Country,
-		TodayPerCentOfTotalCases:  todayPerCentOfTotalCases,
-		TodayPerCentOfTotalDeaths: todayPerCentOfTotalDeaths}
+		TodayPerCentOfTotalCases:  percentage(country.TodayCases, country.Cases),
+		TodayPerCentOfTotalDeaths: percentage(country.TodayDeaths, country.Deaths)}
 
 	return countryStats, nil
 }
 
+// percentage returns part as a percentage of total and 0 if total is 0
+func percentage(part int, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return part * 100 / total
+}
+
 // GetTotalStats gets worlds COVID-19 total statistics.
 // The statistics are total cases, total deaths today's total deaths
 // totltoal cases, percentace totay increase in deaths and cases
@@ -300,7 +202,7 @@ func GetTotalStats() (mcountry.TotalStats, error) {
 	allCountriesArr, errorAllCountries := GetAllCountries()
 	if errorAllCountries != nil {
 		applogger.Log("ERROR", "stats", "GetTotalStats", errorAllCountries.Error())
-		return mcountry.TotalStats{}, nil
+		return mcountry.TotalStats{}, errorAllCountries
 	}
 
 	allCountries := allCountriesArr.Data
@@ -315,8 +217,8 @@ func GetTotalStats() (mcountry.TotalStats, error) {
 		todayTotalCases = todayTotalCases + v.TodayCases
 	}
 
-	var todayPerCentOfTotalCases = todayTotalDeaths * 100 / totalDeaths
-	var todayPerCentOfTotalDeaths = todayTotalCases * 100 / totalCases
+	var todayPerCentOfTotalCases = percentage(todayTotalDeaths, totalDeaths)
+	var todayPerCentOfTotalDeaths = percentage(todayTotalCases, totalCases)
 
 	totalStatsStuct := mcountry.TotalStats{
 		TodayPerCentOfTotalCases:  todayPerCentOfTotalCases,
@@ -0,0 +1,20 @@
+package stats
+
+import "testing"
+
+func TestPercentage(t *testing.T) {
+	tests := []struct {
+		part, total, expected int
+	}{
+		{5, 100, 5},
+		{1, 3, 33},
+		{0, 0, 0},
+		{7, 0, 0},
+	}
+
+	for _, test := range tests {
+		if p := percentage(test.part, test.total); p != test.expected {
+			t.Errorf("percentage(%d, %d) = %d, expected %d", test.part, test.total, p, test.expected)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package upstream
+
+/*
+	HTTP client for the third party APIs the covid-19 data come from
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	applogger "github.com/junkd0g/covid/lib/applogger"
+	pconf "github.com/junkd0g/covid/lib/config"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultBackoff    = 500 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+	defaultUserAgent  = "covid-api"
+	// maxErrorBody is how much of the body of a failed response is kept
+	maxErrorBody = 256
+)
+
+var (
+	serverConf = pconf.GetAppConfig()
+	// Default is the Client of the upstream section of the config
+	Default = New(serverConf.Upstream)
+)
+
+// StatusError is returned when the third party API responds with a
+// status code other than 2xx
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("upstream %s responded with status %d", e.URL, e.StatusCode)
+}
+
+// Unavailable reports whether the third party API is overloaded or down
+// for maintenance, so the request can be tried again later
+func (e *StatusError) Unavailable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// Client does GET requests to the third party APIs with a timeout,
+// retrying with exponential backoff the requests that fail with a
+// 5xx or 429 status code or a network error
+type Client struct {
+	HTTPClient *http.Client
+	UserAgent  string
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	sleep      func(time.Duration)
+}
+
+// New creates a Client from the upstream section of the config,
+// using a default for every value that is not set
+func New(conf pconf.UpstreamConfig) *Client {
+	milliseconds := func(ms int, def time.Duration) time.Duration {
+		if ms <= 0 {
+			return def
+		}
+		return time.Duration(ms) * time.Millisecond
+	}
+
+	userAgent := conf.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	return &Client{
+		HTTPClient: &http.Client{Timeout: milliseconds(conf.Timeout, defaultTimeout)},
+		UserAgent:  userAgent,
+		Retries:    conf.Retries,
+		Backoff:    milliseconds(conf.Backoff, defaultBackoff),
+		MaxBackoff: milliseconds(conf.MaxBackoff, defaultMaxBackoff),
+		sleep:      time.Sleep,
+	}
+}
+
+// Get does a GET request to url and returns the body of the response
+// It returns a *StatusError if the final response is not 2xx
+func (c *Client) Get(url string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.get(url)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if attempt >= c.Retries || !retryable(err) {
+			break
+		}
+
+		wait := c.backoff(attempt)
+		if retryAfter > wait && retryAfter <= c.MaxBackoff {
+			wait = retryAfter
+		}
+		applogger.Log("WARN", "upstream", "Get",
+			fmt.Sprintf("%s, retrying in %v", err.Error(), wait))
+		c.sleep(wait)
+	}
+
+	return nil, lastErr
+}
+
+// get does a single GET request, it returns the Retry-After
+// header of a failed response
+func (c *Client) get(url string) ([]byte, time.Duration, error) {
+	req, reqErr := http.NewRequest("GET", url, nil)
+	if reqErr != nil {
+		return nil, 0, reqErr
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	res, resErr := c.HTTPClient.Do(req)
+	if resErr != nil {
+		return nil, 0, resErr
+	}
+	defer res.Body.Close()
+
+	body, readErr := ioutil.ReadAll(res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+		retryAfter, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		return nil, time.Duration(retryAfter) * time.Second,
+			&StatusError{URL: url, StatusCode: res.StatusCode, Body: string(body)}
+	}
+
+	if readErr != nil {
+		return nil, 0, readErr
+	}
+
+	return body, 0, nil
+}
+
+// backoff returns how long to wait before the retry after attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.Backoff
+	for i := 0; i < attempt && wait < c.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
+
+// retryable reports whether a request that failed with err can succeed
+// if it is done again
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// HTTPStatus returns the status code a controller responds with when
+// getting the data failed with err. 503 when the third party API is
+// overloaded or down for maintenance, 502 when it failed or could not be
+// reached and 500 for any other error
+func HTTPStatus(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.Unavailable() {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusBadGateway
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return http.StatusBadGateway
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,108 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pconf "github.com/junkd0g/covid/lib/config"
+)
+
+func testClient(retries int) (*Client, *[]time.Duration) {
+	client := New(pconf.UpstreamConfig{Retries: retries, Backoff: 100, MaxBackoff: 1000, UserAgent: "covid-test"})
+	waits := []time.Duration{}
+	client.sleep = func(d time.Duration) { waits = append(waits, d) }
+	return client, &waits
+}
+
+func TestGetRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("User-Agent") != "covid-test" {
+			t.Errorf("Wrong user agent %s", r.Header.Get("User-Agent"))
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>Bad Gateway</html>")
+			return
+		}
+		fmt.Fprint(w, `[{"country": "Greece"}]`)
+	}))
+	defer server.Close()
+
+	client, waits := testClient(3)
+	body, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != `[{"country": "Greece"}]` || calls != 3 {
+		t.Fatalf("Wrong body %s after %d calls", body, calls)
+	}
+
+	if len(*waits) != 2 || (*waits)[0] != 100*time.Millisecond || (*waits)[1] != 200*time.Millisecond {
+		t.Fatalf("Wrong backoff %v", *waits)
+	}
+}
+
+func TestGetStatusError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, _ := testClient(3)
+	_, err := client.Get(server.URL)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expecting a StatusError having %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("A 404 should not be retried, having %d calls", calls)
+	}
+
+	if HTTPStatus(err) != http.StatusBadGateway {
+		t.Fatalf("Wrong status %d for a 404 of the upstream", HTTPStatus(err))
+	}
+}
+
+func TestGetTooManyRequests(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, waits := testClient(2)
+	_, err := client.Get(server.URL)
+
+	if calls != 3 || HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Fatalf("Wrong retries %d or status %d", calls, HTTPStatus(err))
+	}
+
+	if (*waits)[0] != time.Second {
+		t.Fatalf("Retry-After should be respected having %v", *waits)
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	client, _ := testClient(0)
+	_, err := client.Get("http://127.0.0.1:1")
+	if HTTPStatus(err) != http.StatusBadGateway {
+		t.Fatalf("Wrong status %d for an unreachable upstream", HTTPStatus(err))
+	}
+
+	if HTTPStatus(errors.New("country not found")) != http.StatusInternalServerError {
+		t.Fatal("Wrong status for an error that is not from the upstream")
+	}
+}